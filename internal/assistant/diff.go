@@ -0,0 +1,73 @@
+package assistant
+
+import "strings"
+
+// DiffOp identifies the kind of a diff line.
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffLine is a single line of a line-based diff.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffLines returns a line-based diff turning a into b,
+// computed via the longest common subsequence of lines.
+func DiffLines(a, b string) []DiffLine {
+	left := splitLines(a)
+	right := splitLines(b)
+
+	// lcs[i][j] holds the LCS length of left[i:] and right[j:]
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i] == right[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: left[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: left[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: right[j]})
+			j++
+		}
+	}
+	for ; i < len(left); i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: left[i]})
+	}
+	for ; j < len(right); j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: right[j]})
+	}
+
+	return diff
+}
+
+// splitLines splits text into lines, ignoring a trailing newline.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
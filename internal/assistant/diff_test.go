@@ -0,0 +1,51 @@
+package assistant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		want []DiffLine
+	}{
+		"identical": {
+			a:    "Be brief.\nBe kind.\n",
+			b:    "Be brief.\nBe kind.",
+			want: []DiffLine{{DiffEqual, "Be brief."}, {DiffEqual, "Be kind."}},
+		},
+		"changed line": {
+			a: "Role: helper\nBe brief.\nAnswer in English.",
+			b: "Role: helper\nBe detailed.\nAnswer in English.",
+			want: []DiffLine{
+				{DiffEqual, "Role: helper"},
+				{DiffDelete, "Be brief."},
+				{DiffInsert, "Be detailed."},
+				{DiffEqual, "Answer in English."},
+			},
+		},
+		"appended and removed": {
+			a: "one\ntwo\nthree",
+			b: "two\nthree\nfour",
+			want: []DiffLine{
+				{DiffDelete, "one"},
+				{DiffEqual, "two"},
+				{DiffEqual, "three"},
+				{DiffInsert, "four"},
+			},
+		},
+		"empty side": {
+			a:    "",
+			b:    "new",
+			want: []DiffLine{{DiffInsert, "new"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, DiffLines(tc.a, tc.b))
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 type FileFilter struct {
 	Extensions   []string // e.g., [".txt", ".md"]
 	IgnoreHidden bool     // ignore files starting with "."
+	Ignore       *Ignore  // ignore files matching .tunaignore, if set
 }
 
 // DefaultFilter returns the standard filter for assistant files.
@@ -58,6 +59,10 @@ func ListFiles(dir string, filter FileFilter) ([]string, error) {
 		files = append(files, name)
 	}
 
+	if filter.Ignore != nil {
+		files = filter.Ignore.filterDir(dir, files)
+	}
+
 	sort.Strings(files)
 	return files, nil
 }
@@ -0,0 +1,175 @@
+package assistant
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the file in the assistant root listing patterns of
+// files to leave out of plans.
+const IgnoreFileName = ".tunaignore"
+
+// Ignore holds gitignore-style patterns. Later patterns take precedence,
+// and a pattern starting with "!" re-includes files excluded before it.
+type Ignore struct {
+	root  string // Assistant directory, set by LoadIgnore
+	rules []ignoreRule
+}
+
+// ignoreRule is a single pattern of an ignore file.
+type ignoreRule struct {
+	segments []string // Pattern split by "/"; "**" matches any number of segments
+	negate   bool
+	rooted   bool // Contains a slash, matched against the path from the assistant root
+	dirOnly  bool // Ends with a slash, matches directories only
+}
+
+// LoadIgnore reads the ignore file of an assistant.
+// A missing file yields an Ignore that matches nothing.
+func LoadIgnore(assistantDir string) (*Ignore, error) {
+	path := filepath.Join(assistantDir, IgnoreFileName)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Ignore{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	ignore, err := ParseIgnore(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ignore.root = assistantDir
+	return ignore, nil
+}
+
+// ParseIgnore parses ignore patterns, one per line. Blank lines and
+// lines starting with "#" are skipped; a leading "/" is optional.
+// A "**" segment matches any number of directories, and a trailing "/"
+// makes the pattern match directories only, i.e. every file below them.
+func ParseIgnore(r io.Reader) (*Ignore, error) {
+	ignore := &Ignore{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, "/")
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.rooted = strings.Contains(line, "/")
+		rule.segments = strings.Split(line, "/")
+		for _, segment := range rule.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("line %d: invalid pattern %q: %w", n, line, err)
+			}
+		}
+		ignore.rules = append(ignore.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ignore, nil
+}
+
+// Match reports whether a file is ignored. Name is the slash-separated
+// path from the assistant root, e.g. "Input/draft.md"; patterns without
+// a slash match any single file or directory name in it. A file is also
+// ignored when a pattern matches one of its parent directories.
+func (i *Ignore) Match(name string) bool {
+	segments := strings.Split(path.Clean(name), "/")
+	ignored := false
+	for _, rule := range i.rules {
+		if rule.match(segments) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// match reports whether the rule matches the file or one of its parent
+// directories, given as path segments from the assistant root.
+func (r ignoreRule) match(segments []string) bool {
+	last := len(segments)
+	if r.dirOnly {
+		last-- // The file itself is not a directory
+	}
+	for end := 1; end <= last; end++ {
+		if r.rooted {
+			if matchSegments(r.segments, segments[:end]) {
+				return true
+			}
+		} else if matched, _ := path.Match(r.segments[0], segments[end-1]); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where
+// a "**" pattern segment matches zero or more path segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(segments); skip++ {
+			if matchSegments(pattern[1:], segments[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// Filter returns the files of dir, relative to the assistant root,
+// that are not ignored.
+func (i *Ignore) Filter(dir string, files []string) []string {
+	if len(i.rules) == 0 {
+		return files
+	}
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if !i.Match(path.Join(dir, file)) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// filterDir is Filter for a directory given as a file system path.
+// Directories outside the assistant root are left unfiltered.
+func (i *Ignore) filterDir(dir string, files []string) []string {
+	rel, err := filepath.Rel(i.root, dir)
+	if i.root == "" || err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return files
+	}
+	return i.Filter(filepath.ToSlash(rel), files)
+}
@@ -0,0 +1,78 @@
+package assistant
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnore_Match(t *testing.T) {
+	ignore, err := ParseIgnore(strings.NewReader(`
+# drafts are not queries
+draft_*.md
+!draft_final.md
+/Input/wip*
+**/scratch/
+Input/**/old.md
+archive/
+`))
+	require.NoError(t, err)
+
+	tests := map[string]bool{
+		"Input/query.md":               false,
+		"Input/draft_1.md":             true,
+		"Input/draft_final.md":         false,
+		"Input/wip.md":                 true,
+		"Input/nested/wip.md":          false,
+		"scratch/query.md":             true,
+		"Input/deep/scratch/query.md":  true,
+		"Input/scratch":                false, // A file, not a directory
+		"Input/old.md":                 true,
+		"Input/a/b/old.md":             true,
+		"Input/archive/query.md":       true,
+		"System prompt/archive/one.md": true,
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, ignore.Match(name), name)
+	}
+}
+
+func TestParseIgnore_InvalidPattern(t *testing.T) {
+	_, err := ParseIgnore(strings.NewReader("ok.md\nInput/[.md\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestListFiles_Ignore(t *testing.T) {
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	for _, name := range []string{"a.md", "draft_b.md", "c.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte("x"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte("Input/draft_*\n"), 0644))
+
+	ignore, err := LoadIgnore(dir)
+	require.NoError(t, err)
+	filter := DefaultFilter()
+	filter.Ignore = ignore
+
+	files, err := ListFiles(inputDir, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md", "c.txt"}, files)
+
+	other := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(other, "draft_c.md"), []byte("x"), 0644))
+	files, err = ListFiles(other, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"draft_c.md"}, files, "directories outside the assistant are not filtered")
+}
+
+func TestLoadIgnore_Missing(t *testing.T) {
+	ignore, err := LoadIgnore(t.TempDir())
+	require.NoError(t, err)
+	assert.False(t, ignore.Match("Input/a.md"))
+}
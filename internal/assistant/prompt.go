@@ -10,10 +10,45 @@ import (
 // SystemPromptDir is the name of the system prompt directory.
 const SystemPromptDir = "System prompt"
 
+// PrefixDelimiter introduces the shared preamble in a compiled system prompt,
+// like the "--- <filename> ---" delimiters of the fragments.
+const PrefixDelimiter = "--- system_prompt_prefix ---"
+
+// PrependPrefix returns the compiled prompt preceded by the shared
+// preamble. An empty prefix returns the prompt unchanged.
+func PrependPrefix(prompt, prefix string) string {
+	if strings.TrimSpace(prefix) == "" {
+		return prompt
+	}
+	if !strings.HasSuffix(prefix, "\n") {
+		prefix += "\n"
+	}
+	return PrefixDelimiter + "\n" + prefix + "\n" + prompt
+}
+
+// PromptDir returns the directory holding the fragments of the named
+// system prompt variant, or the flat system prompt directory if variant
+// is empty.
+func PromptDir(assistantDir, variant string) string {
+	return filepath.Join(assistantDir, SystemPromptDir, variant)
+}
+
 // CompileSystemPrompt reads and concatenates all prompt fragments.
 // Each fragment is prefixed with "--- <filename> ---" delimiter.
 func CompileSystemPrompt(assistantDir string) (string, error) {
-	promptDir := filepath.Join(assistantDir, SystemPromptDir)
+	return CompileSystemPromptVariant(assistantDir, "")
+}
+
+// CompileSystemPromptVariant compiles the fragments of a named variant,
+// stored in a subdirectory of the system prompt directory, e.g.
+// "System prompt/variant-a/". An empty variant compiles the flat directory.
+func CompileSystemPromptVariant(assistantDir, variant string) (string, error) {
+	if variant != "" {
+		if err := ValidateID(variant); err != nil {
+			return "", fmt.Errorf("invalid prompt variant %q: %w", variant, err)
+		}
+	}
+	promptDir := PromptDir(assistantDir, variant)
 
 	files, err := ListFiles(promptDir, DefaultFilter())
 	if err != nil {
@@ -0,0 +1,53 @@
+package assistant
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileSystemPromptVariant(t *testing.T) {
+	dir := t.TempDir()
+	promptDir := filepath.Join(dir, SystemPromptDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(promptDir, "variant-a"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(promptDir, "role.md"), []byte("Be brief."), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(promptDir, "variant-a", "role.md"), []byte("Be thorough.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(promptDir, "variant-a", "tone.md"), []byte("Be polite."), 0644))
+
+	tests := map[string]struct {
+		variant  string
+		expected string
+		err      string
+	}{
+		"flat directory": {
+			expected: "--- role.md ---\nBe brief.\n",
+		},
+		"named variant": {
+			variant:  "variant-a",
+			expected: "--- role.md ---\nBe thorough.\n\n--- tone.md ---\nBe polite.\n",
+		},
+		"missing variant": {
+			variant: "variant-b",
+			err:     "system prompt directory not found: " + filepath.Join(promptDir, "variant-b"),
+		},
+		"path traversal": {
+			variant: "../Input",
+			err:     `invalid prompt variant "../Input"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			prompt, err := CompileSystemPromptVariant(dir, tc.variant)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, prompt)
+		})
+	}
+}
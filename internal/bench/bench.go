@@ -0,0 +1,138 @@
+// Package bench provides a load harness for measuring LLM provider latency and throughput.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.octolab.org/toolset/tuna/internal/llm"
+)
+
+// DefaultPrompt is the trivial user message sent by each benchmark request.
+const DefaultPrompt = "Reply with the single word: pong"
+
+// Options holds benchmark parameters.
+type Options struct {
+	Model       string
+	Requests    int // Total number of requests to send
+	Concurrency int // Maximum number of requests in flight
+	MaxTokens   int
+}
+
+// Report holds aggregated benchmark results.
+type Report struct {
+	Requests   int
+	Succeeded  int
+	Failed     int
+	Elapsed    time.Duration // Wall time for the whole run
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+	Throughput float64 // Successful requests per second
+	Errors     []error
+}
+
+// ErrorRate returns the fraction of failed requests (0.0 to 1.0).
+func (r *Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Failed) / float64(r.Requests)
+}
+
+// Run fires opts.Requests trivial chat requests with at most opts.Concurrency
+// in flight and reports latency percentiles, throughput, and error rate.
+// Rate limits configured on the client are respected.
+func Run(ctx context.Context, client llm.ChatClient, opts Options) (*Report, error) {
+	if opts.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if opts.Requests <= 0 {
+		return nil, fmt.Errorf("requests must be positive, got %d", opts.Requests)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.Concurrency > opts.Requests {
+		opts.Concurrency = opts.Requests
+	}
+
+	jobs := make(chan struct{}, opts.Requests)
+	for i := 0; i < opts.Requests; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		errs      []error
+		wg        sync.WaitGroup
+	)
+
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				resp, err := client.Chat(ctx, llm.ChatRequest{
+					Model:       opts.Model,
+					UserMessage: DefaultPrompt,
+					MaxTokens:   opts.MaxTokens,
+				})
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					latencies = append(latencies, resp.Duration)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	report := &Report{
+		Requests:  opts.Requests,
+		Succeeded: len(latencies),
+		Failed:    len(errs),
+		Elapsed:   elapsed,
+		P50:       percentile(latencies, 50),
+		P95:       percentile(latencies, 95),
+		P99:       percentile(latencies, 99),
+		Errors:    errs,
+	}
+	if elapsed > 0 {
+		report.Throughput = float64(report.Succeeded) / elapsed.Seconds()
+	}
+
+	return report, nil
+}
+
+// percentile returns the p-th percentile of sorted durations using nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
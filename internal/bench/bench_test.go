@@ -0,0 +1,100 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/llm"
+)
+
+func TestRun(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		fail := requests%5 == 0
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		if fail {
+			http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"pong"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(server.Close)
+	router, err := llm.NewRouter(&config.Config{
+		Providers: []config.Provider{{Name: "mock", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}}},
+	})
+	require.NoError(t, err)
+
+	report, err := Run(context.Background(), router, Options{Model: "m", Requests: 20, Concurrency: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, requests)
+	assert.Equal(t, 20, report.Requests)
+	assert.Equal(t, 16, report.Succeeded)
+	assert.Equal(t, 4, report.Failed)
+	assert.Len(t, report.Errors, 4)
+	assert.InDelta(t, 0.2, report.ErrorRate(), 1e-9)
+	assert.Equal(t, 3, peak, "concurrency is bounded")
+	assert.GreaterOrEqual(t, report.P50, 20*time.Millisecond)
+	assert.LessOrEqual(t, report.P50, report.P95)
+	assert.LessOrEqual(t, report.P95, report.P99)
+	assert.Positive(t, report.Throughput)
+}
+
+func TestRun_InvalidOptions(t *testing.T) {
+	_, err := Run(context.Background(), nil, Options{Requests: 1})
+	assert.EqualError(t, err, "model is required")
+	_, err = Run(context.Background(), nil, Options{Model: "m"})
+	assert.EqualError(t, err, "requests must be positive, got 0")
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	tests := map[string]struct {
+		sorted []time.Duration
+		p      int
+		want   time.Duration
+	}{
+		"empty":  {sorted: nil, p: 50, want: 0},
+		"single": {sorted: sorted[:1], p: 99, want: time.Millisecond},
+		"p50":    {sorted: sorted, p: 50, want: 50 * time.Millisecond},
+		"p95":    {sorted: sorted, p: 95, want: 95 * time.Millisecond},
+		"p99":    {sorted: sorted, p: 99, want: 99 * time.Millisecond},
+		"p0":     {sorted: sorted, p: 0, want: time.Millisecond},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, percentile(tc.sorted, tc.p))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/tui"
+)
+
+// Ask returns a cobra.Command to send a one-off query without a plan.
+//
+//	$ tuna ask --model <model> [--system file] [--query text] < query.md
+func Ask() *cobra.Command {
+	var (
+		model       string
+		systemFile  string
+		query       string
+		temperature float64
+		maxTokens   int
+	)
+
+	command := cobra.Command{
+		Use:   "ask",
+		Short: "Send a one-off query to a model",
+		Long: `Ask sends a single query to a model and prints the response, without
+creating an assistant or a plan. Nothing is written to disk.
+
+The query is read from stdin unless --query is given. Use --system to
+send the content of a file as the system prompt.
+
+The response goes to stdout and token usage to stderr, so the response
+can be piped into other tools.
+
+Examples:
+  echo "Explain TCP slow start" | tuna ask --model sonnet
+  tuna ask --model gpt-4o --system prompt.md --query "Hello"`,
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if model == "" {
+				return fmt.Errorf("--model is required")
+			}
+
+			message := query
+			if !cmd.Flags().Changed("query") {
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("failed to read query from stdin: %w", err)
+				}
+				message = string(data)
+			}
+			if strings.TrimSpace(message) == "" {
+				return fmt.Errorf("query is empty: pass it on stdin or with --query")
+			}
+
+			var systemPrompt string
+			if systemFile != "" {
+				data, err := os.ReadFile(systemFile)
+				if err != nil {
+					return fmt.Errorf("failed to read system prompt: %w", err)
+				}
+				systemPrompt = string(data)
+			}
+
+			cfgResult, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if cfgResult.Deprecated {
+				cmd.PrintErrln(config.DeprecationWarning())
+			}
+
+			router, err := llm.NewRouter(cfgResult.Config)
+			if err != nil {
+				return err
+			}
+
+			var resp *llm.ChatResponse
+			err = tui.RunWithSpinnerOutput(cmd.ErrOrStderr(), fmt.Sprintf("Asking %s", model), func() error {
+				var chatErr error
+				resp, chatErr = router.Chat(cmd.Context(), llm.ChatRequest{
+					Model:        model,
+					SystemPrompt: systemPrompt,
+					UserMessage:  message,
+					Temperature:  temperature,
+					MaxTokens:    maxTokens,
+				})
+				return chatErr
+			})
+			if err != nil {
+				return err
+			}
+
+			cmd.Println(strings.TrimRight(resp.Content, "\n"))
+			cmd.PrintErrf("\n%s via %s: %d prompt + %d output tokens in %s\n",
+				resp.Model, resp.Provider, resp.PromptTokens, resp.OutputTokens, resp.Duration.Round(time.Millisecond))
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&model, "model", "m", "", "Model or alias to ask (required)")
+	command.Flags().StringVar(&systemFile, "system", "", "File with the system prompt")
+	command.Flags().StringVarP(&query, "query", "q", "", "Query text (default: read from stdin)")
+	command.Flags().Float64Var(&temperature, "temperature", 0.7, "Temperature setting")
+	command.Flags().IntVar(&maxTokens, "max-tokens", 4096, "Max tokens for response")
+
+	return &command
+}
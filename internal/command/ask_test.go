@@ -0,0 +1,96 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsk(t *testing.T) {
+	var request struct {
+		Model       string  `json:"model"`
+		Temperature float64 `json:"temperature"`
+		MaxTokens   int     `json:"max_tokens"`
+		Messages    []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request.Messages = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Pong.\n"},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":2}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".tuna.toml"), []byte(`default_provider = "openai"
+
+[aliases]
+4o = "gpt-4o"
+
+[[providers]]
+name = "openai"
+base_url = "`+server.URL+`"
+api_token = "token"
+models = ["gpt-4o"]
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "system.md"), []byte("Be brief."), 0644))
+
+	ask := func(t *testing.T, stdin string, args ...string) (string, string, error) {
+		t.Helper()
+		var out, errOut bytes.Buffer
+		cmd := Ask()
+		cmd.SetOut(&out)
+		cmd.SetErr(&errOut)
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetArgs(args)
+		err := cmd.ExecuteContext(context.Background())
+		return out.String(), errOut.String(), err
+	}
+
+	t.Run("stdin", func(t *testing.T) {
+		out, errOut, err := ask(t, "Ping?", "--model", "4o", "--system", "system.md", "--temperature", "0.3", "--max-tokens", "50")
+		require.NoError(t, err)
+		assert.Equal(t, "Pong.\n", out)
+		assert.Contains(t, errOut, "12 prompt + 2 output tokens")
+
+		assert.Equal(t, "gpt-4o", request.Model)
+		assert.InDelta(t, 0.3, request.Temperature, 1e-6)
+		assert.Equal(t, 50, request.MaxTokens)
+		require.Len(t, request.Messages, 2)
+		assert.Equal(t, "Be brief.", request.Messages[0].Content)
+		assert.Equal(t, "Ping?", request.Messages[1].Content)
+	})
+
+	t.Run("query flag", func(t *testing.T) {
+		_, _, err := ask(t, "ignored", "--model", "gpt-4o", "--query", "Hello")
+		require.NoError(t, err)
+		require.Len(t, request.Messages, 2)
+		assert.Empty(t, request.Messages[0].Content, "no system prompt")
+		assert.Equal(t, "Hello", request.Messages[1].Content)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		_, _, err := ask(t, " \n", "--model", "gpt-4o")
+		assert.EqualError(t, err, "query is empty: pass it on stdin or with --query")
+	})
+
+	t.Run("no model", func(t *testing.T) {
+		_, _, err := ask(t, "Ping?")
+		assert.EqualError(t, err, "--model is required")
+	})
+}
@@ -0,0 +1,128 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+// Assistant returns a cobra.Command for assistant management.
+//
+//	$ tuna assistant <subcommand>
+func Assistant() *cobra.Command {
+	command := cobra.Command{
+		Use:   "assistant",
+		Short: "Manage assistants",
+		Long: `Assistant management commands.
+
+Subcommands:
+  prompt-diff    Compare compiled system prompts of two assistants
+  rename         Rename an assistant and update its plans`,
+	}
+
+	command.AddCommand(
+		assistantPromptDiff(),
+		assistantRename(),
+	)
+
+	return &command
+}
+
+// assistantPromptDiff compares compiled system prompts of two assistants.
+func assistantPromptDiff() *cobra.Command {
+	command := cobra.Command{
+		Use:   "prompt-diff <AssistantID1> <AssistantID2>",
+		Short: "Compare compiled system prompts of two assistants",
+		Long: `Compile the system prompts of two assistants and print a line diff.
+
+Lines only in the first prompt are prefixed with "-",
+lines only in the second prompt with "+".
+
+Examples:
+  tuna assistant prompt-diff my-assistant my-assistant-v2`,
+
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			prompts := make([]string, len(args))
+			for i, id := range args {
+				if err := assistant.ValidateID(id); err != nil {
+					return fmt.Errorf("invalid assistant ID %q: %w", id, err)
+				}
+				prompts[i], err = assistant.CompileSystemPrompt(filepath.Join(cwd, id))
+				if err != nil {
+					return fmt.Errorf("assistant %s: %w", id, err)
+				}
+			}
+
+			if prompts[0] == prompts[1] {
+				cmd.Println("System prompts are identical.")
+				return nil
+			}
+
+			printLineDiff(cmd, args[0], args[1], prompts[0], prompts[1])
+			return nil
+		},
+	}
+
+	return &command
+}
+
+// assistantRename renames an assistant directory and updates its plans.
+func assistantRename() *cobra.Command {
+	command := cobra.Command{
+		Use:   "rename <OldAssistantID> <NewAssistantID>",
+		Short: "Rename an assistant and update its plans",
+		Long: `Rename moves the assistant directory and rewrites assistant_id in
+every plan under its Output/ directory, so existing plans and their
+responses keep working under the new name.
+
+References in the configuration, such as default_assistant, are not
+changed; a warning is printed if any need updating.
+
+Examples:
+  tuna assistant rename my-assistant support-bot`,
+
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			oldID, newID := args[0], args[1]
+			updated, err := plan.RenameAssistant(cwd, oldID, newID)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Renamed assistant %s to %s\n", oldID, newID)
+			cmd.Printf("Updated %d plans\n", len(updated))
+
+			result, err := loadOptionalConfig()
+			if err != nil {
+				cmd.PrintErrf("Warning: cannot check the configuration for %s: %v\n", oldID, err)
+			} else if result != nil {
+				if result.Config.DefaultAssistant == oldID {
+					cmd.PrintErrf("Warning: default_assistant in %s still refers to %s\n", result.Source, oldID)
+				}
+				if slices.Contains(result.Config.SystemPromptPrefixSkip, oldID) {
+					cmd.PrintErrf("Warning: system_prompt_prefix_skip in %s still lists %s\n", result.Source, oldID)
+				}
+			}
+			return nil
+		},
+	}
+
+	return &command
+}
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/bench"
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/tui"
+)
+
+// Bench returns a cobra.Command to benchmark a provider.
+//
+//	$ tuna bench --model <model> [flags]
+func Bench() *cobra.Command {
+	var (
+		provider    string
+		model       string
+		requests    int
+		concurrency int
+		maxTokens   int
+	)
+
+	command := cobra.Command{
+		Use:   "bench",
+		Short: "Measure provider latency and throughput",
+		Long: `Bench fires a number of trivial requests at a model and reports
+latency percentiles (p50/p95/p99), throughput, and error rate.
+
+Rate limits from the configuration are respected, so throughput
+will not exceed the provider's configured rate_limit.
+
+Examples:
+  tuna bench --model sonnet --requests 20
+  tuna bench --provider openrouter --model openai/gpt-4o -n 50 -c 5`,
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgResult, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			cfg := cfgResult.Config
+			if provider != "" {
+				cfg, err = pinProvider(cfg, provider)
+				if err != nil {
+					return err
+				}
+			}
+
+			router, err := llm.NewRouter(cfg)
+			if err != nil {
+				return err
+			}
+
+			fullName, providerName := router.ResolveModel(model)
+
+			var report *bench.Report
+			message := fmt.Sprintf("Benchmarking %s via %s (%d requests)", fullName, providerName, requests)
+			err = tui.RunWithSpinner(message, func() error {
+				var benchErr error
+				report, benchErr = bench.Run(cmd.Context(), router, bench.Options{
+					Model:       model,
+					Requests:    requests,
+					Concurrency: concurrency,
+					MaxTokens:   maxTokens,
+				})
+				return benchErr
+			})
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("\nModel:       %s\n", fullName)
+			cmd.Printf("Provider:    %s\n", providerName)
+			cmd.Printf("Requests:    %d (%d ok, %d failed)\n", report.Requests, report.Succeeded, report.Failed)
+			cmd.Printf("Concurrency: %d\n", concurrency)
+			cmd.Printf("Elapsed:     %s\n\n", report.Elapsed.Round(time.Millisecond))
+
+			cmd.Println("Latency:")
+			cmd.Printf("  p50: %s\n", report.P50.Round(time.Millisecond))
+			cmd.Printf("  p95: %s\n", report.P95.Round(time.Millisecond))
+			cmd.Printf("  p99: %s\n\n", report.P99.Round(time.Millisecond))
+
+			cmd.Printf("Throughput:  %.2f req/s\n", report.Throughput)
+			cmd.Printf("Error rate:  %.1f%%\n", report.ErrorRate()*100)
+
+			if len(report.Errors) > 0 {
+				cmd.Println("\nErrors:")
+				for _, err := range report.Errors {
+					cmd.Printf("  x %s\n", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&provider, "provider", "", "Send all requests to this provider, bypassing model routing")
+	command.Flags().StringVarP(&model, "model", "m", "", "Model to benchmark (alias or full name)")
+	command.Flags().IntVarP(&requests, "requests", "n", 10, "Total number of requests")
+	command.Flags().IntVarP(&concurrency, "concurrency", "c", 1, "Maximum number of requests in flight")
+	command.Flags().IntVar(&maxTokens, "max-tokens", 16, "Max tokens for each response")
+	_ = command.MarkFlagRequired("model")
+
+	return &command
+}
+
+// pinProvider returns a copy of the configuration that routes every model
+// to the named provider. Other settings, such as the global rate limit,
+// are kept.
+func pinProvider(cfg *config.Config, name string) (*config.Config, error) {
+	for _, p := range cfg.Providers {
+		if p.Name == name {
+			p.Models = nil
+			pinned := *cfg
+			pinned.DefaultProvider = p.Name
+			pinned.Providers = []config.Provider{p}
+			return &pinned, nil
+		}
+	}
+	return nil, fmt.Errorf("provider %q not found in configuration", name)
+}
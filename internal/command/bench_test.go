@@ -0,0 +1,51 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+func TestPinProvider(t *testing.T) {
+	cfg := &config.Config{
+		DefaultProvider: "openrouter",
+		GlobalRateLimit: "10rps",
+		Aliases:         map[string]string{"fast": "gpt-4o-mini"},
+		Providers: []config.Provider{
+			{Name: "openrouter", BaseURL: "https://openrouter.ai/api/v1", Models: []string{"gpt-4o"}},
+			{Name: "openai", BaseURL: "https://api.openai.com/v1", Models: []string{"gpt-4o-mini"}},
+		},
+	}
+
+	tests := map[string]struct {
+		provider string
+		wantErr  string
+	}{
+		"known provider":   {provider: "openai"},
+		"unknown provider": {provider: "anthropic", wantErr: `provider "anthropic" not found in configuration`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pinned, err := pinProvider(cfg, tc.provider)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.provider, pinned.DefaultProvider)
+			require.Len(t, pinned.Providers, 1)
+			assert.Equal(t, tc.provider, pinned.Providers[0].Name)
+			assert.Empty(t, pinned.Providers[0].Models)
+			assert.Equal(t, "10rps", pinned.GlobalRateLimit)
+			assert.Equal(t, cfg.Aliases, pinned.Aliases)
+
+			assert.Equal(t, "openrouter", cfg.DefaultProvider, "the original configuration is unchanged")
+			assert.Len(t, cfg.Providers, 2)
+			assert.Equal(t, []string{"gpt-4o-mini"}, cfg.Providers[1].Models)
+		})
+	}
+}
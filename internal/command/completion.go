@@ -0,0 +1,41 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+// completePlanID offers plan IDs found under the current directory
+// as completions for a <PlanID> argument, described by their assistant.
+func completePlanID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	entries, err := plan.List(cwd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		p := entry.Plan
+		if !strings.HasPrefix(p.PlanID, toComplete) {
+			continue
+		}
+		completions = append(completions, fmt.Sprintf("%s\t%s (%d models, %d queries)",
+			p.PlanID, p.AssistantID, len(p.Assistant.LLM.Models), len(p.Queries)))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,14 +1,21 @@
 package command
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"go.octolab.org/toolset/tuna/internal/config"
 	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/tui"
 )
 
 // Config returns a cobra.Command for configuration management.
@@ -21,15 +28,25 @@ func Config() *cobra.Command {
 		Long: `Configuration management commands for tuna.
 
 Subcommands:
-  show      Display current configuration
-  validate  Validate configuration file
-  resolve   Show which provider will be used for a model`,
+  show             Display current configuration
+  providers        List providers and whether their API tokens are set
+  validate         Validate configuration file
+  resolve          Show which provider will be used for a model
+  explain-routing  Trace how a request for a model would be routed
+  migrate          Create a config file from deprecated environment variables
+  sync             Compare provider models and rate limits with live values
+  ping             Check that providers are reachable and authenticated`,
 	}
 
 	command.AddCommand(
 		configShow(),
+		configProviders(),
 		configValidate(),
 		configResolve(),
+		configExplainRouting(),
+		configMigrate(),
+		configSync(),
+		configPing(),
 	)
 
 	return &command
@@ -61,7 +78,20 @@ including providers, aliases, and the default provider.`,
 			cmd.Println()
 
 			// Show default provider
-			cmd.Printf("Default provider: %s\n\n", cfg.DefaultProvider)
+			cmd.Printf("Default provider: %s\n", cfg.DefaultProvider)
+			if cfg.DefaultAssistant != "" {
+				cmd.Printf("Default assistant: %s\n", cfg.DefaultAssistant)
+			}
+			if cfg.MarkdownStyle != "" {
+				cmd.Printf("Markdown style: %s\n", cfg.MarkdownStyle)
+			}
+			if cfg.ConfirmAbove > 0 {
+				cmd.Printf("Confirm above: %d requests\n", cfg.ConfirmAbove)
+			}
+			if cfg.DefaultParallel > 0 {
+				cmd.Printf("Default parallel: %d\n", cfg.DefaultParallel)
+			}
+			cmd.Println()
 
 			// Show providers
 			cmd.Println("Providers:")
@@ -72,12 +102,58 @@ including providers, aliases, and the default provider.`,
 				if p.RateLimit != "" {
 					cmd.Printf("    Rate Limit:  %s\n", p.RateLimit)
 				}
+				if p.ConnectTimeout != "" {
+					cmd.Printf("    Connect:     %s timeout\n", p.ConnectTimeout)
+				}
+				if p.MaxRetries > 0 {
+					retries := fmt.Sprint(p.MaxRetries)
+					if p.RetryJitter != "" {
+						retries += fmt.Sprintf(", %s jitter", p.RetryJitter)
+					}
+					if p.RetryMaxElapsed != "" {
+						retries += fmt.Sprintf(", within %s", p.RetryMaxElapsed)
+					}
+					cmd.Printf("    Retries:     %s\n", retries)
+				}
+				if p.HTTPProxy != "" {
+					cmd.Printf("    HTTP Proxy:  %s\n", p.HTTPProxy)
+				}
+				if p.HTTPSProxy != "" {
+					cmd.Printf("    HTTPS Proxy: %s\n", p.HTTPSProxy)
+				}
+				if p.NoProxy != "" {
+					cmd.Printf("    No Proxy:    %s\n", p.NoProxy)
+				}
+				if len(p.Headers) > 0 {
+					// Values may hold secrets; show only the names
+					names := make([]string, 0, len(p.Headers))
+					for name := range p.Headers {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					cmd.Printf("    Headers:     %s\n", strings.Join(names, ", "))
+				}
+				if p.Moderate {
+					cmd.Println("    Moderation:  enabled")
+				}
 				if len(p.Models) > 0 {
 					cmd.Printf("    Models:      %s\n", strings.Join(p.Models, ", "))
 				}
 				cmd.Println()
 			}
 
+			// Show response post-filters
+			if r := cfg.RejectIf; r != nil {
+				cmd.Println("Reject responses:")
+				if r.MinLength > 0 {
+					cmd.Printf("  shorter than %d chars\n", r.MinLength)
+				}
+				for _, pattern := range r.Contains {
+					cmd.Printf("  containing %q\n", pattern)
+				}
+				cmd.Println()
+			}
+
 			// Show aliases
 			if len(cfg.Aliases) > 0 {
 				cmd.Println("Aliases:")
@@ -97,6 +173,138 @@ including providers, aliases, and the default provider.`,
 	}
 }
 
+// providerStatus is the status of a provider reported by config providers.
+type providerStatus struct {
+	Name       string `json:"name"`
+	BaseURL    string `json:"base_url"`
+	TokenEnv   string `json:"token_env,omitempty"`
+	TokenSet   bool   `json:"token_set"`
+	TokenError string `json:"token_error,omitempty"`
+	RateLimit  string `json:"rate_limit,omitempty"`
+	Models     int    `json:"models"`
+}
+
+// configProviders lists providers with their token status.
+func configProviders() *cobra.Command {
+	var asJSON bool
+
+	command := cobra.Command{
+		Use:   "providers",
+		Short: "List providers and whether their API tokens are set",
+		Long: `List each configured provider with its base URL, whether its API
+token is available (the token itself is never printed), rate limit,
+and number of configured models.
+
+The command fails if any provider's token cannot be resolved,
+so it can be used as a preflight check in CI.
+
+Examples:
+  tuna config providers
+  tuna config providers --json`,
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			var (
+				statuses = make([]providerStatus, 0, len(result.Config.Providers))
+				missing  int
+			)
+			for _, p := range result.Config.Providers {
+				status := providerStatus{
+					Name:      p.Name,
+					BaseURL:   p.BaseURL,
+					TokenEnv:  p.APITokenEnv,
+					TokenSet:  true,
+					RateLimit: p.RateLimit,
+					Models:    len(p.Models),
+				}
+				if _, err := p.ResolveAPIToken(); err != nil {
+					status.TokenSet = false
+					status.TokenError = err.Error()
+					missing++
+				}
+				statuses = append(statuses, status)
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(statuses, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal providers: %w", err)
+				}
+				cmd.Println(string(data))
+			} else {
+				printProviderTable(cmd, statuses)
+			}
+
+			if missing > 0 {
+				return fmt.Errorf("%d of %d providers have no API token", missing, len(statuses))
+			}
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&asJSON, "json", false, "Print providers as JSON")
+
+	return &command
+}
+
+// printProviderTable prints provider statuses as an aligned table.
+func printProviderTable(cmd *cobra.Command, statuses []providerStatus) {
+	rows := [][]string{{"NAME", "BASE URL", "TOKEN", "RATE LIMIT", "MODELS"}}
+	for _, s := range statuses {
+		token := "set"
+		if s.TokenEnv != "" {
+			token += " ($" + s.TokenEnv + ")"
+		}
+		if !s.TokenSet {
+			token = "missing"
+			if s.TokenEnv != "" {
+				token += " ($" + s.TokenEnv + ")"
+			}
+		}
+		rateLimit := s.RateLimit
+		if rateLimit == "" {
+			rateLimit = "-"
+		}
+		models := fmt.Sprint(s.Models)
+		if s.Models == 0 {
+			models = "any"
+		}
+		rows = append(rows, []string{s.Name, s.BaseURL, token, rateLimit, models})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	for r, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			padded := fmt.Sprintf("%-*s", widths[i], cell)
+			switch {
+			case r == 0:
+				padded = tui.Bold.Render(padded)
+			case i == 2 && statuses[r-1].TokenSet:
+				padded = tui.Success.Render(padded)
+			case i == 2:
+				padded = tui.Error.Render(padded)
+			}
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(padded)
+		}
+		cmd.Println(strings.TrimRight(line.String(), " "))
+	}
+}
+
 // configValidate validates configuration.
 func configValidate() *cobra.Command {
 	return &cobra.Command{
@@ -105,26 +313,38 @@ func configValidate() *cobra.Command {
 		Long: `Validate the tuna configuration file.
 
 Checks for:
-  - Valid TOML syntax
+  - Valid TOML, YAML or JSON syntax
   - Required fields (default_provider, providers)
   - Valid rate limit formats
+  - Valid connect timeout durations
   - No duplicate provider names
-  - Default provider exists in providers list`,
+  - Default provider exists in providers list
+  - Default assistant is a valid assistant ID
+
+Also warns about aliases that shadow a provider model name
+and about several aliases mapping to the same model.`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Find config file
 			configPath, err := config.FindConfigFile()
 			if err != nil {
-				return fmt.Errorf("no configuration file found\n\nCreate .tuna.toml in your project or ~/.config/tuna.toml")
+				return fmt.Errorf("no configuration file found\n\nCreate one, looked up in this order:\n%s", config.LookupOrder())
 			}
 
 			// Try to load and validate
-			_, err = config.LoadFromFile(configPath)
+			cfg, err := config.LoadFromFile(configPath)
 			if err != nil {
 				return err
 			}
 
 			cmd.Printf("Configuration is valid: %s\n", configPath)
+
+			if warnings := cfg.Warnings(); len(warnings) > 0 {
+				cmd.Println("\nWarnings:")
+				for _, w := range warnings {
+					cmd.Printf("  ! %s\n", w)
+				}
+			}
 			return nil
 		},
 	}
@@ -191,6 +411,506 @@ Examples:
 	}
 }
 
+// routingStep is a single decision of the routing trace.
+type routingStep struct {
+	Step     string `json:"step"`
+	Provider string `json:"provider,omitempty"` // Set for provider-specific steps
+	Detail   string `json:"detail"`
+}
+
+// configExplainRouting prints every routing decision for a model.
+func configExplainRouting() *cobra.Command {
+	var asJSON bool
+
+	command := cobra.Command{
+		Use:   "explain-routing <model>",
+		Short: "Trace how a request for a model would be routed",
+		Long: `Trace every decision the router makes for a model, without making
+a call: alias resolution, provider selection, the model name sent,
+and the effective base URL, API token source, rate limits, timeouts,
+retries, proxy, moderation, pricing and context window of the provider.
+
+A model listed by several providers is balanced across them by weight;
+the provider-specific steps are then shown for each of them.
+
+Secrets are never printed: only where the API token comes from.
+A provider whose token is not available fails the trace, as it
+would fail any request.
+
+Examples:
+  tuna config explain-routing sonnet
+  tuna config explain-routing gpt-4o --json`,
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			steps, err := explainRouting(result.Config, args[0])
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(steps, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode routing trace: %w", err)
+				}
+				cmd.Println(string(data))
+				return nil
+			}
+
+			cmd.Printf("Routing trace for %s:\n", args[0])
+			for i, step := range steps {
+				name := step.Step
+				if step.Provider != "" {
+					name = fmt.Sprintf("%s [%s]", step.Step, step.Provider)
+				}
+				cmd.Printf("  %2d. %-28s %s\n", i+1, name, step.Detail)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&asJSON, "json", false, "Output as JSON")
+
+	return &command
+}
+
+// explainRouting traces the routing decisions llm.Router makes for a
+// model, together with the provider settings applied to its requests.
+func explainRouting(cfg *config.Config, model string) ([]routingStep, error) {
+	router, err := llm.NewRouter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	route, err := router.Route(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []routingStep
+
+	if route.Alias != "" {
+		steps = append(steps, routingStep{Step: "alias", Detail: fmt.Sprintf("%s -> %s", route.Alias, route.Model)})
+	} else {
+		steps = append(steps, routingStep{Step: "alias", Detail: fmt.Sprintf("%s is not an alias", model)})
+	}
+
+	switch {
+	case route.Fallback:
+		steps = append(steps, routingStep{Step: "provider", Detail: fmt.Sprintf(
+			"%s is not listed by any provider, sent to default_provider %s", route.Model, route.Targets[0].Provider)})
+	case len(route.Targets) == 1:
+		steps = append(steps, routingStep{Step: "provider", Detail: fmt.Sprintf(
+			"%s is listed by %s", route.Model, route.Targets[0].Provider)})
+	default:
+		shares := make([]string, len(route.Targets))
+		for i, target := range route.Targets {
+			shares[i] = fmt.Sprintf("%s weight %d", target.Provider, target.Weight)
+		}
+		steps = append(steps, routingStep{Step: "provider", Detail: fmt.Sprintf(
+			"%s is listed by %d providers, balanced by weight: %s", route.Model, len(route.Targets), strings.Join(shares, ", "))})
+	}
+
+	steps = append(steps, routingStep{Step: "model", Detail: fmt.Sprintf("sent as %q", route.Model)})
+
+	// Global rate limit applies to every provider
+	if route.GlobalRateLimited {
+		steps = append(steps, routingStep{Step: "global rate limit", Detail: cfg.GlobalRateLimit + ", waited for before the provider limit"})
+	} else {
+		steps = append(steps, routingStep{Step: "global rate limit", Detail: "none"})
+	}
+
+	for _, target := range route.Targets {
+		p := cfg.Provider(target.Provider)
+		providerSteps, err := explainProvider(p, target, route.Model)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, providerSteps...)
+	}
+
+	return steps, nil
+}
+
+// explainProvider traces the settings of a provider applied to requests
+// for a model sent to the route target.
+func explainProvider(p *config.Provider, target llm.RouteTarget, model string) ([]routingStep, error) {
+	var steps []routingStep
+	add := func(step, detail string) {
+		steps = append(steps, routingStep{Step: step, Provider: target.Provider, Detail: detail})
+	}
+
+	add("base URL", target.BaseURL)
+
+	// The router has resolved the token, so it is set
+	if p.APIToken != "" {
+		add("API token", "api_token in the configuration")
+	} else {
+		add("API token", fmt.Sprintf("$%s, set", p.APITokenEnv))
+	}
+
+	if len(p.Headers) > 0 {
+		names := make([]string, 0, len(p.Headers))
+		for name := range p.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		add("headers", strings.Join(names, ", "))
+	}
+
+	if target.RateLimited {
+		add("rate limit", p.RateLimit)
+	} else {
+		add("rate limit", "none")
+	}
+
+	timeout, err := p.Timeout()
+	if err != nil {
+		return nil, fmt.Errorf("provider %q: request_timeout: %w", p.Name, err)
+	}
+	if p.RequestTimeout == "" {
+		add("request timeout", fmt.Sprintf("%s (default)", timeout))
+	} else {
+		add("request timeout", timeout.String())
+	}
+	if p.ConnectTimeout != "" {
+		add("connect timeout", p.ConnectTimeout)
+	} else {
+		add("connect timeout", "none")
+	}
+
+	retries := fmt.Sprintf("up to %d", p.MaxRetries)
+	if p.MaxRetries == 0 {
+		retries = "none"
+	} else {
+		if p.RetryJitter != "" {
+			retries += ", jitter " + p.RetryJitter
+		}
+		if p.RetryMaxElapsed != "" {
+			retries += ", at most " + p.RetryMaxElapsed
+		}
+	}
+	add("retries", retries)
+
+	if target.Proxy != nil {
+		add("proxy", target.Proxy.Redacted())
+	} else {
+		add("proxy", "none, direct connection")
+	}
+
+	if target.Moderated {
+		add("moderation", "pre-check before every request")
+	} else {
+		add("moderation", "off")
+	}
+
+	if target.Pricing != (config.Pricing{}) {
+		add("pricing", fmt.Sprintf("%g input / %g output per 1K tokens", target.Pricing.InputPer1K, target.Pricing.OutputPer1K))
+	} else {
+		add("pricing", "not configured")
+	}
+
+	if window := p.ContextWindow(model); window > 0 {
+		add("context window", fmt.Sprintf("%d tokens", window))
+	}
+
+	return steps, nil
+}
+
+// configMigrate writes a config file equivalent to the deprecated environment variables.
+func configMigrate() *cobra.Command {
+	var global bool
+
+	command := cobra.Command{
+		Use:   "migrate",
+		Short: "Create a config file from deprecated environment variables",
+		Long: `Create a configuration file equivalent to the deprecated
+LLM_API_TOKEN and LLM_BASE_URL environment variables.
+
+The base URL is written to the file, while the token is referenced
+via api_token_env = "LLM_API_TOKEN" and is never stored on disk.
+
+The file is created as .tuna.toml in the current directory,
+or as ~/.config/tuna.toml with --global. The command refuses
+to run if a configuration file is already in use.`,
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var path string
+			if global {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("failed to get home directory: %w", err)
+				}
+				path = filepath.Join(home, config.GlobalConfigPath)
+			} else {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+				path = filepath.Join(cwd, config.ConfigFileName)
+			}
+
+			cfg, err := config.MigrateEnv(path)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Configuration created: %s\n", path)
+			cmd.Printf("  Default provider: %s\n", cfg.DefaultProvider)
+			for _, p := range cfg.Providers {
+				cmd.Printf("  Provider %s: %s (token from $%s)\n", p.Name, p.BaseURL, p.APITokenEnv)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&global, "global", false, "Create ~/.config/tuna.toml instead of .tuna.toml")
+
+	return &command
+}
+
+// configSync compares configured models and rate limits with what providers report.
+func configSync() *cobra.Command {
+	var write bool
+
+	command := cobra.Command{
+		Use:   "sync [provider]",
+		Short: "Compare provider models and rate limits with live values",
+		Long: `Fetch the live model list of each provider (or only the given one)
+and compare it with the configuration.
+
+Reported for each provider:
+  - Configured models the provider no longer serves
+  - Served models that are not configured
+  - A rate_limit suggestion based on the x-ratelimit-limit-requests
+    header, if the provider sends one
+
+Nothing is changed unless --write is given, in which case the models
+are synced both ways (models that are no longer served are removed,
+newly served ones are added) and rate_limit is set to the suggestion.
+Providers without models serve every model and are left that way.
+Other content of the configuration file, including comments, is kept.
+
+Examples:
+  tuna config sync
+  tuna config sync openai --write`,
+
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			var only string
+			if len(args) > 0 {
+				only = args[0]
+			}
+			return syncProviders(cmd, result, only, write)
+		},
+	}
+
+	command.Flags().BoolVar(&write, "write", false, "Apply the suggested changes to the configuration file")
+
+	return &command
+}
+
+// syncProviders compares every provider of the loaded configuration, or
+// only the named one, with its live catalog, and with write applies the
+// differences to the configuration file.
+func syncProviders(cmd *cobra.Command, result *config.LoadResult, only string, write bool) error {
+	if write && result.Deprecated {
+		return fmt.Errorf("--write requires a configuration file\n\nRun 'tuna config migrate' to create one")
+	}
+	if write && !config.IsTOML(result.Source) {
+		return fmt.Errorf("--write supports only TOML configuration files, %s is not one", result.Source)
+	}
+
+	router, err := llm.NewRouter(result.Config)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	found, changed := false, false
+	for _, p := range result.Config.Providers {
+		if only != "" && p.Name != only {
+			continue
+		}
+		found = true
+
+		var catalog *llm.Catalog
+		err := tui.RunWithSpinnerOutput(cmd.ErrOrStderr(), fmt.Sprintf("Fetching models from %s", p.Name), func() error {
+			var fetchErr error
+			catalog, fetchErr = router.Catalog(cmd.Context(), p.Name)
+			return fetchErr
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", p.Name, err))
+			continue
+		}
+
+		sync := config.SyncProvider(p, catalog.Models, catalog.RequestsLimit)
+		printProviderSync(cmd, p, sync)
+		if !sync.Changed(p) {
+			continue
+		}
+		changed = true
+
+		if write {
+			if err := config.UpdateProvider(result.Source, p.Name, sync.SyncedModels(p), sync.RateLimit); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if only != "" && !found {
+		return fmt.Errorf("provider %q not found", only)
+	}
+
+	switch {
+	case !changed:
+		cmd.Println("\nConfiguration is in sync.")
+	case write && len(errs) == 0:
+		cmd.Printf("\nConfiguration updated: %s\n", result.Source)
+	case !write:
+		cmd.Printf("\nRun with --write to apply these changes to %s\n", result.Source)
+	}
+
+	return errors.Join(errs...)
+}
+
+// configPing checks every provider with a minimal request.
+func configPing() *cobra.Command {
+	var (
+		model   string
+		timeout time.Duration
+	)
+
+	command := cobra.Command{
+		Use:   "ping [provider]",
+		Short: "Check that providers are reachable and authenticated",
+		Long: `Send a minimal request to each provider (or only the given one) and
+report whether it succeeded and how long it took.
+
+The request is a one-token chat completion for the first model the
+provider declares, or for --model if given. Providers without models
+are checked by listing their models instead.
+
+All providers are checked even if some fail or are misconfigured
+(e.g. their token variable is not set); the command exits with an
+error if any did.
+
+Examples:
+  tuna config ping
+  tuna config ping openai --model gpt-4o-mini`,
+
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			var only string
+			if len(args) > 0 {
+				only = args[0]
+			}
+			return pingProviders(cmd, result.Config, only, model, timeout)
+		},
+	}
+
+	command.Flags().StringVar(&model, "model", "", "Model to probe (default: first model each provider declares)")
+	command.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "Timeout for each provider")
+
+	return &command
+}
+
+// pingProviders pings every provider of cfg, or only the named one,
+// printing a row per provider. Each provider gets its own router, so
+// that one misconfigured provider (e.g. an unset token variable) is
+// reported on its row instead of preventing the others from being checked.
+func pingProviders(cmd *cobra.Command, cfg *config.Config, only, model string, timeout time.Duration) error {
+	checked, failed := 0, 0
+	for _, p := range cfg.Providers {
+		if only != "" && p.Name != only {
+			continue
+		}
+		checked++
+
+		probe := model
+		if probe == "" && len(p.Models) > 0 {
+			probe = p.Models[0]
+		}
+		target := probe
+		if target == "" {
+			target = "list models"
+		}
+
+		single := *cfg
+		single.Providers = []config.Provider{p}
+		router, err := llm.NewRouter(&single)
+		if err != nil {
+			failed++
+			cmd.Printf("  %s %s (%s): %v\n", tui.SymbolError, p.Name, target, err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		start := time.Now()
+		err = router.Ping(ctx, p.Name, probe)
+		latency := time.Since(start).Round(time.Millisecond)
+		cancel()
+
+		if err != nil {
+			failed++
+			cmd.Printf("  %s %s (%s): %v\n", tui.SymbolError, p.Name, target, err)
+			continue
+		}
+		cmd.Printf("  %s %s (%s): OK in %s\n", tui.SymbolSuccess, p.Name, target, latency)
+	}
+	if only != "" && checked == 0 {
+		return fmt.Errorf("provider %q not found", only)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d providers failed", failed, checked)
+	}
+	cmd.Println("\nAll providers are reachable.")
+	return nil
+}
+
+// printProviderSync prints the differences found for a provider.
+func printProviderSync(cmd *cobra.Command, p config.Provider, sync config.ProviderSync) {
+	cmd.Printf("%s:\n", p.Name)
+	if len(p.Models) == 0 {
+		cmd.Printf("  Models:     all %d served models (none configured)\n", len(sync.New))
+	} else {
+		cmd.Printf("  Models:     %d configured, %d still served\n", len(p.Models), len(sync.Models))
+		for _, m := range sync.Stale {
+			cmd.Printf("    - %s (no longer served)\n", m)
+		}
+		for _, m := range sync.New {
+			cmd.Printf("    + %s (not configured)\n", m)
+		}
+	}
+
+	switch {
+	case sync.RateLimit == "":
+		cmd.Println("  Rate limit: not reported by provider")
+	case sync.RateLimit == p.RateLimit:
+		cmd.Printf("  Rate limit: %s\n", p.RateLimit)
+	case p.RateLimit == "":
+		cmd.Printf("  Rate limit: none -> suggested %s\n", sync.RateLimit)
+	default:
+		cmd.Printf("  Rate limit: %s -> suggested %s\n", p.RateLimit, sync.RateLimit)
+	}
+}
+
 // resolveWithoutRouter resolves model without creating actual clients.
 func resolveWithoutRouter(cmd *cobra.Command, cfg *config.Config, model string) error {
 	// Resolve alias
@@ -234,3 +954,14 @@ func resolveWithoutRouter(cmd *cobra.Command, cfg *config.Config, model string)
 
 	return nil
 }
+
+// loadOptionalConfig loads the configuration for commands that also work
+// without one. It returns nil if there is no configuration; a configuration
+// that exists but cannot be loaded is an error.
+func loadOptionalConfig() (*config.LoadResult, error) {
+	result, err := config.Load()
+	if errors.Is(err, config.ErrNoConfig) {
+		return nil, nil
+	}
+	return result, err
+}
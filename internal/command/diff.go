@@ -0,0 +1,151 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
+	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/tui"
+	"go.octolab.org/toolset/tuna/internal/view"
+)
+
+// Diff returns a cobra.Command to compare the responses of two plans.
+//
+//	$ tuna diff <PlanID-A> <PlanID-B>
+func Diff() *cobra.Command {
+	command := cobra.Command{
+		Use:   "diff <PlanID-A> <PlanID-B>",
+		Short: "Compare the responses of two plans",
+		Long: `Diff matches the responses of two plans by query ID and model and
+prints a line diff of every pair that differs, e.g. to see the effect
+of a system prompt change.
+
+Lines only in the first plan are prefixed with "-", lines only in the
+second plan with "+". Models and queries present in only one plan are
+listed and skipped.
+
+Examples:
+  tuna diff 01JG... 01JH...`,
+
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completePlanID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			var (
+				plans  [2]*plan.Plan
+				groups [2][]view.ResponseGroup
+			)
+			for i, ref := range args {
+				p, planPath, err := plan.Load(cwd, ref)
+				if err != nil {
+					return err
+				}
+				plans[i] = p
+				groups[i], err = view.LoadResponses(planPath)
+				if err != nil {
+					return fmt.Errorf("failed to load responses of %s: %w", ref, err)
+				}
+			}
+
+			a, b := plans[0], plans[1]
+			printOnlyIn(cmd, "Models", a, b, onlyIn(a.ViewModels(), b.ViewModels()))
+			printOnlyIn(cmd, "Models", b, a, onlyIn(b.ViewModels(), a.ViewModels()))
+			printOnlyIn(cmd, "Queries", a, b, onlyIn(queryIDs(a), queryIDs(b)))
+			printOnlyIn(cmd, "Queries", b, a, onlyIn(queryIDs(b), queryIDs(a)))
+
+			// Index the second plan's responses by query and model
+			other := make(map[string]map[string]string)
+			for _, group := range groups[1] {
+				other[group.QueryID] = make(map[string]string)
+				for _, resp := range group.Responses {
+					other[group.QueryID][resp.Model] = resp.Content
+				}
+			}
+
+			compared, differ := 0, 0
+			for _, group := range groups[0] {
+				for _, resp := range group.Responses {
+					content, ok := other[group.QueryID][resp.Model]
+					if !ok {
+						continue
+					}
+					compared++
+					if content == resp.Content {
+						continue
+					}
+					differ++
+					cmd.Printf("\n%s\n", tui.Bold.Render(fmt.Sprintf("%s / %s", group.QueryID, resp.Model)))
+					printLineDiff(cmd, a.PlanID, b.PlanID, resp.Content, content)
+				}
+			}
+
+			cmd.Printf("\n%d of %d responses differ\n", differ, compared)
+			return nil
+		},
+	}
+
+	return &command
+}
+
+// queryIDs returns the query IDs of a plan.
+func queryIDs(p *plan.Plan) []string {
+	ids := make([]string, len(p.Queries))
+	for i, q := range p.Queries {
+		ids[i] = q.ID
+	}
+	return ids
+}
+
+// onlyIn returns the items of a missing from b, in order.
+func onlyIn(a, b []string) []string {
+	var missing []string
+	for _, item := range a {
+		if !slices.Contains(b, item) {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
+
+// printOnlyIn notes items present in plan a but not in plan b.
+func printOnlyIn(cmd *cobra.Command, kind string, a, b *plan.Plan, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	cmd.Println(tui.Muted.Render(fmt.Sprintf("%s only in %s (not in %s): %s",
+		kind, a.PlanID, b.PlanID, strings.Join(items, ", "))))
+}
+
+// printLineDiff prints a line diff turning a into b, with lines
+// only in a prefixed with "-" and lines only in b with "+".
+func printLineDiff(cmd *cobra.Command, nameA, nameB, a, b string) {
+	interactive := tui.IsInteractive()
+	cmd.Printf("--- %s\n+++ %s\n", nameA, nameB)
+	for _, line := range assistant.DiffLines(a, b) {
+		switch line.Op {
+		case assistant.DiffDelete:
+			text := "- " + line.Text
+			if interactive {
+				text = tui.Error.Render(text)
+			}
+			cmd.Println(text)
+		case assistant.DiffInsert:
+			text := "+ " + line.Text
+			if interactive {
+				text = tui.Success.Render(text)
+			}
+			cmd.Println(text)
+		default:
+			cmd.Println("  " + line.Text)
+		}
+	}
+}
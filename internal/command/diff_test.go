@@ -0,0 +1,66 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	inputDir := filepath.Join(dir, "Helper", "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	for _, name := range []string{"q1.md", "q2.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte("Question"), 0644))
+	}
+
+	// responses maps a model to the content of its responses by query
+	writePlan := func(planID string, responses map[string]map[string]string) {
+		outputDir := filepath.Join(dir, "Helper", "Output", planID)
+		p := &plan.Plan{PlanID: planID, AssistantID: "Helper", Queries: []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}}}
+		for model, byQuery := range responses {
+			p.Assistant.LLM.Models = append(p.Assistant.LLM.Models, model)
+			modelDir := filepath.Join(outputDir, exec.ModelHash(model))
+			require.NoError(t, os.MkdirAll(modelDir, 0755))
+			for queryID, content := range byQuery {
+				name := plan.ResponseBaseName(queryID) + "_response.md"
+				data := "---\nmodel: " + model + "\n---\n\n" + content
+				require.NoError(t, os.WriteFile(filepath.Join(modelDir, name), []byte(data), 0644))
+			}
+		}
+		require.NoError(t, plan.Save(filepath.Join(outputDir, "plan.toml"), p))
+	}
+	writePlan("plan-a", map[string]map[string]string{
+		"gpt-4o": {"q1.md": "Same.\n", "q2.md": "Go is a language.\nIt is fast.\n"},
+		"o1":     {"q1.md": "Only in A.\n"},
+	})
+	writePlan("plan-b", map[string]map[string]string{
+		"gpt-4o": {"q1.md": "Same.\n", "q2.md": "Go is a language.\nIt is simple.\n"},
+		"sonnet": {"q1.md": "Only in B.\n"},
+	})
+
+	var out, errOut bytes.Buffer
+	cmd := Diff()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{"plan-a", "plan-b"})
+	require.NoError(t, cmd.Execute())
+
+	report := out.String()
+	assert.Contains(t, report, "Models only in plan-a (not in plan-b): o1\n")
+	assert.Contains(t, report, "Models only in plan-b (not in plan-a): sonnet\n")
+	assert.NotContains(t, report, "Queries only in")
+	assert.Contains(t, report, "q2.md / gpt-4o\n--- plan-a\n+++ plan-b\n  Go is a language.\n- It is fast.\n+ It is simple.\n")
+	assert.NotContains(t, report, "q1.md / gpt-4o", "identical responses are not shown")
+	assert.NotContains(t, report, "Only in", "responses of models in one plan are skipped")
+	assert.Contains(t, report, "1 of 2 responses differ\n")
+}
@@ -1,9 +1,17 @@
 package command
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -22,8 +30,33 @@ import (
 func Exec() *cobra.Command {
 	var (
 		parallel   int
+		maxTask    time.Duration
 		dryRun     bool
 		continueOp bool
+		eventsFile string
+		maxTokens  string
+		retries    int
+		noLimits   bool
+		skipSame   bool
+		force      bool
+		raw        bool
+		yes        bool
+		compact    bool
+		waitLock   bool
+		checkCfg   bool
+		printCurl  bool
+		estimate   bool
+		outTokens  int
+		onlyModels []string
+		onlyQuery  []string
+		budget     float64
+		shuffle    bool
+		shuffleBy  uint64
+		streamDisk bool
+		noStream   bool
+		logJSON    bool
+		watchCfg   bool
+		strictCtx  bool
 	)
 
 	command := cobra.Command{
@@ -32,24 +65,74 @@ func Exec() *cobra.Command {
 		Long: `Execute runs the specified plan, sending queries to the configured models.
 
 Configuration is loaded from (in order of priority):
-  1. .tuna.toml in current directory or parent directories
-  2. ~/.config/tuna.toml
-  3. Environment variables (deprecated): LLM_API_TOKEN, LLM_BASE_URL
-
-Use 'tuna config show' to see the current configuration.`,
-
-		Args: cobra.ExactArgs(1),
+` + config.LookupOrder() + `
+  3. Environment variables (deprecated): ` + config.EnvAPIToken + ", " + config.EnvBaseURL + `
+
+Use 'tuna config show' to see the current configuration.
+
+In a terminal, progress is shown interactively, with the last line of
+each running response as it is generated. Responses are then streamed;
+if a provider refuses to open a stream, the request is sent again
+without streaming. Use --no-stream for providers that stream without
+reporting token usage. Quitting with q or ctrl+c stops the run;
+requests in flight are cancelled.
+
+Use --continue to resume an interrupted run: responses that were already
+written with execution metadata are kept and only the rest is sent.
+
+Use --estimate-only to budget a run: every request is sent with
+max_tokens=1 to measure its input tokens, and the full-run cost is
+projected from configured pricing and --expected-output-tokens. As
+these are real requests, confirm_above applies to them too.
+
+Use --model and --query (both repeatable) to run only part of the plan.
+
+Use --max-tokens-per-model to override max tokens of some models, e.g.
+--max-tokens-per-model gpt-4o=8000,sonnet=4000. Models may be named by
+their alias or full name; each must be one of the plan models.
+
+Use --parallel (or default_parallel) to send several requests at a time.
+Rate limits still apply; responses and the summary keep plan order.
+
+Use --budget to refuse runs whose projected cost exceeds a cap. The
+projection approximates input tokens from prompt length and assumes
+every response uses its max tokens, so it errs on the high side. It
+applies to --dry-run as well.
+
+Use --shuffle-queries to run the queries of each model in a different
+random order, e.g. to detect results that depend on prompt caching.
+Pass --shuffle-seed to reproduce the orders of an earlier run; without
+it a seed is picked and printed. --dry-run lists queries in run order.
+
+Use --raw to debug provider responses: the full JSON body of each
+response is printed to stdout instead of being written to a response
+file, so the plan's responses are left as they are. Progress is written
+to stderr; --raw cannot be combined with --compact-json.
+
+Use --stream-to-disk to write each response to <response>.partial as it
+is generated. The partial file is replaced by the response file with
+full metadata when the response completes, so if exec is interrupted it
+keeps the text received so far.
+
+If context_windows is configured for a model, requests whose approximate
+input plus max tokens exceed the window are reported before the run.
+With --strict-context, the run is refused instead.
+
+Use --log-json in CI pipelines to replace the per-task progress lines
+with one JSON object per line on stderr. It implies non-interactive
+output; the final summary is still printed to stdout.
+
+Use --watch-config for long runs: when the config file changes, requests
+sent afterwards use the new configuration (e.g. a rotated token or a new
+rate limit), while requests in flight finish with the old one. A change
+that fails validation is reported and the previous configuration kept.
+It implies non-interactive output, so reloads can be reported.`,
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePlanID,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			planID := args[0]
 
-			// Warn about unimplemented flags
-			if parallel > 1 {
-				cmd.PrintErrln("Warning: --parallel is not yet implemented, using default (1)")
-			}
-			if continueOp {
-				cmd.PrintErrln("Warning: --continue is not yet implemented")
-			}
-
 			// Get working directory
 			cwd, err := os.Getwd()
 			if err != nil {
@@ -61,15 +144,90 @@ Use 'tuna config show' to see the current configuration.`,
 			if err != nil {
 				return err
 			}
+			planID = p.PlanID // The plan may be referenced by name
 
 			assistantDir := plan.AssistantDir(planPath)
 
+			maxTokensPerModel, err := exec.ParseModelInts(maxTokens)
+			if err != nil {
+				return fmt.Errorf("--max-tokens-per-model: %w", err)
+			}
+			if len(maxTokensPerModel) > 0 {
+				aliases, err := modelAliases()
+				if err != nil {
+					return err
+				}
+				maxTokensPerModel, err = exec.MatchModels(p.Assistant.LLM.Models, maxTokensPerModel, aliases)
+				if err != nil {
+					return fmt.Errorf("--max-tokens-per-model: %w", err)
+				}
+			}
+
+			// Restrict the execution matrix
+			p, err = exec.FilterPlan(p, onlyModels, onlyQuery)
+			if err != nil {
+				return err
+			}
+			opts := exec.Options{
+				DryRun:            dryRun,
+				Parallel:          parallel,
+				Continue:          continueOp,
+				MaxTokensPerModel: maxTokensPerModel,
+				SkipUnchanged:     skipSame,
+				Force:             force,
+				WaitLock:          waitLock,
+
+				MaxDurationPerTask: maxTask,
+				ShuffleQueries:     shuffle,
+				ShuffleSeed:        shuffleBy,
+				StreamToDisk:       streamDisk,
+			}
+			if cmd.Flags().Changed("shuffle-seed") && !shuffle {
+				return fmt.Errorf("--shuffle-seed requires --shuffle-queries")
+			}
+			if shuffle && !cmd.Flags().Changed("shuffle-seed") {
+				opts.ShuffleSeed = uint64(time.Now().UnixNano())
+				cmd.PrintErrf("Shuffling queries with --shuffle-seed %d\n", opts.ShuffleSeed)
+			}
+
+			if budget < 0 {
+				return fmt.Errorf("--budget must not be negative")
+			}
+			var projection *exec.CostProjection // Reused by the confirmation
+			if budget > 0 {
+				cfgResult, err := config.Load()
+				if err != nil {
+					return err
+				}
+				projection, err = checkBudget(cmd, exec.New(p, assistantDir, nil, opts), cfgResult.Config, budget)
+				if err != nil {
+					return err
+				}
+			}
+
 			// Dry run mode
 			if dryRun {
-				executor := exec.New(p, assistantDir, nil, exec.Options{DryRun: true})
+				executor := exec.New(p, assistantDir, nil, opts)
 				cmd.Print(executor.DryRun())
+				if printCurl {
+					if err := printCurlCommands(cmd, executor); err != nil {
+						return err
+					}
+				}
+				if checkCfg {
+					return checkConfig(cmd, p)
+				}
 				return nil
 			}
+			if checkCfg || printCurl {
+				return fmt.Errorf("--check-config and --print-curl require --dry-run")
+			}
+			if outTokens < 0 {
+				return fmt.Errorf("--expected-output-tokens must not be negative")
+			}
+			if raw && compact {
+				return fmt.Errorf("--raw cannot be combined with --compact-json")
+			}
 
 			// Load configuration
 			cfgResult, err := config.Load()
@@ -82,81 +240,220 @@ Use 'tuna config show' to see the current configuration.`,
 				cmd.PrintErrln(config.DeprecationWarning())
 			}
 
+			// Ask before sending many paid requests; estimating sends
+			// one request per task as well, while a run with --continue
+			// only sends the pending ones
+			total := len(p.Assistant.LLM.Models) * len(p.Queries)
+			if !estimate {
+				total = exec.New(p, assistantDir, nil, opts).PendingTasks()
+			}
+			if threshold := cfgResult.Config.ConfirmThreshold(); !yes && total > threshold {
+				question := fmt.Sprintf("Estimating will send %d requests with max_tokens=1. Continue?", total)
+				if !estimate {
+					if projection == nil {
+						projected, err := projectCost(cmd, exec.New(p, assistantDir, nil, opts), cfgResult.Config)
+						if err != nil {
+							return err
+						}
+						projection = &projected
+					}
+					question = fmt.Sprintf("Plan will send %d requests (projected cost %.4f). Continue?", total, projection.Cost)
+				}
+				if err := confirmRequests(cmd, total, threshold, tui.IsInteractive(), question); err != nil {
+					return err
+				}
+			}
+
+			if err := checkContextWindows(cmd, p, assistantDir, opts, cfgResult.Config, strictCtx); err != nil {
+				return err
+			}
+
+			// Apply default_parallel unless --parallel is set explicitly
+			if !cmd.Flags().Changed("parallel") && cfgResult.Config.DefaultParallel > 0 {
+				opts.Parallel = cfgResult.Config.DefaultParallel
+			}
+			if opts.Parallel < 1 {
+				return fmt.Errorf("--parallel must be at least 1, got %d", opts.Parallel)
+			}
+
 			// Create router
-			router, err := llm.NewRouter(cfgResult.Config)
+			var routerOpts []llm.RouterOption
+			if noLimits {
+				routerOpts = append(routerOpts, llm.WithoutRateLimits())
+			}
+			if raw {
+				routerOpts = append(routerOpts, llm.WithRawResponses())
+				opts.Store = exec.DiscardStore{}
+			}
+			router, err := llm.NewRouter(cfgResult.Config, routerOpts...)
 			if err != nil {
 				return err
 			}
 
-			// Execute with TUI or non-interactive mode
-			if tui.IsInteractive() {
-				return executeWithTUI(cmd, p, assistantDir, router, planID, parallel, continueOp)
+			if estimate {
+				return printEstimate(cmd, exec.New(p, assistantDir, router, opts), router, outTokens)
+			}
+
+			// Requests go through a router rebuilt on config changes if watched
+			var client llm.RoutingClient = router
+			if watchCfg {
+				reloading, stop, err := watchConfig(cmd, cfgResult, routerOpts)
+				if err != nil {
+					return err
+				}
+				defer stop()
+				client = reloading
+			}
+
+			opts.RejectIf = cfgResult.Config.RejectIf
+			opts.TimestampPrecision = cfgResult.Config.Precision()
+			opts.RetryRejected = retries
+
+			// Open structured event log if requested
+			var events *exec.EventLog
+			if eventsFile != "" {
+				events, err = exec.NewEventLog(eventsFile, planID)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if closeErr := events.Close(); closeErr != nil {
+						cmd.PrintErrln("Warning:", closeErr)
+					}
+				}()
+				events.RunStart(len(p.Assistant.LLM.Models), len(p.Queries))
+			}
+
+			if opts.StreamToDisk {
+				if err := exec.CheckStreaming(client, opts.Store); err != nil {
+					cmd.PrintErrf("Warning: --stream-to-disk is ignored: %v\n", err)
+				}
 			}
-			return executeNonInteractive(cmd, p, assistantDir, router, planID, parallel, continueOp)
+
+			// Execute with TUI or non-interactive mode; raw output and
+			// config reload messages are never interactive
+			if compact {
+				return executeCompactJSON(cmd, p, assistantDir, client, planID, opts, events)
+			}
+			if tui.IsInteractive() && !raw && !logJSON && !watchCfg {
+				opts.LiveOutput = !noStream
+				return executeWithTUI(cmd, p, assistantDir, client, planID, opts, events)
+			}
+			return executeNonInteractive(cmd, p, assistantDir, client, planID, opts, events, logJSON, raw)
 		},
 	}
 
-	command.Flags().IntVarP(&parallel, "parallel", "p", 1, "Number of parallel requests")
+	command.Flags().IntVarP(&parallel, "parallel", "p", 1, "Number of parallel requests (overrides default_parallel)")
+	command.Flags().DurationVar(&maxTask, "max-duration-per-task", 0, "Fail a task whose generation takes longer, e.g. 2m (0 = no limit)")
+	command.Flags().StringArrayVar(&onlyModels, "model", nil, "Execute only this model (repeatable)")
+	command.Flags().StringArrayVar(&onlyQuery, "query", nil, "Execute only this query ID (repeatable)")
+	command.Flags().BoolVar(&shuffle, "shuffle-queries", false, "Run each model's queries in a random, per-model order")
+	command.Flags().Uint64Var(&shuffleBy, "shuffle-seed", 0, "With --shuffle-queries, the seed that reproduces the orders")
+	command.Flags().BoolVar(&streamDisk, "stream-to-disk", false, "Stream responses to .partial files while they are generated")
+	command.Flags().BoolVar(&noStream, "no-stream", false, "Wait for complete responses instead of showing them as they are generated")
 	command.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be executed without making API calls")
 	command.Flags().BoolVar(&continueOp, "continue", false, "Continue from last checkpoint if interrupted")
+	command.Flags().StringVar(&eventsFile, "events-file", "", "Write execution events as JSON Lines to this file")
+	command.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation for large runs (see confirm_above)")
+	command.Flags().BoolVar(&printCurl, "print-curl", false, "With --dry-run, print a curl command reproducing each request")
+	command.Flags().BoolVar(&estimate, "estimate-only", false, "Measure input tokens with max_tokens=1 requests and project the full-run cost")
+	command.Flags().IntVar(&outTokens, "expected-output-tokens", 500, "With --estimate-only, the expected response length in tokens")
+	command.Flags().Float64Var(&budget, "budget", 0, "Refuse to run if the projected cost exceeds this amount (0 = no cap)")
+	command.Flags().BoolVar(&strictCtx, "strict-context", false, "Refuse to run if a request may exceed its model's context_windows entry")
+	command.Flags().BoolVar(&checkCfg, "check-config", false, "With --dry-run, also verify configuration, API tokens and model routing")
+	command.Flags().BoolVar(&waitLock, "wait", false, "Wait for another exec of the same plan to finish instead of failing")
+	command.Flags().BoolVar(&compact, "compact-json", false, "Print only a single-line JSON run report, for log ingestion")
+	command.Flags().BoolVar(&logJSON, "log-json", false, "Write progress as JSON Lines to stderr instead of plain text")
+	command.Flags().BoolVar(&watchCfg, "watch-config", false, "Apply changes to the config file to requests sent after them")
+	command.Flags().BoolVar(&raw, "raw", false, "Print the raw provider JSON of each response")
+	command.Flags().BoolVar(&force, "force", false, "Overwrite responses pinned in tuna view")
+	command.Flags().BoolVar(&skipSame, "skip-unchanged", false, "Reuse responses of identical requests from previous runs of any plan")
+	command.Flags().BoolVar(&noLimits, "ignore-rate-limits", false, "Ignore configured provider rate limits (e.g. for local mocks)")
+	command.Flags().IntVar(&retries, "retry-rejected", 0, "Re-request responses rejected by reject_if rules up to this many times")
+	command.Flags().StringVar(&maxTokens, "max-tokens-per-model", "", "Per-model max tokens overrides (e.g. gpt-4o=8000,sonnet=4000)")
 
 	return &command
 }
 
-func executeWithTUI(cmd *cobra.Command, p *plan.Plan, assistantDir string, router llm.ChatClient, planID string, parallel int, continueOp bool) error {
+func executeWithTUI(cmd *cobra.Command, p *plan.Plan, assistantDir string, router llm.RoutingClient, planID string, opts exec.Options, events *exec.EventLog) error {
 	// Create TUI model
 	models := p.Assistant.LLM.Models
 	queries := make([]string, len(p.Queries))
 	for i, q := range p.Queries {
 		queries[i] = q.ID
 	}
+	providers := make(map[string]string, len(models))
+	for _, m := range models {
+		_, providers[m] = router.ResolveModel(m)
+	}
 
-	model := tuiexec.New(models, queries)
-	program := tea.NewProgram(model, tea.WithAltScreen())
-
-	// Create executor with progress callback
-	executor := exec.New(p, assistantDir, router, exec.Options{
-		Parallel: parallel,
-		Continue: continueOp,
-		OnProgress: func(event exec.ProgressEvent) {
-			switch event.Type {
-			case exec.EventTaskStart:
-				program.Send(tuiexec.TaskStartMsg{
-					Model:   event.Model,
-					QueryID: event.QueryID,
-				})
-			case exec.EventTaskDone:
-				program.Send(tuiexec.TaskDoneMsg{
-					Model:   event.Model,
-					QueryID: event.QueryID,
-					Tokens: tuiexec.TokenUsage{
-						Prompt: event.Tokens.Prompt,
-						Output: event.Tokens.Output,
-					},
-					Duration: event.Duration,
-				})
-			case exec.EventTaskError:
-				program.Send(tuiexec.TaskErrorMsg{
-					Model:   event.Model,
-					QueryID: event.QueryID,
-					Err:     event.Err,
-				})
-			}
-		},
-	})
+	// Execution is tied to the program: quitting it cancels running requests
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	aggregator := exec.NewProgressAggregator(models, len(queries), providers)
+	model := tuiexec.New(models, queries, providers, aggregator)
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx))
+
+	// Create executor with progress callback; unless --no-stream is set,
+	// responses are streamed to show them as they arrive
+	opts.OnProgress = events.Wrap(aggregator.Wrap(func(event exec.ProgressEvent) {
+		switch event.Type {
+		case exec.EventTaskStart:
+			program.Send(tuiexec.TaskStartMsg{
+				Model:   event.Model,
+				QueryID: event.QueryID,
+			})
+		case exec.EventTaskDone:
+			program.Send(tuiexec.TaskDoneMsg{
+				Model:   event.Model,
+				QueryID: event.QueryID,
+				Tokens: tuiexec.TokenUsage{
+					Prompt: event.Tokens.Prompt,
+					Output: event.Tokens.Output,
+				},
+				Duration: event.Duration,
+			})
+		case exec.EventTaskError:
+			program.Send(tuiexec.TaskErrorMsg{
+				Model:   event.Model,
+				QueryID: event.QueryID,
+				Err:     event.Err,
+			})
+		case exec.EventTaskSkipped:
+			program.Send(tuiexec.TaskSkippedMsg{
+				Model:   event.Model,
+				QueryID: event.QueryID,
+			})
+		case exec.EventTaskOutput:
+			program.Send(tuiexec.TaskOutputMsg{
+				Model:   event.Model,
+				QueryID: event.QueryID,
+				Output:  event.Output,
+			})
+		}
+	}))
+	executor := exec.New(p, assistantDir, router, opts)
 
 	// Run executor in background
 	var summary *exec.ExecutionSummary
 	var execErr error
+	executed := make(chan struct{})
 
 	go func() {
-		ctx := context.Background()
+		defer close(executed)
 		summary, execErr = executor.Execute(ctx)
+		if events != nil {
+			events.RunEnd(summary, execErr)
+		}
 		program.Send(tuiexec.ExecutionDoneMsg{Err: execErr})
 	}()
 
-	// Run TUI
-	if _, err := program.Run(); err != nil {
+	// Run TUI, then stop the executor if it was quit early
+	_, err := program.Run()
+	cancel()
+	<-executed
+	if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
@@ -165,34 +462,78 @@ func executeWithTUI(cmd *cobra.Command, p *plan.Plan, assistantDir string, route
 		cmd.Println()
 		cmd.Println(tui.Bold.Render("Output files:"))
 		for _, result := range summary.Results {
-			cmd.Printf("  %s %s\n", tui.SymbolSuccess, result.OutputPath)
+			if result.Flagged {
+				cmd.Printf("  %s %s %s\n", tui.Warning.Render("!"), result.OutputPath,
+					tui.Muted.Render("(flagged by moderation)"))
+				continue
+			}
+			if result.Pinned {
+				cmd.Printf("  %s %s %s\n", tui.SymbolSkipped, result.OutputPath,
+					tui.Muted.Render("(pinned, kept)"))
+				continue
+			}
+			if result.Resumed {
+				cmd.Printf("  %s %s %s\n", tui.SymbolSkipped, result.OutputPath,
+					tui.Muted.Render("(completed earlier, skipped)"))
+				continue
+			}
+			if result.Skipped {
+				cmd.Printf("  %s %s %s\n", tui.SymbolSkipped, result.OutputPath,
+					tui.Muted.Render("(unchanged, reused)"))
+				continue
+			}
+			cmd.Printf("  %s %s %s\n", tui.SymbolSuccess, result.OutputPath,
+				tui.Muted.Render(fmt.Sprintf("(%d words, %d chars)", result.Words, result.Chars)))
 		}
 	}
+	if summary != nil && summary.ErrorLog != "" {
+		cmd.Println()
+		cmd.Println(tui.RenderKeyValue("Error log", summary.ErrorLog))
+	}
 
 	return execErr
 }
 
-func executeNonInteractive(cmd *cobra.Command, p *plan.Plan, assistantDir string, router llm.ChatClient, planID string, parallel int, continueOp bool) error {
+// executeNonInteractive runs the plan printing plain progress lines.
+// With raw, progress goes to stderr so that stdout holds only the report
+// with the raw provider responses.
+func executeNonInteractive(cmd *cobra.Command, p *plan.Plan, assistantDir string, router llm.ChatClient, planID string, opts exec.Options, events *exec.EventLog, logJSON, raw bool) error {
+	progress := cmd.OutOrStdout()
+	if raw {
+		progress = cmd.ErrOrStderr()
+	}
+
 	// Execute
-	executor := exec.New(p, assistantDir, router, exec.Options{
-		Parallel: parallel,
-		Continue: continueOp,
-		OnProgress: func(event exec.ProgressEvent) {
-			// Simple progress output for non-interactive mode
-			switch event.Type {
-			case exec.EventTaskStart:
-				cmd.Printf("  Processing %s with %s...\n", event.QueryID, event.Model)
-			case exec.EventTaskDone:
-				cmd.Printf("  ✓ %s -> %s (%d tokens)\n", event.QueryID, event.Model,
-					event.Tokens.Prompt+event.Tokens.Output)
-			case exec.EventTaskError:
-				cmd.Printf("  ✗ %s -> %s: %v\n", event.QueryID, event.Model, event.Err)
-			}
-		},
-	})
+	aggregator := exec.NewProgressAggregator(p.Assistant.LLM.Models, len(p.Queries), nil)
+	opts.OnProgress = events.Wrap(aggregator.Wrap(func(event exec.ProgressEvent) {
+		if logJSON {
+			writeProgressLog(cmd.ErrOrStderr(), event)
+			return
+		}
+
+		// Simple progress output for non-interactive mode
+		snapshot := aggregator.Snapshot()
+		switch event.Type {
+		case exec.EventTaskStart:
+			fmt.Fprintf(progress, "  Processing %s with %s...\n", event.QueryID, event.Model)
+		case exec.EventTaskDone:
+			fmt.Fprintf(progress, "  ✓ [%d/%d] %s -> %s (%d tokens)\n", snapshot.Finished(), snapshot.Total,
+				event.QueryID, event.Model, event.Tokens.Prompt+event.Tokens.Output)
+		case exec.EventTaskError:
+			fmt.Fprintf(progress, "  ✗ [%d/%d] %s -> %s: %v\n", snapshot.Finished(), snapshot.Total,
+				event.QueryID, event.Model, event.Err)
+		case exec.EventTaskSkipped:
+			fmt.Fprintf(progress, "  = [%d/%d] %s -> %s (completed earlier)\n", snapshot.Finished(), snapshot.Total,
+				event.QueryID, event.Model)
+		}
+	}))
+	executor := exec.New(p, assistantDir, router, opts)
 
 	ctx := context.Background()
 	summary, err := executor.Execute(ctx)
+	if events != nil {
+		events.RunEnd(summary, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -202,14 +543,52 @@ func executeNonInteractive(cmd *cobra.Command, p *plan.Plan, assistantDir string
 	cmd.Printf("Plan:      %s\n", planID)
 	cmd.Printf("Queries:   %d\n", summary.TotalQueries)
 	cmd.Printf("Models:    %d\n", summary.TotalModels)
-	cmd.Printf("Tokens:    %d prompt + %d output = %d total\n\n",
+	cmd.Printf("Tokens:    %d prompt + %d output = %d total\n",
 		summary.TotalTokens.Prompt,
 		summary.TotalTokens.Output,
 		summary.TotalTokens.Prompt+summary.TotalTokens.Output)
+	if summary.TotalCost > 0 {
+		cmd.Printf("Cost:      %.4f\n", summary.TotalCost)
+	}
+	if cumulative := summary.CumulativeTokens; cumulative.Prompt != summary.TotalTokens.Prompt ||
+		cumulative.Output != summary.TotalTokens.Output {
+		cmd.Printf("All runs:  %d prompt + %d output = %d total\n",
+			cumulative.Prompt, cumulative.Output, cumulative.Prompt+cumulative.Output)
+	}
+	cmd.Println()
+
+	if len(summary.Providers) > 1 {
+		cmd.Println("Providers:")
+		for _, name := range summary.ProviderNames() {
+			ps := summary.Providers[name]
+			cmd.Printf("  %s: %d results, %d errors, %d tokens\n", ps.Provider,
+				ps.Results, ps.Errors, ps.Tokens.Prompt+ps.Tokens.Output)
+		}
+		cmd.Println()
+	}
 
 	cmd.Println("Results:")
 	for _, result := range summary.Results {
-		cmd.Printf("  + %s -> %s\n", result.QueryID, result.OutputPath)
+		if result.Flagged {
+			cmd.Printf("  ! %s -> %s (flagged by moderation)\n", result.QueryID, result.OutputPath)
+			continue
+		}
+		if result.Pinned {
+			cmd.Printf("  = %s -> %s (pinned, kept)\n", result.QueryID, result.OutputPath)
+			continue
+		}
+		if result.Resumed {
+			cmd.Printf("  = %s -> %s (completed earlier, skipped)\n", result.QueryID, result.OutputPath)
+			continue
+		}
+		if result.Skipped {
+			cmd.Printf("  = %s -> %s (unchanged, reused)\n", result.QueryID, result.OutputPath)
+			continue
+		}
+		cmd.Printf("  + %s -> %s (%d words, %d chars)\n", result.QueryID, result.OutputPath, result.Words, result.Chars)
+		if len(result.Raw) > 0 {
+			printRaw(cmd, result.Raw)
+		}
 	}
 
 	if len(summary.Errors) > 0 {
@@ -218,6 +597,345 @@ func executeNonInteractive(cmd *cobra.Command, p *plan.Plan, assistantDir string
 			cmd.Printf("  x %s\n", err)
 		}
 	}
+	if summary.ErrorLog != "" {
+		cmd.Printf("\nError log: %s\n", summary.ErrorLog)
+	}
+
+	return nil
+}
+
+// watchConfig creates a router that is rebuilt whenever the loaded config
+// file changes, until stop is called. Reloads are reported on stderr;
+// an invalid change keeps the previous router.
+func watchConfig(cmd *cobra.Command, cfgResult *config.LoadResult, opts []llm.RouterOption) (router *llm.ReloadingRouter, stop func(), err error) {
+	if cfgResult.Source == "environment" {
+		return nil, nil, fmt.Errorf("--watch-config requires a configuration file")
+	}
+	router, err = llm.NewReloadingRouter(cfgResult.Source, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		logf := func(format string, args ...any) {
+			cmd.PrintErrf("Config: "+format+"\n", args...)
+		}
+		if err := router.Watch(ctx, logf); err != nil {
+			cmd.PrintErrf("Warning: %v\n", err)
+		}
+	}()
+	return router, cancel, nil
+}
+
+// printCurlCommands prints a curl command for every task of the plan.
+// API tokens are referenced by environment variable or redacted.
+func printCurlCommands(cmd *cobra.Command, executor *exec.Executor) error {
+	cfgResult, err := config.Load()
+	if err != nil {
+		return err
+	}
 
+	requests, err := executor.Requests()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range requests {
+		fullName, provider := cfgResult.Config.ResolveModel(task.Model)
+		if provider == nil {
+			return fmt.Errorf("no provider found for model %q", task.Model)
+		}
+
+		req := task.Request
+		req.Model = fullName
+		curl, err := llm.CurlCommand(provider, req)
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("\n# %s -> %s (%s)\n%s\n", task.QueryID, task.Model, provider.Name, curl)
+	}
 	return nil
 }
+
+// printEstimate measures the input tokens of every task and prints the
+// projected cost of a full run, per model and in total.
+func printEstimate(cmd *cobra.Command, executor *exec.Executor, router *llm.Router, outputTokens int) error {
+	estimates, err := executor.Estimate(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var (
+		models  []string
+		byModel = make(map[string][]exec.TaskEstimate)
+		spent   float64
+		failed  int
+	)
+	for _, estimate := range estimates {
+		if _, ok := byModel[estimate.Model]; !ok {
+			models = append(models, estimate.Model)
+		}
+		byModel[estimate.Model] = append(byModel[estimate.Model], estimate)
+		spent += estimate.Cost
+		if estimate.Err != nil {
+			failed++
+			cmd.PrintErrf("Warning: %s -> %s: %v\n", estimate.QueryID, estimate.Model, estimate.Err)
+		}
+	}
+
+	cmd.Printf("Estimate (%d output tokens per response):\n", outputTokens)
+	for _, model := range models {
+		projection := exec.ProjectCost(byModel[model], outputTokens, router.Pricing)
+		cmd.Printf("  %s: %d tasks, %d input + %d output tokens, cost %.4f\n",
+			model, projection.Tasks, projection.InputTokens, projection.OutputTokens, projection.Cost)
+	}
+
+	total := exec.ProjectCost(estimates, outputTokens, router.Pricing)
+	cmd.Printf("Total: %d tasks, %d input + %d output tokens, cost %.4f\n",
+		total.Tasks, total.InputTokens, total.OutputTokens, total.Cost)
+	if spent > 0 {
+		cmd.Printf("Spent on estimation: %.4f\n", spent)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tasks could not be estimated", failed, len(estimates))
+	}
+	return nil
+}
+
+// checkBudget projects the worst-case cost of the plan from configured
+// pricing and fails if it exceeds the budget. Nothing is sent; the
+// projection is returned for the confirmation.
+func checkBudget(cmd *cobra.Command, executor *exec.Executor, cfg *config.Config, budget float64) (*exec.CostProjection, error) {
+	projection, err := projectCost(cmd, executor, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if projection.Cost > budget {
+		return nil, fmt.Errorf("projected cost %.4f exceeds --budget %.4f (~%d input + %d output tokens), refusing to run",
+			projection.Cost, budget, projection.InputTokens, projection.OutputTokens)
+	}
+	cmd.PrintErrf("Projected cost %.4f is within budget %.4f\n", projection.Cost, budget)
+	return &projection, nil
+}
+
+// projectCost approximates the cost of a full run from prompt lengths,
+// assuming every response uses its max tokens. Models without pricing
+// are reported and counted as free.
+func projectCost(cmd *cobra.Command, executor *exec.Executor, cfg *config.Config) (exec.CostProjection, error) {
+	estimates, err := executor.ApproxEstimate()
+	if err != nil {
+		return exec.CostProjection{}, err
+	}
+
+	var unpriced []string
+	for _, estimate := range estimates {
+		if cfg.Pricing(estimate.Model) == (config.Pricing{}) && !slices.Contains(unpriced, estimate.Model) {
+			unpriced = append(unpriced, estimate.Model)
+		}
+	}
+	if len(unpriced) > 0 {
+		cmd.PrintErrf("Warning: no pricing configured for %s, counted as free\n", strings.Join(unpriced, ", "))
+	}
+
+	return exec.ProjectCost(estimates, 0, cfg.Pricing), nil
+}
+
+// checkContextWindows reports requests that may exceed the configured
+// context window of their model, and fails on them if strict is set.
+// Plans without a model with a configured context window are not read.
+func checkContextWindows(cmd *cobra.Command, p *plan.Plan, assistantDir string, opts exec.Options, cfg *config.Config, strict bool) error {
+	if !slices.ContainsFunc(p.Assistant.LLM.Models, func(model string) bool { return cfg.ContextWindow(model) > 0 }) {
+		return nil
+	}
+
+	estimates, err := exec.New(p, assistantDir, nil, opts).ApproxEstimate()
+	if err != nil {
+		return err
+	}
+	overflows := exec.CheckContext(estimates, cfg.ContextWindow)
+	if len(overflows) == 0 {
+		return nil
+	}
+
+	for _, o := range overflows {
+		cmd.PrintErrf("Warning: %s -> %s needs ~%d tokens (input + max tokens), context window is %d\n",
+			o.QueryID, o.Model, o.Tokens, o.Window)
+	}
+	if strict {
+		return fmt.Errorf("%d requests may exceed the context window, refusing to run (--strict-context)", len(overflows))
+	}
+	return nil
+}
+
+// checkConfig loads the configuration, resolves every provider token
+// and header, and routes every plan model, without making API calls.
+func checkConfig(cmd *cobra.Command, p *plan.Plan) error {
+	cmd.Println("Configuration check:")
+
+	cfgResult, err := config.Load()
+	if err != nil {
+		cmd.Printf("  x %v\n", err)
+		return fmt.Errorf("configuration check failed")
+	}
+	cmd.Printf("  Source: %s\n", cfgResult.Source)
+
+	var problems int
+	for _, provider := range cfgResult.Config.Providers {
+		if _, err := provider.ResolveAPIToken(); err != nil {
+			cmd.Printf("  x provider %s: %v\n", provider.Name, err)
+			problems++
+		}
+		if _, err := provider.ResolveHeaders(); err != nil {
+			cmd.Printf("  x provider %s: %v\n", provider.Name, err)
+			problems++
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("configuration check failed: %d problems", problems)
+	}
+
+	router, err := llm.NewRouter(cfgResult.Config)
+	if err != nil {
+		cmd.Printf("  x %v\n", err)
+		return fmt.Errorf("configuration check failed")
+	}
+
+	listed := make(map[string]bool)
+	for _, provider := range cfgResult.Config.Providers {
+		for _, m := range provider.Models {
+			listed[m] = true
+		}
+	}
+	for _, model := range p.Assistant.LLM.Models {
+		fullName, provider := router.ResolveModel(model)
+		note := ""
+		if !listed[fullName] {
+			note = " (default provider)"
+		}
+		cmd.Printf("  + %s -> %s%s\n", model, provider, note)
+	}
+
+	cmd.Println("  Configuration OK")
+	return nil
+}
+
+// executeCompactJSON runs the plan silently and prints a single-line JSON report.
+func executeCompactJSON(cmd *cobra.Command, p *plan.Plan, assistantDir string, router llm.ChatClient, planID string, opts exec.Options, events *exec.EventLog) error {
+	opts.OnProgress = events.Wrap(nil)
+	executor := exec.New(p, assistantDir, router, opts)
+
+	start := time.Now()
+	summary, err := executor.Execute(context.Background())
+	if events != nil {
+		events.RunEnd(summary, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(exec.NewRunReport(planID, summary, time.Since(start)))
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	cmd.Println(string(data))
+	return nil
+}
+
+// progressLogLine is the --log-json representation of a progress event.
+type progressLogLine struct {
+	Time         time.Time `json:"time"`
+	Type         string    `json:"type"`
+	Model        string    `json:"model"`
+	Provider     string    `json:"provider,omitempty"`
+	QueryID      string    `json:"query_id"`
+	PromptTokens int       `json:"prompt_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	DurationMS   int64     `json:"duration_ms,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// newProgressLogLine maps a progress event to its --log-json representation.
+func newProgressLogLine(event exec.ProgressEvent) progressLogLine {
+	line := progressLogLine{
+		Time:         time.Now(),
+		Type:         event.Type.String(),
+		Model:        event.Model,
+		Provider:     event.Provider,
+		QueryID:      event.QueryID,
+		PromptTokens: event.Tokens.Prompt,
+		OutputTokens: event.Tokens.Output,
+		DurationMS:   event.Duration.Milliseconds(),
+	}
+	if event.Err != nil {
+		line.Error = event.Err.Error()
+	}
+	return line
+}
+
+// writeProgressLog writes a progress event as a single JSON line.
+// The executor reports events one at a time, so lines never interleave.
+func writeProgressLog(w io.Writer, event exec.ProgressEvent) {
+	data, err := json.Marshal(newProgressLogLine(event))
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// confirmRequests asks question before sending total requests if that
+// is above threshold (confirm_above). If the session is not interactive,
+// it fails instead, pointing to --yes.
+func confirmRequests(cmd *cobra.Command, total, threshold int, interactive bool, question string) error {
+	if total <= threshold {
+		return nil
+	}
+	if !interactive {
+		return fmt.Errorf("plan would send %d requests (above confirm_above=%d), rerun with --yes to proceed",
+			total, threshold)
+	}
+	ok, err := confirm(cmd, question)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("execution cancelled")
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on the command input; only "y" or "yes" confirm.
+func confirm(cmd *cobra.Command, question string) (bool, error) {
+	cmd.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// printRaw prints a provider response body as indented JSON,
+// falling back to the body as is if it is not valid JSON.
+func printRaw(cmd *cobra.Command, raw []byte) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "    ", "  "); err != nil {
+		cmd.Printf("    %s\n", raw)
+		return
+	}
+	cmd.Printf("    %s\n", buf.String())
+}
+
+// modelAliases returns the configured model aliases. Without any
+// configuration, e.g. for a dry run, there are none.
+func modelAliases() (map[string]string, error) {
+	result, err := loadOptionalConfig()
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return result.Config.Aliases, nil
+}
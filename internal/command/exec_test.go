@@ -0,0 +1,417 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+// testAssistant creates an assistant directory with the given Input files.
+func testAssistant(t *testing.T, inputs map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	for name, content := range inputs {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+// testCommand returns a command writing its output to out and errors to errOut.
+func testCommand(out, errOut *bytes.Buffer) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	return cmd
+}
+
+func TestCheckContextWindows(t *testing.T) {
+	// 360 characters approximate 90 input tokens; with 10 max tokens a
+	// request needs ~100 tokens, right at the edge of the windows below.
+	assistantDir := testAssistant(t, map[string]string{"long.md": strings.Repeat("x", 360)})
+	p := &plan.Plan{
+		PlanID: "plan",
+		Assistant: plan.Assistant{
+			LLM: plan.LLM{Models: []string{"small"}, MaxTokens: 10},
+		},
+		Queries: []plan.Query{{ID: "long.md"}},
+	}
+	cfgWithWindow := func(window int) *config.Config {
+		return &config.Config{
+			DefaultProvider: "local",
+			Providers: []config.Provider{{
+				Name:           "local",
+				Models:         []string{"small"},
+				ContextWindows: map[string]int{"small": window},
+			}},
+		}
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		err := checkContextWindows(testCommand(&out, &errOut), p, assistantDir, exec.Options{}, cfgWithWindow(100), true)
+		require.NoError(t, err)
+		assert.Empty(t, errOut.String())
+	})
+
+	t.Run("warns", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		err := checkContextWindows(testCommand(&out, &errOut), p, assistantDir, exec.Options{}, cfgWithWindow(99), false)
+		require.NoError(t, err)
+		assert.Contains(t, errOut.String(), "long.md -> small needs ~100 tokens")
+		assert.Contains(t, errOut.String(), "context window is 99")
+	})
+
+	t.Run("refuses with strict", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		err := checkContextWindows(testCommand(&out, &errOut), p, assistantDir, exec.Options{}, cfgWithWindow(99), true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--strict-context")
+	})
+
+	t.Run("skipped without windows", func(t *testing.T) {
+		broken := *p
+		broken.Queries = []plan.Query{{ID: "missing.md"}}
+		cfg := &config.Config{
+			DefaultProvider: "local",
+			Providers:       []config.Provider{{Name: "local", Models: []string{"small"}}},
+		}
+
+		var out, errOut bytes.Buffer
+		err := checkContextWindows(testCommand(&out, &errOut), &broken, assistantDir, exec.Options{}, cfg, true)
+		require.NoError(t, err)
+		assert.Empty(t, errOut.String())
+	})
+
+	t.Run("unreadable queries are left to exec", func(t *testing.T) {
+		broken := *p
+		broken.Queries = []plan.Query{{ID: "missing.md"}, {ID: "long.md"}}
+
+		var out, errOut bytes.Buffer
+		err := checkContextWindows(testCommand(&out, &errOut), &broken, assistantDir, exec.Options{}, cfgWithWindow(100), true)
+		require.NoError(t, err)
+	})
+}
+
+func TestConfirmRequests(t *testing.T) {
+	tests := map[string]struct {
+		total       int
+		interactive bool
+		answer      string
+		err         string
+	}{
+		"at threshold":             {total: 10, answer: ""},
+		"above, non-interactive":   {total: 11, err: "plan would send 11 requests (above confirm_above=10), rerun with --yes to proceed"},
+		"above, confirmed":         {total: 11, interactive: true, answer: "y\n"},
+		"above, declined":          {total: 11, interactive: true, answer: "n\n", err: "execution cancelled"},
+		"above, no answer (EOF)":   {total: 11, interactive: true, answer: "", err: "execution cancelled"},
+		"above, confirmed in full": {total: 11, interactive: true, answer: "Yes\n"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			cmd := testCommand(&out, &errOut)
+			cmd.SetIn(strings.NewReader(test.answer))
+
+			err := confirmRequests(cmd, test.total, 10, test.interactive, "Continue?")
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.interactive && test.total > 10, strings.Contains(out.String(), "Continue? [y/N]"))
+		})
+	}
+}
+
+func TestProjectCost(t *testing.T) {
+	assistantDir := testAssistant(t, map[string]string{"q.md": strings.Repeat("x", 40)})
+	p := &plan.Plan{
+		PlanID: "plan",
+		Assistant: plan.Assistant{
+			SystemPrompt: strings.Repeat("s", 20),
+			LLM:          plan.LLM{Models: []string{"priced", "free"}, MaxTokens: 50},
+		},
+		Queries: []plan.Query{{ID: "q.md"}},
+	}
+	cfg := &config.Config{
+		DefaultProvider: "local",
+		Providers: []config.Provider{
+			{Name: "paid", Models: []string{"priced"}, InputCostPer1K: 1, OutputCostPer1K: 2},
+			{Name: "local", Models: []string{"free"}},
+		},
+	}
+
+	var out, errOut bytes.Buffer
+	projection, err := projectCost(testCommand(&out, &errOut), exec.New(p, assistantDir, nil, exec.Options{}), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 2, projection.Tasks)
+	assert.InDelta(t, 15*0.001+50*0.002, projection.Cost, 1e-9)
+	assert.Equal(t, "Warning: no pricing configured for free, counted as free\n", errOut.String())
+}
+
+func TestCheckBudget(t *testing.T) {
+	assistantDir := testAssistant(t, map[string]string{"q.md": strings.Repeat("x", 40)})
+	p := &plan.Plan{
+		PlanID: "plan",
+		Assistant: plan.Assistant{
+			SystemPrompt: strings.Repeat("s", 20),
+			LLM:          plan.LLM{Models: []string{"priced"}, MaxTokens: 50},
+		},
+		Queries: []plan.Query{{ID: "q.md"}},
+	}
+	cfg := &config.Config{
+		DefaultProvider: "paid",
+		Providers: []config.Provider{
+			{Name: "paid", Models: []string{"priced"}, InputCostPer1K: 1, OutputCostPer1K: 2},
+		},
+	}
+	executor := exec.New(p, assistantDir, nil, exec.Options{})
+
+	t.Run("under budget", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		projection, err := checkBudget(testCommand(&out, &errOut), executor, cfg, 1)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.115, projection.Cost, 1e-9)
+		assert.Equal(t, "Projected cost 0.1150 is within budget 1.0000\n", errOut.String())
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		projection, err := checkBudget(testCommand(&out, &errOut), executor, cfg, 0.1)
+		assert.EqualError(t, err, "projected cost 0.1150 exceeds --budget 0.1000 (~15 input + 50 output tokens), refusing to run")
+		assert.Nil(t, projection)
+	})
+}
+
+func TestWatchConfig(t *testing.T) {
+	var out, errOut bytes.Buffer
+	cmd := testCommand(&out, &errOut)
+
+	_, _, err := watchConfig(cmd, &config.LoadResult{Source: "environment"}, nil)
+	assert.EqualError(t, err, "--watch-config requires a configuration file")
+
+	path := filepath.Join(t.TempDir(), ".tuna.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`default_provider = "local"
+
+[[providers]]
+name = "local"
+base_url = "http://localhost:1/v1"
+api_token = "token"
+models = ["m"]
+`), 0644))
+	router, stop, err := watchConfig(cmd, &config.LoadResult{Source: path}, nil)
+	require.NoError(t, err)
+	defer stop()
+	_, provider := router.ResolveModel("m")
+	assert.Equal(t, "local", provider)
+}
+
+func TestExecuteCompactJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if strings.Contains(body.Messages[len(body.Messages)-1].Content, "fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"bad request"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":7,"completion_tokens":3}}`)
+	}))
+	t.Cleanup(server.Close)
+	router, err := llm.NewRouter(&config.Config{
+		Providers: []config.Provider{{Name: "local", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}}},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		inputs     map[string]string
+		wantStatus string
+		wantErrors float64
+	}{
+		"ok": {
+			inputs:     map[string]string{"q1.md": "Hi", "q2.md": "Hello"},
+			wantStatus: exec.ReportStatusOK,
+		},
+		"errors": {
+			inputs:     map[string]string{"q1.md": "Hi", "q2.md": "Please fail"},
+			wantStatus: exec.ReportStatusErrors,
+			wantErrors: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assistantDir := testAssistant(t, tc.inputs)
+			p := &plan.Plan{
+				PlanID:    "plan",
+				Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"m"}, MaxTokens: 10}},
+				Queries:   []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+			}
+
+			var out, errOut bytes.Buffer
+			require.NoError(t, executeCompactJSON(testCommand(&out, &errOut), p, assistantDir, router, "plan", exec.Options{}, nil))
+
+			line, rest, _ := strings.Cut(out.String(), "\n")
+			assert.Empty(t, rest, "a single line")
+			var report map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &report))
+			for _, field := range []string{"plan_id", "status", "queries", "models", "results", "errors", "prompt_tokens", "output_tokens", "duration_ms"} {
+				assert.Contains(t, report, field)
+			}
+			assert.Equal(t, "plan", report["plan_id"])
+			assert.Equal(t, tc.wantStatus, report["status"])
+			assert.Equal(t, tc.wantErrors, report["errors"])
+			assert.Equal(t, 2-tc.wantErrors, report["results"])
+			assert.Equal(t, []any{map[string]any{
+				"provider":      "local",
+				"results":       2 - tc.wantErrors,
+				"errors":        tc.wantErrors,
+				"prompt_tokens": 7 * (2 - tc.wantErrors),
+				"output_tokens": 3 * (2 - tc.wantErrors),
+			}}, report["providers"])
+		})
+	}
+}
+
+func TestExecuteNonInteractive_Raw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		raw        bool
+		wantStdout bool // Progress lines on stdout
+	}{
+		"plain": {wantStdout: true},
+		"raw":   {raw: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var routerOpts []llm.RouterOption
+			opts := exec.Options{}
+			if tc.raw {
+				routerOpts = append(routerOpts, llm.WithRawResponses())
+				opts.Store = exec.DiscardStore{}
+			}
+			router, err := llm.NewRouter(&config.Config{
+				Providers: []config.Provider{{Name: "local", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}}},
+			}, routerOpts...)
+			require.NoError(t, err)
+			assistantDir := testAssistant(t, map[string]string{"q1.md": "Hi"})
+			p := &plan.Plan{
+				PlanID:    "plan",
+				Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"m"}, MaxTokens: 10}},
+				Queries:   []plan.Query{{ID: "q1.md"}},
+			}
+
+			var out, errOut bytes.Buffer
+			require.NoError(t, executeNonInteractive(testCommand(&out, &errOut), p, assistantDir, router, "plan", opts, nil, false, tc.raw))
+
+			progress := "Processing q1.md with m..."
+			if tc.wantStdout {
+				assert.Contains(t, out.String(), progress)
+				assert.Empty(t, errOut.String())
+				return
+			}
+			assert.NotContains(t, out.String(), progress, "stdout holds only the report")
+			assert.Contains(t, errOut.String(), progress)
+			assert.Contains(t, out.String(), `"finish_reason": "stop"`, "the raw response is printed")
+		})
+	}
+}
+
+func TestExec_RawCompactJSON(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+	outputDir := filepath.Join(dir, "Helper", "Output", "plan-id")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(filepath.Join(outputDir, "plan.toml"), &plan.Plan{
+		PlanID:      "plan-id",
+		AssistantID: "Helper",
+		Assistant:   plan.Assistant{LLM: plan.LLM{Models: []string{"m"}}},
+		Queries:     []plan.Query{{ID: "q1.md"}},
+	}))
+
+	var out, errOut bytes.Buffer
+	cmd := Exec()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{"plan-id", "--raw", "--compact-json"})
+	assert.EqualError(t, cmd.Execute(), "--raw cannot be combined with --compact-json")
+}
+
+func TestCheckConfig(t *testing.T) {
+	const cfg = `default_provider = "openai"
+
+[[providers]]
+name = "openai"
+base_url = "http://localhost:1/v1"
+api_token_env = "TUNA_TEST_CHECK_TOKEN"
+models = ["gpt-4o"]
+`
+	p := &plan.Plan{Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}}}}
+
+	tests := map[string]struct {
+		token   string
+		want    []string
+		wantErr string
+	}{
+		"ok": {
+			token: "secret",
+			want:  []string{"  + gpt-4o -> openai\n", "  + o1 -> openai (default provider)\n", "Configuration OK"},
+		},
+		"missing token": {
+			want:    []string{`x provider openai: environment variable "TUNA_TEST_CHECK_TOKEN" is not set`},
+			wantErr: "configuration check failed: 1 problems",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("HOME", dir)
+			t.Chdir(dir)
+			t.Setenv("TUNA_TEST_CHECK_TOKEN", tc.token)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ".tuna.toml"), []byte(cfg), 0644))
+
+			var out, errOut bytes.Buffer
+			err := checkConfig(testCommand(&out, &errOut), p)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				assert.NotContains(t, out.String(), "Configuration OK")
+			} else {
+				require.NoError(t, err)
+			}
+			for _, want := range tc.want {
+				assert.Contains(t, out.String(), want)
+			}
+		})
+	}
+}
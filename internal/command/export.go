@@ -0,0 +1,171 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/view"
+)
+
+// Export formats.
+const (
+	exportFormatJSON     = "json"
+	exportFormatMarkdown = "md"
+)
+
+// Export returns a cobra.Command to export a plan's responses.
+//
+//	$ tuna export <PlanID> [--format json|md] [--output file]
+func Export() *cobra.Command {
+	var (
+		format string
+		output string
+	)
+
+	command := cobra.Command{
+		Use:   "export <PlanID>",
+		Short: "Export a plan's responses as JSON or Markdown",
+		Long: `Export writes all responses of a plan, with their ratings and
+execution metadata, for consumers outside the terminal.
+
+Formats:
+  json  A single JSON document with queries, responses and metadata
+  md    A Markdown report with a section per query
+
+Output goes to stdout unless --output is given.
+
+Use 'tuna export dataset' to turn ratings into preference pairs.
+
+Examples:
+  tuna export 01JG... --format json --output results.json
+  tuna export 01JG... --format md > report.md`,
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePlanID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			planID := args[0]
+
+			if format != exportFormatJSON && format != exportFormatMarkdown {
+				return fmt.Errorf("unknown format %q: expected %s or %s", format, exportFormatJSON, exportFormatMarkdown)
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			p, planPath, err := plan.Load(cwd, planID)
+			if err != nil {
+				return err
+			}
+			planID = p.PlanID // The plan may be referenced by name
+
+			groups, err := view.LoadResponses(planPath)
+			if err != nil {
+				return fmt.Errorf("failed to load responses: %w", err)
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			if format == exportFormatJSON {
+				err = view.ExportJSON(w, planID, groups)
+			} else {
+				err = view.ExportMarkdown(w, planID, groups)
+			}
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				cmd.PrintErrf("Exported %d queries to %s\n", len(groups), output)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&format, "format", "f", exportFormatJSON, "Export format: json or md")
+	command.Flags().StringVarP(&output, "output", "o", "", "Write to a file instead of stdout")
+
+	command.AddCommand(exportDataset())
+
+	return &command
+}
+
+// exportDataset returns a cobra.Command to export rated responses as
+// preference pairs.
+//
+//	$ tuna export dataset <PlanID> [--output file]
+func exportDataset() *cobra.Command {
+	var output string
+
+	command := cobra.Command{
+		Use:   "dataset <PlanID>",
+		Short: "Export ratings as a JSONL preference dataset",
+		Long: `Dataset writes a JSON Lines file of preference pairs for fine-tuning
+or DPO. Each line holds the plan's system prompt, the query, a response
+rated good ("chosen") and a response rated bad ("rejected") for that
+query:
+
+  {"system": "...", "user": "...", "chosen": "...", "rejected": "..."}
+
+Every good response of a query is paired with every bad one. Queries
+without both a good and a bad rating are skipped.
+
+Output goes to stdout unless --output is given.`,
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePlanID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			p, planPath, err := plan.Load(cwd, args[0])
+			if err != nil {
+				return err
+			}
+
+			groups, err := view.LoadResponses(planPath)
+			if err != nil {
+				return fmt.Errorf("failed to load responses: %w", err)
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			n, err := view.ExportDataset(w, p.Assistant.SystemPrompt, groups)
+			if err != nil {
+				return err
+			}
+
+			if output != "" || n == 0 {
+				cmd.PrintErrf("Exported %d pairs\n", n)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&output, "output", "o", "", "Write to a file instead of stdout")
+
+	return &command
+}
@@ -0,0 +1,120 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/view"
+)
+
+// testRatedPlan creates a plan in the working directory with one query
+// answered by two models, one rated good and one rated bad.
+func testRatedPlan(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	outputDir := filepath.Join(dir, "Helper", "Output", "plan-id")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(filepath.Join(outputDir, "plan.toml"), &plan.Plan{
+		PlanID:      "plan-id",
+		AssistantID: "Helper",
+		Assistant:   plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}}},
+		Queries:     []plan.Query{{ID: "q1.md"}},
+	}))
+	inputDir := filepath.Join(dir, "Helper", "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "q1.md"), []byte("What is Go?"), 0644))
+
+	responses := map[string]string{
+		"gpt-4o": "---\nmodel: gpt-4o\nexecuted_at: 2026-01-02T03:04:05Z\nrating: good\n---\n\nA language.\n",
+		"o1":     "---\nmodel: o1\nexecuted_at: 2026-01-02T03:04:05Z\nrating: bad\n---\n\nA gopher.\n",
+	}
+	for model, content := range responses {
+		modelDir := filepath.Join(outputDir, exec.ModelHash(model))
+		require.NoError(t, os.MkdirAll(modelDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(modelDir, "q1_response.md"), []byte(content), 0644))
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		check  func(t *testing.T, data []byte)
+	}{
+		"json": {
+			format: "json",
+			check: func(t *testing.T, data []byte) {
+				var exported view.ExportedPlan
+				require.NoError(t, json.Unmarshal(data, &exported))
+				assert.Equal(t, "plan-id", exported.PlanID)
+				require.Len(t, exported.Queries, 1)
+				assert.Equal(t, "What is Go?", exported.Queries[0].Input)
+
+				got := make(map[string]view.ExportedResponse)
+				for _, resp := range exported.Queries[0].Responses {
+					got[resp.Model] = resp
+				}
+				assert.Equal(t, view.RatingGood, got["gpt-4o"].Rating)
+				assert.Equal(t, "A language.\n", got["gpt-4o"].Content)
+				assert.Equal(t, view.RatingBad, got["o1"].Rating)
+				assert.Equal(t, "A gopher.\n", got["o1"].Content)
+				assert.NotNil(t, got["gpt-4o"].Metadata)
+			},
+		},
+		"md": {
+			format: "md",
+			check: func(t *testing.T, data []byte) {
+				report := string(data)
+				assert.Contains(t, report, "# Plan plan-id\n")
+				assert.Contains(t, report, "## q1.md\n\n> What is Go?\n")
+				assert.Contains(t, report, "### gpt-4o (good)\n\nA language.\n")
+				assert.Contains(t, report, "### o1 (bad)\n\nA gopher.\n")
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testRatedPlan(t)
+
+			var out, errOut bytes.Buffer
+			cmd := Export()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs([]string{"plan-id", "--format", tc.format})
+			require.NoError(t, cmd.Execute())
+			tc.check(t, out.Bytes())
+
+			out.Reset()
+			cmd = Export()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs([]string{"plan-id", "--format", tc.format, "--output", "report"})
+			require.NoError(t, cmd.Execute())
+			assert.Empty(t, out.String(), "nothing is written to stdout with --output")
+			data, err := os.ReadFile("report")
+			require.NoError(t, err)
+			tc.check(t, data)
+		})
+	}
+}
+
+func TestExport_UnknownFormat(t *testing.T) {
+	testRatedPlan(t)
+
+	var out, errOut bytes.Buffer
+	cmd := Export()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{"plan-id", "--format", "csv"})
+	assert.EqualError(t, cmd.Execute(), `unknown format "csv": expected json or md`)
+}
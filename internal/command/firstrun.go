@@ -0,0 +1,64 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+// firstRunExample is a minimal configuration suggested on first run.
+const firstRunExample = `  default_provider = "openai"
+
+  [[providers]]
+  name = "openai"
+  base_url = "https://api.openai.com/v1"
+  api_token_env = "OPENAI_API_KEY"
+  models = ["gpt-4o", "gpt-4o-mini"]`
+
+// firstRunError adds setup instructions to an error wrapping
+// config.ErrNoConfig, returned when neither a configuration file nor
+// environment variables exist.
+type firstRunError struct {
+	err error
+}
+
+// Error implements error. The original message is kept, so errors
+// such as "--pick requires a configuration" still tell what failed.
+func (e *firstRunError) Error() string {
+	return fmt.Sprintf(`%s
+
+It looks like this is the first time tuna runs here. Create %s in
+your project directory (or ~/%s for all projects), e.g.:
+
+%s
+
+then set the token variable and check the setup with 'tuna config validate'.
+Supported formats are TOML, YAML and JSON, see 'tuna config --help'.`,
+		e.err, config.ConfigFileName, config.GlobalConfigPath, firstRunExample)
+}
+
+// Unwrap returns the original error.
+func (e *firstRunError) Unwrap() error {
+	return e.err
+}
+
+// withFirstRunGuidance wraps the RunE of cmd and all its subcommands so
+// that a missing configuration is reported with setup instructions.
+// The instructions are plain text, safe for non-interactive use.
+func withFirstRunGuidance(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			err := run(cmd, args)
+			if errors.Is(err, config.ErrNoConfig) {
+				return &firstRunError{err: err}
+			}
+			return err
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		withFirstRunGuidance(sub)
+	}
+}
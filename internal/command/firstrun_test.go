@@ -0,0 +1,91 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+func TestWithFirstRunGuidance(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv(config.EnvAPIToken, "")
+	t.Setenv(config.EnvBaseURL, "")
+	t.Chdir(dir)
+
+	run := func(t *testing.T, args ...string) error {
+		t.Helper()
+		root := &cobra.Command{Use: "tuna"}
+		root.AddCommand(&cobra.Command{
+			Use: "load",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				_, err := config.Load()
+				return err
+			},
+		})
+		root.AddCommand(&cobra.Command{
+			Use: "pick",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if _, err := config.Load(); err != nil {
+					return fmt.Errorf("--pick requires a configuration: %w", config.ErrNoConfig)
+				}
+				return nil
+			},
+		})
+		withFirstRunGuidance(root)
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		if len(args) == 0 {
+			args = []string{"load"}
+		}
+		root.SetArgs(args)
+		return root.Execute()
+	}
+
+	t.Run("no configuration", func(t *testing.T) {
+		tests := map[string]struct {
+			command    string
+			wantPrefix string
+		}{
+			"load": {command: "load", wantPrefix: "no configuration found"},
+			"pick": {command: "pick", wantPrefix: "--pick requires a configuration: no configuration found\n\n"},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				err := run(t, tc.command)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, config.ErrNoConfig)
+				assert.True(t, strings.HasPrefix(err.Error(), tc.wantPrefix), "the original message is kept: %s", err)
+				assert.Contains(t, err.Error(), "first time tuna runs here")
+				assert.Contains(t, err.Error(), "tuna config validate")
+			})
+		}
+	})
+
+	t.Run("configuration present", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.ConfigFileName), []byte(`default_provider = "openai"
+
+[[providers]]
+name = "openai"
+base_url = "https://api.openai.com/v1"
+api_token = "token"
+`), 0644))
+		assert.NoError(t, run(t))
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.ConfigFileName), []byte("default_provider = \n"), 0644))
+		err := run(t)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "first time tuna runs here")
+	})
+}
@@ -12,10 +12,10 @@ import (
 
 // Init returns a cobra.Command to initialize project structure for a new assistant.
 //
-//	$ tuna init <AssistantID>
+//	$ tuna init [AssistantID]
 func Init() *cobra.Command {
 	command := cobra.Command{
-		Use:   "init <AssistantID>",
+		Use:   "init [AssistantID]",
 		Short: "Initialize project structure for a new assistant",
 		Long: `Initialize creates the directory structure for a new assistant:
 
@@ -28,11 +28,16 @@ func Init() *cobra.Command {
       └── fragment_001.md
 
 If the directory already exists, missing parts will be completed.
-Existing files will not be overwritten.`,
+Existing files will not be overwritten.
 
-		Args: cobra.ExactArgs(1),
+If AssistantID is omitted, default_assistant from the configuration is used.`,
+
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			assistantID := args[0]
+			assistantID, err := resolveAssistantID(args)
+			if err != nil {
+				return err
+			}
 
 			// Get current working directory
 			cwd, err := os.Getwd()
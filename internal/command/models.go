@@ -0,0 +1,204 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/tui"
+)
+
+// Models returns a cobra.Command to list available models.
+//
+//	$ tuna models [provider] [--remote] [--json]
+func Models() *cobra.Command {
+	var (
+		remote     bool
+		asJSON     bool
+		onlyByName string
+	)
+
+	command := cobra.Command{
+		Use:   "models [provider]",
+		Short: "List available models",
+		Long: `List models configured for each provider, along with the aliases
+that resolve to them. Aliases of models not listed by any provider are
+shown under the default provider, which serves them.
+
+With --remote, the model catalog is fetched from the provider's
+/models endpoint instead, which helps to populate the models list
+in the configuration. The default provider is used if none is given.
+
+Examples:
+  tuna models
+  tuna models --provider openrouter --json
+  tuna models --remote
+  tuna models --remote openrouter`,
+
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgResult, err := config.Load()
+			if err != nil {
+				return err
+			}
+			cfg := cfgResult.Config
+
+			var provider string
+			if len(args) > 0 {
+				provider = args[0]
+			}
+			if onlyByName != "" {
+				provider = onlyByName
+			}
+
+			if !remote {
+				groups, err := configuredModels(cfg, provider)
+				if err != nil {
+					return err
+				}
+				if asJSON {
+					data, err := json.MarshalIndent(groups, "", "  ")
+					if err != nil {
+						return fmt.Errorf("failed to encode models: %w", err)
+					}
+					cmd.Println(string(data))
+					return nil
+				}
+				printConfiguredModels(cmd, groups)
+				return nil
+			}
+
+			router, err := llm.NewRouter(cfg)
+			if err != nil {
+				return err
+			}
+			if provider == "" {
+				provider = router.DefaultProvider()
+			}
+
+			var ids []string
+			err = tui.RunWithSpinner(fmt.Sprintf("Fetching models from %s", provider), func() error {
+				var listErr error
+				ids, listErr = router.ListModels(cmd.Context(), provider)
+				return listErr
+			})
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(ids, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode models: %w", err)
+				}
+				cmd.Println(string(data))
+				return nil
+			}
+			if len(ids) == 0 {
+				cmd.Printf("Provider %s returned no models.\n", provider)
+				return nil
+			}
+			cmd.Println(strings.Join(ids, "\n"))
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&remote, "remote", false, "Fetch the live model catalog from the provider")
+	command.Flags().StringVar(&onlyByName, "provider", "", "Show only this provider")
+	command.Flags().BoolVar(&asJSON, "json", false, "Output as JSON")
+
+	return &command
+}
+
+// providerModels lists the models and aliases served by a provider.
+type providerModels struct {
+	Provider string       `json:"provider"`
+	Default  bool         `json:"default,omitempty"`
+	Models   []string     `json:"models"`
+	Aliases  []modelAlias `json:"aliases,omitempty"`
+}
+
+// modelAlias is an alias and the model it resolves to.
+type modelAlias struct {
+	Alias string `json:"alias"`
+	Model string `json:"model"`
+	// ViaDefault is set when no provider lists the model,
+	// so it is sent to the default provider.
+	ViaDefault bool `json:"via_default,omitempty"`
+}
+
+// configuredModels groups the configured models and aliases by the
+// provider serving them, in configuration order. A non-empty provider
+// restricts the result to that provider.
+func configuredModels(cfg *config.Config, provider string) ([]providerModels, error) {
+	var groups []providerModels
+	index := make(map[string]int)
+	for _, p := range cfg.Providers {
+		if provider != "" && p.Name != provider {
+			continue
+		}
+		index[p.Name] = len(groups)
+		groups = append(groups, providerModels{
+			Provider: p.Name,
+			Default:  p.Name == cfg.DefaultProvider,
+			Models:   append([]string{}, p.Models...),
+		})
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("provider %q not found", provider)
+	}
+
+	aliases := make([]string, 0, len(cfg.Aliases))
+	for alias := range cfg.Aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		fullName, p := cfg.ResolveModel(alias)
+		if p == nil {
+			continue
+		}
+		i, ok := index[p.Name]
+		if !ok {
+			continue
+		}
+		groups[i].Aliases = append(groups[i].Aliases, modelAlias{
+			Alias:      alias,
+			Model:      fullName,
+			ViaDefault: !slices.Contains(p.Models, fullName),
+		})
+	}
+
+	return groups, nil
+}
+
+// printConfiguredModels prints models and aliases grouped by provider.
+func printConfiguredModels(cmd *cobra.Command, groups []providerModels) {
+	for _, group := range groups {
+		if group.Default {
+			cmd.Printf("%s (default):\n", group.Provider)
+		} else {
+			cmd.Printf("%s:\n", group.Provider)
+		}
+		if len(group.Models) == 0 && len(group.Aliases) == 0 {
+			cmd.Println("  (no models configured)")
+		}
+		for _, m := range group.Models {
+			cmd.Printf("  %s\n", m)
+		}
+		for _, a := range group.Aliases {
+			line := fmt.Sprintf("  %s -> %s", a.Alias, a.Model)
+			if a.ViaDefault {
+				line += " (via default provider)"
+			}
+			cmd.Println(line)
+		}
+	}
+}
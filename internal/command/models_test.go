@@ -0,0 +1,207 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+func TestModels_Remote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"object":"list","data":[{"id":"o1"},{"id":"gpt-4o"}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+	content := fmt.Sprintf(`default_provider = "openai"
+
+[[providers]]
+name = "openai"
+base_url = %q
+api_token = "token"
+models = ["gpt-4o"]
+`, server.URL)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".tuna.toml"), []byte(content), 0o644))
+
+	run := func(t *testing.T, args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := Models()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	t.Run("text", func(t *testing.T) {
+		assert.Equal(t, "gpt-4o\no1\n", run(t, "--remote", "openai"))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var ids []string
+		require.NoError(t, json.Unmarshal([]byte(run(t, "--remote", "--json")), &ids))
+		assert.Equal(t, []string{"gpt-4o", "o1"}, ids, "the default provider is used")
+	})
+}
+
+func TestConfiguredModels(t *testing.T) {
+	cfg := &config.Config{
+		DefaultProvider: "openai",
+		Providers: []config.Provider{
+			{Name: "openai", Models: []string{"gpt-4o", "o1"}},
+			{Name: "anthropic", Models: []string{"claude-sonnet"}},
+			{Name: "openrouter", Models: []string{"gpt-4o", "meta/llama"}},
+		},
+		Aliases: map[string]string{
+			"4o":     "gpt-4o",
+			"fast":   "gpt-4o",
+			"sonnet": "claude-sonnet",
+			"llama":  "meta/llama",
+			"mini":   "gpt-4o-mini",
+		},
+	}
+
+	tests := map[string]struct {
+		provider string
+		expected []providerModels
+		err      string
+	}{
+		"all providers": {
+			expected: []providerModels{
+				{
+					Provider: "openai",
+					Default:  true,
+					Models:   []string{"gpt-4o", "o1"},
+					Aliases: []modelAlias{
+						{Alias: "4o", Model: "gpt-4o"},
+						{Alias: "fast", Model: "gpt-4o"},
+						{Alias: "mini", Model: "gpt-4o-mini", ViaDefault: true},
+					},
+				},
+				{
+					Provider: "anthropic",
+					Models:   []string{"claude-sonnet"},
+					Aliases:  []modelAlias{{Alias: "sonnet", Model: "claude-sonnet"}},
+				},
+				{
+					Provider: "openrouter",
+					Models:   []string{"gpt-4o", "meta/llama"},
+					Aliases:  []modelAlias{{Alias: "llama", Model: "meta/llama"}},
+				},
+			},
+		},
+		"overlapping model goes to the first provider": {
+			provider: "openrouter",
+			expected: []providerModels{{
+				Provider: "openrouter",
+				Models:   []string{"gpt-4o", "meta/llama"},
+				Aliases:  []modelAlias{{Alias: "llama", Model: "meta/llama"}},
+			}},
+		},
+		"unknown provider": {
+			provider: "mistral",
+			err:      `provider "mistral" not found`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			groups, err := configuredModels(cfg, tc.provider)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, groups)
+		})
+	}
+}
+
+func TestModels_Configured(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+	content := `default_provider = "openai"
+
+[aliases]
+fast = "gpt-4o"
+mini = "gpt-4o-mini"
+
+[[providers]]
+name = "openai"
+base_url = "https://api.openai.com/v1"
+api_token = "token"
+models = ["gpt-4o"]
+
+[[providers]]
+name = "local"
+base_url = "http://localhost:11434/v1"
+api_token = "token"
+models = []
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".tuna.toml"), []byte(content), 0o644))
+
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"text": {
+			expected: "openai (default):\n  gpt-4o\n  fast -> gpt-4o\n  mini -> gpt-4o-mini (via default provider)\n" +
+				"local:\n  (no models configured)\n",
+		},
+		"provider": {
+			args:     []string{"--provider", "local"},
+			expected: "local:\n  (no models configured)\n",
+		},
+		"json": {
+			args: []string{"--provider", "openai", "--json"},
+			expected: `[
+  {
+    "provider": "openai",
+    "default": true,
+    "models": [
+      "gpt-4o"
+    ],
+    "aliases": [
+      {
+        "alias": "fast",
+        "model": "gpt-4o"
+      },
+      {
+        "alias": "mini",
+        "model": "gpt-4o-mini",
+        "via_default": true
+      }
+    ]
+  }
+]
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := Models()
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tc.args)
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
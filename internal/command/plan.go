@@ -1,27 +1,51 @@
 package command
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/llm"
 	"go.octolab.org/toolset/tuna/internal/plan"
 	"go.octolab.org/toolset/tuna/internal/tui"
+	"go.octolab.org/toolset/tuna/internal/tui/pick"
 )
 
 // Plan returns a cobra.Command to create an execution plan.
 //
-//	$ tuna plan <AssistantID> [flags]
+//	$ tuna plan [AssistantID] [flags]
+//	$ tuna plan show <PlanID>
 func Plan() *cobra.Command {
 	var (
 		models      string
 		temperature float64
 		maxTokens   int
+		queryPrefix string
+		querySuffix string
+		noQueries   bool
+		queryFile   string
+		freeze      bool
+		variables   []string
+		inputGlobs  []string
+		variant     string
+		name        string
+		topP        float64
+		seed        int
+		strict      bool
+		overrides   []string
+		modelsFile  string
+		pickModels  bool
 	)
 
 	command := cobra.Command{
-		Use:   "plan <AssistantID>",
+		Use:   "plan [AssistantID]",
 		Short: "Create an execution plan",
 		Long: `Plan creates a TOML configuration file that defines an execution session.
 
@@ -30,12 +54,114 @@ The plan includes:
   - Compiled system prompt (from System prompt/ directory)
   - List of input queries (from Input/ directory)
   - Target models and execution parameters
+  - Optional query prefix/suffix wrapped around every query
 
-Output: <AssistantID>/Output/<plan_id>/plan.toml`,
+The query prefix and suffix are taken from --query-prefix and
+--query-suffix, or else from query_prefix and query_suffix of the
+[assistants.<AssistantID>] table of the configuration, or else from the
+top-level query_prefix and query_suffix.
 
-		Args: cobra.ExactArgs(1),
+With --query-file, queries are taken from a single multi-document file
+in Input/ instead: sections are split by "---" lines or, if there are
+none, by "## " headings, and named <file>#1, <file>#2, and so on.
+Their responses are named after the section, e.g. queries#2_response.md.
+
+Queries that would share a response file, like a.md and a.txt, are
+refused; rename one of the files.
+
+With --input-glob (repeatable), only Input/ files matching one of the
+patterns become queries, e.g. --input-glob 'topic_*.md'. Every pattern
+must match at least one file.
+
+Files matching a pattern in <AssistantID>/.tunaignore are left out,
+and --query-file refuses an ignored file. It takes gitignore-style
+patterns, one per line: globs like "draft_*.md" match file names,
+patterns with a slash like "Input/wip*" match paths from the assistant
+directory, "**" matches any number of directories as in "**/scratch*",
+a trailing slash like "drafts/" matches directories only, and
+"!pattern" re-includes files excluded by an earlier line.
+
+With --models-file, models are read from a file with one model per
+line; blank lines and lines starting with # are ignored. Models given
+with --models explicitly are added first, duplicates are dropped.
+
+With --pick, the models are chosen in an interactive list of the
+configured models and aliases, with the --models and --models-file
+models checked. Type / to filter, space to toggle, enter to confirm.
+Without a terminal, --pick is ignored.
+
+With --model-override model:temperature:max_tokens (repeatable), a model
+runs with its own temperature or max tokens instead of the plan defaults,
+e.g. --model-override o3:1: or --model-override gpt-4o::8000.
+
+With --var key=value (repeatable), {{.key}} placeholders in queries are
+replaced at exec time, so one query file can be rendered with different
+parameters. Write literal braces as {{"{{"}}.
+
+With --prompt-variant <name>, the system prompt is compiled from the
+System prompt/<name>/ subdirectory instead, and the plan records the
+variant, so different prompts can be compared on the same queries.
+
+The system_prompt_prefix from the configuration, if set, is prepended
+to the compiled system prompt unless the assistant is listed in
+system_prompt_prefix_skip.
+
+With --freeze, the query files are copied to Output/<plan_id>/inputs/ and
+exec reads them from there, so editing Input/ after planning does not
+change the results. The system prompt is always compiled into the plan.
+
+Output: <AssistantID>/Output/<plan_id>/plan.toml
+
+With --name, the plan is stored in Output/<slug>/ instead, where slug is
+the name lowercased with dashes, e.g. "My experiment" -> my-experiment.
+Other commands accept the plan ID, the name or the slug.
+
+With --strict, every model must resolve, after aliases, to a provider
+that lists it in the configuration; otherwise the plan is not created.
+Without it, unlisted models are sent to the default provider at exec time,
+and no configuration is needed to plan.
+
+If AssistantID is omitted, default_assistant from the configuration is used.
+
+Use 'tuna plan show <PlanID>' to inspect an existing plan.`,
+
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			assistantID := args[0]
+			assistantID, err := resolveAssistantID(args)
+			if err != nil {
+				return err
+			}
+
+			if noQueries && queryFile != "" {
+				return fmt.Errorf("--no-queries and --query-file are mutually exclusive")
+			}
+			if topP < 0 || topP > 1 {
+				return fmt.Errorf("--top-p must be between 0 and 1, got %g", topP)
+			}
+			if len(inputGlobs) > 0 && (noQueries || queryFile != "") {
+				return fmt.Errorf("--input-glob cannot be combined with --no-queries or --query-file")
+			}
+
+			vars, err := plan.ParseVariables(variables)
+			if err != nil {
+				return fmt.Errorf("--var: %w", err)
+			}
+			modelOverrides, err := plan.ParseModelOverrides(overrides)
+			if err != nil {
+				return fmt.Errorf("--model-override: %w", err)
+			}
+
+			modelList := plan.ParseModels(models)
+			if modelsFile != "" {
+				fileModels, err := plan.ReadModelsFile(modelsFile)
+				if err != nil {
+					return err
+				}
+				if !cmd.Flags().Changed("models") {
+					modelList = nil // The default model is not merged
+				}
+				modelList = plan.MergeModels(modelList, fileModels)
+			}
 
 			cwd, err := os.Getwd()
 			if err != nil {
@@ -43,9 +169,63 @@ Output: <AssistantID>/Output/<plan_id>/plan.toml`,
 			}
 
 			cfg := plan.Config{
-				Models:      plan.ParseModels(models),
+				Models:      modelList,
 				Temperature: temperature,
 				MaxTokens:   maxTokens,
+				QueryPrefix: queryPrefix,
+				QuerySuffix: querySuffix,
+				NoQueries:   noQueries,
+				QueryFile:   queryFile,
+				Freeze:      freeze,
+				Variables:   vars,
+				InputGlobs:  inputGlobs,
+
+				PromptVariant: variant,
+				Name:          name,
+				TopP:          topP,
+
+				ModelOverrides: modelOverrides,
+			}
+			if cmd.Flags().Changed("seed") {
+				cfg.Seed = &seed
+			}
+			cfgResult, err := loadOptionalConfig()
+			if err != nil {
+				return err
+			}
+			if cfgResult != nil {
+				id := filepath.Base(filepath.Clean(assistantID))
+				cfg.SystemPromptPrefix = cfgResult.Config.PromptPrefix(id)
+				cfg.Aliases = cfgResult.Config.Aliases
+
+				prefix, suffix := cfgResult.Config.QueryWrap(id)
+				if !cmd.Flags().Changed("query-prefix") {
+					cfg.QueryPrefix = prefix
+				}
+				if !cmd.Flags().Changed("query-suffix") {
+					cfg.QuerySuffix = suffix
+				}
+			}
+			if pickModels && tui.IsInteractive() {
+				if cfgResult == nil {
+					return fmt.Errorf("--pick requires a configuration: %w", config.ErrNoConfig)
+				}
+				preselected := cfg.Models
+				if !cmd.Flags().Changed("models") && modelsFile == "" {
+					preselected = nil // The default model is not a choice
+				}
+				cfg.Models, err = runModelPicker(cmd, cfgResult.Config, preselected)
+				if err != nil {
+					return err
+				}
+			}
+			if strict {
+				if cfgResult == nil {
+					return fmt.Errorf("--strict requires a configuration: %w", config.ErrNoConfig)
+				}
+				if err := checkModels(cfgResult.Config, cfg.Models); err != nil {
+					return err
+				}
 			}
 
 			var result *plan.Result
@@ -67,7 +247,7 @@ Output: <AssistantID>/Output/<plan_id>/plan.toml`,
 				cmd.Println(tui.RenderKeyValue("Models", fmt.Sprintf("%d", result.ModelsCount)))
 				cmd.Println(tui.RenderKeyValue("Queries", fmt.Sprintf("%d", result.QueriesCount)))
 
-				if result.QueriesCount == 0 {
+				if result.QueriesCount == 0 && !noQueries {
 					cmd.Println()
 					cmd.Println(tui.RenderWarning("No input queries found. Add .txt or .md files to Input/ directory."))
 				}
@@ -78,7 +258,7 @@ Output: <AssistantID>/Output/<plan_id>/plan.toml`,
 				cmd.Printf("  Models:  %d\n", result.ModelsCount)
 				cmd.Printf("  Queries: %d\n", result.QueriesCount)
 
-				if result.QueriesCount == 0 {
+				if result.QueriesCount == 0 && !noQueries {
 					cmd.Println("\nWarning: No input queries found. Add .txt or .md files to Input/ directory.")
 				}
 			}
@@ -88,8 +268,273 @@ Output: <AssistantID>/Output/<plan_id>/plan.toml`,
 	}
 
 	command.Flags().StringVarP(&models, "models", "m", "claude-sonnet-4-20250514", "Comma-separated list of models")
+	command.Flags().StringVar(&modelsFile, "models-file", "", "Read models from a file, one per line (merged with --models)")
+	command.Flags().BoolVar(&pickModels, "pick", false, "Choose models interactively from the configuration")
 	command.Flags().Float64Var(&temperature, "temperature", 0.7, "Temperature setting")
 	command.Flags().IntVar(&maxTokens, "max-tokens", 4096, "Max tokens for response")
+	command.Flags().StringArrayVar(&overrides, "model-override", nil, "Per-model model:temperature:max_tokens, either value may be empty (repeatable)")
+	command.Flags().Float64Var(&topP, "top-p", 0, "Nucleus sampling top_p (0 = provider default)")
+	command.Flags().IntVar(&seed, "seed", 0, "Sampling seed for reproducible runs (not sent unless set)")
+	command.Flags().BoolVar(&noQueries, "no-queries", false, "Create a prompt-only plan without queries")
+	command.Flags().StringVar(&queryFile, "query-file", "", "Split a multi-document file in Input/ into queries")
+	command.Flags().StringArrayVar(&inputGlobs, "input-glob", nil, "Use only Input/ files matching this glob as queries (repeatable)")
+	command.Flags().StringVar(&name, "name", "", "Plan name; its slug names the output directory")
+	command.Flags().StringVar(&variant, "prompt-variant", "", "Compile the system prompt from System prompt/<name>/")
+	command.Flags().BoolVar(&strict, "strict", false, "Fail if a model is not listed by any configured provider")
+	command.Flags().BoolVar(&freeze, "freeze", false, "Snapshot query files into the plan output for reproducible runs")
+	command.Flags().StringArrayVar(&variables, "var", nil, "Query template variable as key=value (repeatable)")
+	command.Flags().StringVar(&queryPrefix, "query-prefix", "", "Text prepended to every query")
+	command.Flags().StringVar(&querySuffix, "query-suffix", "", "Text appended to every query")
+
+	command.AddCommand(planShow())
 
 	return &command
 }
+
+// promptPreviewLines is the number of system prompt lines shown by plan show.
+const promptPreviewLines = 10
+
+// runModelPicker lets the user choose among the configured models,
+// with preselected models checked. Preselected models that are not
+// configured are reported, as they cannot be picked.
+func runModelPicker(cmd *cobra.Command, cfg *config.Config, preselected []string) ([]string, error) {
+	groups, err := configuredModels(cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	items := pickItems(groups)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no models configured to pick from; add models or aliases to the configuration")
+	}
+	if unknown := pick.Unknown(items, preselected); len(unknown) > 0 {
+		cmd.PrintErrf("Warning: %s not configured, leaving out of the selection\n", strings.Join(unknown, ", "))
+	}
+
+	result, err := tea.NewProgram(pick.New(items, preselected), tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, fmt.Errorf("model picker error: %w", err)
+	}
+	picker := result.(pick.Model)
+	if !picker.Confirmed() {
+		return nil, fmt.Errorf("model selection cancelled")
+	}
+	models := picker.Selected()
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no models selected")
+	}
+	return models, nil
+}
+
+// pickItems converts models grouped by provider into picker items:
+// the provider's models followed by its aliases.
+func pickItems(groups []providerModels) []pick.Item {
+	var items []pick.Item
+	for _, group := range groups {
+		for _, model := range group.Models {
+			items = append(items, pick.Item{Model: model, Description: group.Provider})
+		}
+		for _, alias := range group.Aliases {
+			items = append(items, pick.Item{
+				Model:       alias.Alias,
+				Description: fmt.Sprintf("-> %s (%s)", alias.Model, group.Provider),
+			})
+		}
+	}
+	return items
+}
+
+// checkModels verifies that every model or alias is listed by a provider.
+func checkModels(cfg *config.Config, models []string) error {
+	router, err := llm.NewRouter(cfg, llm.WithoutRateLimits())
+	if err != nil {
+		return fmt.Errorf("failed to create router: %w", err)
+	}
+
+	var errs []error
+	for _, model := range models {
+		if router.Lists(model) {
+			continue
+		}
+		fullName, _ := router.ResolveModel(model)
+		if fullName != model {
+			errs = append(errs, fmt.Errorf("model %q (alias of %q) is not listed by any provider", model, fullName))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("model %q is not listed by any provider", model))
+	}
+	if len(errs) > 0 {
+		errs = append(errs, fmt.Errorf("add the models to a provider's models list, or run 'tuna models' to see what is configured"))
+	}
+	return errors.Join(errs...)
+}
+
+// planInfo is the JSON representation of a plan printed by plan show.
+type planInfo struct {
+	PlanID        string            `json:"plan_id"`
+	Name          string            `json:"name,omitempty"`
+	AssistantID   string            `json:"assistant_id"`
+	Path          string            `json:"path"`
+	Models        []string          `json:"models"`
+	Temperature   float64           `json:"temperature"`
+	MaxTokens     int               `json:"max_tokens"`
+	TopP          float64           `json:"top_p,omitempty"`
+	Seed          *int              `json:"seed,omitempty"`
+	Frozen        bool              `json:"frozen,omitempty"`
+	PromptVariant string            `json:"prompt_variant,omitempty"`
+	Variables     map[string]string `json:"variables,omitempty"`
+	Queries       []string          `json:"queries"`
+	SystemPrompt  string            `json:"system_prompt"`
+
+	// Overrides hold per-model temperature and max tokens.
+	Overrides map[string]plan.ModelOverride `json:"overrides,omitempty"`
+}
+
+// planShow returns a cobra.Command to inspect an existing plan.
+//
+//	$ tuna plan show <PlanID> [--full] [--json]
+func planShow() *cobra.Command {
+	var (
+		full   bool
+		asJSON bool
+	)
+
+	command := cobra.Command{
+		Use:   "show <PlanID>",
+		Short: "Show an existing plan",
+		Long: `Show prints the settings of a plan: models, parameters, queries and
+a preview of the compiled system prompt.
+
+Use --full to print the entire system prompt and --json for machine
+output, which always includes the full prompt.`,
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePlanID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			p, planPath, err := plan.Load(cwd, args[0])
+			if err != nil {
+				return err
+			}
+
+			info := planInfo{
+				PlanID:        p.PlanID,
+				Name:          p.Name,
+				AssistantID:   p.AssistantID,
+				Path:          planPath,
+				Models:        p.Assistant.LLM.Models,
+				Temperature:   p.Assistant.LLM.Temperature,
+				MaxTokens:     p.Assistant.LLM.MaxTokens,
+				TopP:          p.Assistant.LLM.TopP,
+				Seed:          p.Assistant.LLM.Seed,
+				Frozen:        p.Frozen,
+				PromptVariant: p.Assistant.PromptVariant,
+				Variables:     p.Assistant.Variables,
+				Queries:       make([]string, len(p.Queries)),
+				SystemPrompt:  p.Assistant.SystemPrompt,
+
+				Overrides: p.Assistant.LLM.Overrides,
+			}
+			for i, q := range p.Queries {
+				info.Queries[i] = q.ID
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(info, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode plan: %w", err)
+				}
+				cmd.Println(string(data))
+				return nil
+			}
+
+			printPlanInfo(cmd, info, full)
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&full, "full", false, "Print the entire system prompt")
+	command.Flags().BoolVar(&asJSON, "json", false, "Output as JSON")
+
+	return &command
+}
+
+// printPlanInfo prints a plan, with the system prompt truncated
+// to promptPreviewLines unless full is set.
+func printPlanInfo(cmd *cobra.Command, info planInfo, full bool) {
+	cmd.Println(tui.RenderKeyValue("Plan ID", tui.Bold.Render(info.PlanID)))
+	if info.Name != "" {
+		cmd.Println(tui.RenderKeyValue("Name", info.Name))
+	}
+	cmd.Println(tui.RenderKeyValue("Assistant", info.AssistantID))
+	cmd.Println(tui.RenderKeyValue("Path", info.Path))
+	cmd.Println(tui.RenderKeyValue("Models", strings.Join(info.Models, ", ")))
+	cmd.Println(tui.RenderKeyValue("Temperature", fmt.Sprintf("%g", info.Temperature)))
+	cmd.Println(tui.RenderKeyValue("Max tokens", fmt.Sprintf("%d", info.MaxTokens)))
+	if info.TopP != 0 {
+		cmd.Println(tui.RenderKeyValue("Top P", fmt.Sprintf("%g", info.TopP)))
+	}
+	if info.Seed != nil {
+		cmd.Println(tui.RenderKeyValue("Seed", fmt.Sprintf("%d", *info.Seed)))
+	}
+	for _, model := range info.Models {
+		if o, ok := info.Overrides[model]; ok {
+			var params []string
+			if o.Temperature != nil {
+				params = append(params, fmt.Sprintf("temperature %g", *o.Temperature))
+			}
+			if o.MaxTokens > 0 {
+				params = append(params, fmt.Sprintf("max tokens %d", o.MaxTokens))
+			}
+			cmd.Println(tui.RenderKeyValue("  "+model, strings.Join(params, ", ")))
+		}
+	}
+	if info.Frozen {
+		cmd.Println(tui.RenderKeyValue("Frozen", "yes"))
+	}
+	if info.PromptVariant != "" {
+		cmd.Println(tui.RenderKeyValue("Prompt variant", info.PromptVariant))
+	}
+
+	cmd.Println()
+	cmd.Println(tui.Bold.Render(fmt.Sprintf("Queries (%d):", len(info.Queries))))
+	for _, id := range info.Queries {
+		cmd.Printf("  %s\n", id)
+	}
+
+	cmd.Println()
+	cmd.Println(tui.Bold.Render("System prompt:"))
+	prompt := strings.TrimRight(info.SystemPrompt, "\n")
+	if prompt == "" {
+		cmd.Println(tui.Muted.Render("  (empty)"))
+		return
+	}
+	lines := strings.Split(prompt, "\n")
+	if !full && len(lines) > promptPreviewLines {
+		cmd.Println(strings.Join(lines[:promptPreviewLines], "\n"))
+		cmd.Println(tui.Muted.Render(fmt.Sprintf("... %d more lines, use --full to show all", len(lines)-promptPreviewLines)))
+		return
+	}
+	cmd.Println(prompt)
+}
+
+// resolveAssistantID returns the assistant ID from arguments,
+// falling back to default_assistant from the configuration.
+func resolveAssistantID(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	result, err := loadOptionalConfig()
+	if err != nil {
+		return "", err
+	}
+	if result != nil && result.Config.DefaultAssistant != "" {
+		return result.Config.DefaultAssistant, nil
+	}
+
+	return "", fmt.Errorf("assistant ID is required\n\nPass it as an argument or set default_assistant in %s", config.ConfigFileName)
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+func TestPlanShow(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	var prompt []string
+	for i := range promptPreviewLines + 2 {
+		prompt = append(prompt, fmt.Sprintf("line %d", i+1))
+	}
+	outputDir := filepath.Join(dir, "Helper", "Output", "plan-id")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(filepath.Join(outputDir, "plan.toml"), &plan.Plan{
+		PlanID:      "plan-id",
+		AssistantID: "Helper",
+		Assistant: plan.Assistant{
+			SystemPrompt: strings.Join(prompt, "\n"),
+			LLM:          plan.LLM{Models: []string{"gpt-4o", "o1"}, Temperature: 0.5, MaxTokens: 100},
+		},
+		Queries: []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+	}))
+
+	show := func(t *testing.T, args ...string) string {
+		t.Helper()
+		var out, errOut bytes.Buffer
+		cmd := planShow()
+		cmd.SetOut(&out)
+		cmd.SetErr(&errOut)
+		cmd.SetArgs(append([]string{"plan-id"}, args...))
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	t.Run("text", func(t *testing.T) {
+		out := show(t)
+		assert.Contains(t, out, "plan-id")
+		assert.Contains(t, out, "gpt-4o, o1")
+		assert.Contains(t, out, "Queries (2):\n  q1.md\n  q2.md\n")
+		assert.Contains(t, out, fmt.Sprintf("line %d\n", promptPreviewLines))
+		assert.NotContains(t, out, fmt.Sprintf("line %d\n", promptPreviewLines+1))
+		assert.Contains(t, out, "2 more lines, use --full to show all")
+	})
+
+	t.Run("full", func(t *testing.T) {
+		out := show(t, "--full")
+		assert.Contains(t, out, fmt.Sprintf("line %d\n", promptPreviewLines+2))
+		assert.NotContains(t, out, "more lines")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var info planInfo
+		require.NoError(t, json.Unmarshal([]byte(show(t, "--json")), &info))
+		assert.Equal(t, "plan-id", info.PlanID)
+		assert.Equal(t, "Helper", info.AssistantID)
+		assert.Equal(t, []string{"gpt-4o", "o1"}, info.Models)
+		assert.Equal(t, 0.5, info.Temperature)
+		assert.Equal(t, 100, info.MaxTokens)
+		assert.Equal(t, []string{"q1.md", "q2.md"}, info.Queries)
+		assert.Equal(t, strings.Join(prompt, "\n"), info.SystemPrompt)
+	})
+}
+
+func TestCheckModels(t *testing.T) {
+	cfg := &config.Config{
+		DefaultProvider: "openai",
+		Providers: []config.Provider{
+			{Name: "openai", BaseURL: "http://localhost", APIToken: "token", Models: []string{"gpt-4o"}},
+		},
+		Aliases: map[string]string{"4o": "gpt-4o", "gone": "gpt-3"},
+	}
+
+	assert.NoError(t, checkModels(cfg, []string{"gpt-4o"}), "listed model")
+	assert.NoError(t, checkModels(cfg, []string{"4o"}), "alias of a listed model")
+
+	err := checkModels(cfg, []string{"gpt-4o", "o1", "gone"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `model "o1" is not listed by any provider`)
+	assert.Contains(t, err.Error(), `model "gone" (alias of "gpt-3") is not listed by any provider`)
+	assert.NotContains(t, err.Error(), `"gpt-4o" is not listed`)
+}
+
+func TestCompletePlanID(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	for assistant, id := range map[string]string{"Helper": "4f1c", "Writer": "4a07"} {
+		outputDir := filepath.Join(dir, assistant, "Output", id)
+		require.NoError(t, os.MkdirAll(outputDir, 0755))
+		require.NoError(t, plan.Save(filepath.Join(outputDir, "plan.toml"), &plan.Plan{
+			PlanID:      id,
+			AssistantID: assistant,
+			Assistant:   plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o"}}},
+			Queries:     []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+		}))
+	}
+	// Not a plan: skipped rather than failing the completion
+	brokenDir := filepath.Join(dir, "Broken", "Output", "bad")
+	require.NoError(t, os.MkdirAll(brokenDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(brokenDir, "plan.toml"), []byte("not = [toml"), 0644))
+
+	tests := map[string]struct {
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		"all plans": {
+			want: []string{
+				"4a07\tWriter (1 models, 2 queries)",
+				"4f1c\tHelper (1 models, 2 queries)",
+			},
+		},
+		"prefix": {
+			toComplete: "4f",
+			want:       []string{"4f1c\tHelper (1 models, 2 queries)"},
+		},
+		"no match": {
+			toComplete: "ff",
+			want:       []string{},
+		},
+		"argument already given": {
+			args: []string{"4f1c"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, directive := completePlanID(planShow(), tc.args, tc.toComplete)
+			assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+			assert.ElementsMatch(t, tc.want, got)
+		})
+	}
+}
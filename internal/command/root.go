@@ -36,7 +36,15 @@ efficiently by organizing inputs, outputs, and execution plans.`,
 		Exec(),
 		View(),
 		Config(),
+		Bench(),
+		Stats(),
+		Assistant(),
+		Models(),
+		Export(),
+		Diff(),
+		Ask(),
 	)
+	withFirstRunGuidance(&command)
 
 	return &command
 }
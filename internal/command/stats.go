@@ -0,0 +1,108 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/view"
+)
+
+// Stats returns a cobra.Command for response statistics.
+//
+//	$ tuna stats <subcommand>
+func Stats() *cobra.Command {
+	command := cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics about plan responses",
+		Long: `Statistics commands for executed plans.
+
+Subcommands:
+  tokens    Show token usage per model and the most expensive queries`,
+	}
+
+	command.AddCommand(
+		statsTokens(),
+	)
+
+	return &command
+}
+
+// statsTokens shows token usage across a plan's responses.
+func statsTokens() *cobra.Command {
+	var (
+		asJSON bool
+		top    int
+	)
+
+	command := cobra.Command{
+		Use:   "tokens <PlanID>",
+		Short: "Show token usage of a plan's responses",
+		Long: `Show input and output token totals per model and overall,
+read from the metadata of each response file, plus the most
+expensive query/model pairs.
+
+Examples:
+  tuna stats tokens 01JG...
+  tuna stats tokens 01JG... --top 10 --json`,
+
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePlanID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			planID := args[0]
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			p, planPath, err := plan.Load(cwd, planID)
+			if err != nil {
+				return err
+			}
+			planID = p.PlanID // The plan may be referenced by name
+
+			groups, err := view.LoadResponses(planPath)
+			if err != nil {
+				return fmt.Errorf("failed to load responses: %w", err)
+			}
+
+			report := view.TokenUsage(groups, top)
+
+			if asJSON {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal report: %w", err)
+				}
+				cmd.Println(string(data))
+				return nil
+			}
+
+			cmd.Printf("Plan: %s\n\n", planID)
+
+			cmd.Println("Per model:")
+			for _, m := range append(report.Models, report.Total) {
+				cmd.Printf("  %-40s %4d responses  %8d in  %8d out  %8d total\n",
+					m.Model, m.Responses, m.Input, m.Output, m.Input+m.Output)
+			}
+
+			if len(report.Top) > 0 {
+				cmd.Println("\nMost expensive:")
+				for i, r := range report.Top {
+					cmd.Printf("  %2d. %s -> %s: %d tokens (%d in, %d out)\n",
+						i+1, r.QueryID, r.Model, r.Total(), r.Input, r.Output)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	command.Flags().IntVar(&top, "top", 5, "Number of most expensive responses to list")
+
+	return &command
+}
@@ -3,20 +3,49 @@ package command
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/exec"
 	"go.octolab.org/toolset/tuna/internal/plan"
 	"go.octolab.org/toolset/tuna/internal/tui"
 	"go.octolab.org/toolset/tuna/internal/view"
 	viewtui "go.octolab.org/toolset/tuna/internal/tui/view"
 )
 
+// Non-interactive view output formats.
+const (
+	viewFormatText = "text"
+	viewFormatJSON = "json"
+	viewFormatCSV  = "csv"
+
+	viewFormatMatrix    = "matrix"     // Queries × models grid of ratings
+	viewFormatMatrixCSV = "matrix-csv" // The same grid as CSV
+)
+
 // View returns the view command.
 func View() *cobra.Command {
+	var (
+		importDir   string
+		importModel string
+		strictYAML  bool
+		onlyFailed  bool
+		tempSweep   bool
+		byModel     bool
+		models      []string
+		ratings     []string
+		since       time.Duration
+		raw         bool
+		format      string
+	)
+
 	cmd := &cobra.Command{
-		Use:   "view <PlanID>",
+		Use:   "view <PlanID> [--raw <QueryID> <Model>]",
 		Short: "View and rate LLM responses",
 		Long: `View opens an interactive terminal UI for browsing LLM responses.
 
@@ -31,20 +60,101 @@ Navigation:
   Tab          Expand/collapse input query
   Space/g/b    Rate responses as good or bad
   u            Clear rating
-  q            Quit`,
-		Args: cobra.ExactArgs(1),
+  c            Copy response markdown to clipboard
+  q            Quit
+
+Use --import <dir> to copy markdown responses generated elsewhere into
+the plan so they can be rated. Files are matched to queries by name.
+The --import-model column is shown alongside the plan models but is
+never sent to a provider by 'tuna exec'.
+
+Use --only-failed to review only missing, empty, or moderation-flagged
+responses after a run with errors. Responses can also be narrowed down
+with --model, --rating (good, bad or unrated) and --since (e.g. 24h);
+combined filters must all match.
+
+Use --by-model without a terminal to summarize ratings per model across
+all queries, with the number of queries where a model is the only one
+rated good.
+
+Use --temperature-sweep to label columns with the sampling temperature
+recorded in each response (e.g. "gpt-4o @ T=0.2") when comparing runs
+of the same model at different temperatures.
+
+Use --raw <QueryID> <Model> to print a single response without front
+matter to stdout, e.g. for piping into other tools.
+
+Use --format json or --format csv to print the responses for scripts and
+spreadsheets instead of opening the UI. JSON includes ratings and
+execution metadata; CSV has one row per query and model with columns
+query_id, model, rating, input_tokens, output_tokens and duration
+(in seconds).
+
+Use --format matrix for a grid with a row per query and a column per
+model, each cell showing good, bad, unrated or missing, and
+--format matrix-csv to export the same grid for spreadsheets.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if raw {
+				return cobra.ExactArgs(3)(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		ValidArgsFunction: completePlanID,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			planID := args[0]
 
+			switch format {
+			case viewFormatText, viewFormatJSON, viewFormatCSV, viewFormatMatrix, viewFormatMatrixCSV:
+			default:
+				return fmt.Errorf("unknown format %q: expected %s, %s, %s, %s or %s", format,
+					viewFormatText, viewFormatJSON, viewFormatCSV, viewFormatMatrix, viewFormatMatrixCSV)
+			}
+
 			cwd, err := os.Getwd()
 			if err != nil {
 				return fmt.Errorf("failed to get working directory: %w", err)
 			}
 
-			_, planPath, err := plan.Load(cwd, planID)
+			p, planPath, err := plan.Load(cwd, planID)
 			if err != nil {
 				return err
 			}
+			planID = p.PlanID // The plan may be referenced by name
+
+			if raw {
+				content, err := view.ReadResponse(planPath, args[1], args[2])
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(cmd.OutOrStdout(), content)
+				return nil
+			}
+
+			if importDir != "" {
+				if err := exec.CheckLock(filepath.Dir(planPath)); err != nil {
+					return fmt.Errorf("cannot import while exec is running: %w", err)
+				}
+				result, err := view.Import(planPath, importDir, importModel)
+				if err != nil {
+					return err
+				}
+				cmd.Printf("Imported %d responses as %s\n", len(result.Imported), result.Model)
+			}
+
+			if strictYAML {
+				invalid, err := view.FindInvalidResponses(planPath)
+				if err != nil {
+					return err
+				}
+				if len(invalid) > 0 {
+					lines := make([]string, len(invalid))
+					for i, resp := range invalid {
+						lines[i] = fmt.Sprintf("  %s: %v", resp.FilePath, resp.Err)
+					}
+					return fmt.Errorf("%d response files have invalid front matter:\n%s",
+						len(invalid), strings.Join(lines, "\n"))
+				}
+			}
 
 			groups, err := view.LoadResponses(planPath)
 			if err != nil {
@@ -55,15 +165,92 @@ Navigation:
 				return fmt.Errorf("no responses found for plan %s", planID)
 			}
 
+			filter := view.Filter{Failed: onlyFailed, Models: models}
+			for _, r := range ratings {
+				rating, err := view.ParseRating(r)
+				if err != nil {
+					return fmt.Errorf("--rating: %w", err)
+				}
+				filter.Ratings = append(filter.Ratings, rating)
+			}
+			if since > 0 {
+				filter.Since = time.Now().Add(-since)
+			}
+			if !filter.IsZero() {
+				groups = view.FilterGroups(groups, filter)
+				if len(groups) == 0 {
+					if onlyFailed {
+						cmd.Printf("No failed responses in plan %s\n", planID)
+					} else {
+						cmd.Printf("No responses in plan %s match the filters\n", planID)
+					}
+					return nil
+				}
+			}
+
+			switch format {
+			case viewFormatJSON:
+				return view.ExportJSON(cmd.OutOrStdout(), planID, groups)
+			case viewFormatCSV:
+				return view.ExportCSV(cmd.OutOrStdout(), groups)
+			case viewFormatMatrix:
+				printMatrix(cmd, view.BuildMatrix(groups))
+				return nil
+			case viewFormatMatrixCSV:
+				return view.ExportMatrixCSV(cmd.OutOrStdout(), view.BuildMatrix(groups))
+			}
+
+			// Responses of models removed from the plan are not shown
+			others, err := view.OtherModels(planPath)
+			if err != nil {
+				return err
+			}
+			var otherNotice string
+			if len(others) > 0 {
+				otherNotice = fmt.Sprintf("Not shown: responses of models no longer in the plan (%s)", strings.Join(others, ", "))
+			}
+
 			// Non-interactive mode: print summary
 			if !tui.IsInteractive() {
-				return printViewSummary(planID, groups)
+				if otherNotice != "" {
+					cmd.PrintErrln(otherNotice)
+				}
+				if byModel {
+					return printModelSummary(planID, groups)
+				}
+				return printViewSummary(planID, groups, tempSweep)
+			}
+
+			// Ratings are written to response files that a running exec may replace
+			notice := otherNotice
+			if err := exec.CheckLock(filepath.Dir(planPath)); err != nil {
+				notice = fmt.Sprintf("Warning: %v; ratings may be overwritten", err)
+			}
+
+			// Token usage is shown across runs, including interrupted ones
+			var usage exec.TokenUsage
+			if ledger, err := exec.LoadUsage(filepath.Dir(planPath)); err == nil {
+				usage = ledger.Total()
+			} else if notice == "" {
+				notice = fmt.Sprintf("Warning: %v", err)
+			}
+
+			cfgResult, err := loadOptionalConfig()
+			if err != nil {
+				return err
 			}
 
-			model := viewtui.New(planID, groups)
-			p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+			model := viewtui.New(planID, groups, viewtui.Options{
+				MarkdownStyle:    markdownStyle(cmd, cfgResult),
+				TemperatureSweep: tempSweep,
+
+				TimestampPrecision: timestampPrecision(cfgResult),
+				Notice:             notice,
+				Usage:              usage,
+			})
+			program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
-			if _, err := p.Run(); err != nil {
+			if _, err := program.Run(); err != nil {
 				return fmt.Errorf("viewer error: %w", err)
 			}
 
@@ -71,11 +258,23 @@ Navigation:
 		},
 	}
 
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print the response to <QueryID> from <Model> to stdout")
+	cmd.Flags().StringVar(&format, "format", viewFormatText, "Print responses as text, json, csv, matrix or matrix-csv instead of opening the UI")
+	cmd.Flags().BoolVar(&onlyFailed, "only-failed", false, "Show only missing, empty, or flagged responses")
+	cmd.Flags().StringSliceVar(&models, "model", nil, "Show only responses of these models (repeatable)")
+	cmd.Flags().StringSliceVar(&ratings, "rating", nil, "Show only responses rated good, bad or unrated (repeatable)")
+	cmd.Flags().DurationVar(&since, "since", 0, "Show only responses executed within this period, e.g. 24h")
+	cmd.Flags().BoolVar(&byModel, "by-model", false, "Summarize ratings per model in non-interactive mode")
+	cmd.Flags().BoolVar(&tempSweep, "temperature-sweep", false, "Label columns with each response's temperature")
+	cmd.Flags().BoolVar(&strictYAML, "strict-yaml", false, "Refuse to open if any response has malformed front matter")
+	cmd.Flags().StringVar(&importDir, "import", "", "Import markdown responses from a directory before viewing")
+	cmd.Flags().StringVar(&importModel, "import-model", "imported", "Model name to file imported responses under")
+
 	return cmd
 }
 
 // printViewSummary prints a non-interactive summary of responses.
-func printViewSummary(planID string, groups []view.ResponseGroup) error {
+func printViewSummary(planID string, groups []view.ResponseGroup, withTemperature bool) error {
 	fmt.Printf("Plan: %s\n", planID)
 	fmt.Printf("Queries: %d\n", len(groups))
 
@@ -120,7 +319,12 @@ func printViewSummary(planID string, groups []view.ResponseGroup) error {
 				}
 			}
 
-			fmt.Printf("  - %s %s: %s\n", resp.Model, ratingStr, contentPreview)
+			lengthStr := ""
+			if resp.Words > 0 {
+				lengthStr = fmt.Sprintf(" (%d words, %d chars)", resp.Words, resp.Chars)
+			}
+
+			fmt.Printf("  - %s %s%s: %s\n", resp.Label(withTemperature), ratingStr, lengthStr, contentPreview)
 		}
 		fmt.Println()
 	}
@@ -128,3 +332,69 @@ func printViewSummary(planID string, groups []view.ResponseGroup) error {
 	fmt.Println("Run without --no-tui flag to view responses interactively.")
 	return nil
 }
+
+// printMatrix prints the comparison matrix as a terminal grid.
+func printMatrix(cmd *cobra.Command, m *view.Matrix) {
+	rows := make([][]string, len(m.Rows))
+	for i, row := range m.Rows {
+		rows[i] = []string{row.QueryID}
+		for _, cell := range row.Cells {
+			rows[i] = append(rows[i], renderCell(cell))
+		}
+	}
+	cmd.Print(tui.RenderTable(append([]string{"Query"}, m.Models...), rows))
+}
+
+// renderCell styles a matrix cell by its status.
+func renderCell(status string) string {
+	switch status {
+	case view.CellGood:
+		return tui.Success.Render(status)
+	case view.CellBad:
+		return tui.Error.Render(status)
+	case view.CellMissing:
+		return tui.Warning.Render(status)
+	default:
+		return tui.Muted.Render(status)
+	}
+}
+
+// printModelSummary prints a non-interactive summary of ratings per model.
+func printModelSummary(planID string, groups []view.ResponseGroup) error {
+	fmt.Printf("Plan: %s\n", planID)
+	fmt.Printf("Queries: %d\n\n", len(groups))
+
+	for _, tally := range view.RatingsByModel(groups) {
+		fmt.Printf("  - %s: %d good, %d bad, %d unrated, %d wins\n",
+			tally.Model, tally.Good, tally.Bad, tally.Unrated, tally.Wins)
+	}
+
+	return nil
+}
+
+// markdownStyle returns the configured glamour style, warning and falling
+// back to viewtui.DefaultMarkdownStyle if it cannot be loaded. The default
+// is also used if there is no configuration or it sets no style.
+func markdownStyle(cmd *cobra.Command, result *config.LoadResult) string {
+	if result == nil || result.Config.MarkdownStyle == "" {
+		return viewtui.DefaultMarkdownStyle
+	}
+
+	style := result.MarkdownStyle()
+	if err := viewtui.ValidateMarkdownStyle(style); err != nil {
+		cmd.PrintErrf("Warning: markdown_style %q cannot be loaded (%v), using %q\n",
+			style, err, viewtui.DefaultMarkdownStyle)
+		return viewtui.DefaultMarkdownStyle
+	}
+
+	return style
+}
+
+// timestampPrecision returns the configured precision of rating
+// timestamps, or zero for the default if there is no configuration.
+func timestampPrecision(result *config.LoadResult) time.Duration {
+	if result == nil {
+		return 0
+	}
+	return result.Config.Precision()
+}
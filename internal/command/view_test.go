@@ -0,0 +1,120 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+func TestView_StrictYAML(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	outputDir := filepath.Join(dir, "Helper", "Output", "plan-id")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(filepath.Join(outputDir, "plan.toml"), &plan.Plan{
+		PlanID:      "plan-id",
+		AssistantID: "Helper",
+		Assistant:   plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o"}}},
+		Queries:     []plan.Query{{ID: "good.md"}, {ID: "bad.md"}},
+	}))
+	inputDir := filepath.Join(dir, "Helper", "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	for _, name := range []string{"good.md", "bad.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte("Question"), 0644))
+	}
+	modelDir := filepath.Join(outputDir, exec.ModelHash("gpt-4o"))
+	require.NoError(t, os.MkdirAll(modelDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(modelDir, "good_response.md"), []byte("---\nmodel: gpt-4o\n---\n\nFine.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(modelDir, "bad_response.md"), []byte("---\nmodel: gpt-4o\n  rating: [\n---\n\nCorrupt.\n"), 0644))
+
+	tests := map[string]struct {
+		args    []string
+		wantErr bool
+	}{
+		"strict":  {args: []string{"plan-id", "--strict-yaml", "--format", "json"}, wantErr: true},
+		"lenient": {args: []string{"plan-id", "--format", "json"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			cmd := View()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs(tc.args)
+
+			err := cmd.Execute()
+			if !tc.wantErr {
+				require.NoError(t, err, "corrupt front matter renders blank metadata")
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "1 response files have invalid front matter")
+			assert.Contains(t, err.Error(), filepath.Join(modelDir, "bad_response.md"))
+			assert.NotContains(t, err.Error(), "good_response.md")
+		})
+	}
+}
+
+func TestView_Matrix(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	outputDir := filepath.Join(dir, "Helper", "Output", "plan-id")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(filepath.Join(outputDir, "plan.toml"), &plan.Plan{
+		PlanID:      "plan-id",
+		AssistantID: "Helper",
+		Assistant:   plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}}},
+		Queries:     []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+	}))
+	inputDir := filepath.Join(dir, "Helper", "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	for _, name := range []string{"q1.md", "q2.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte("Question"), 0644))
+	}
+	modelDir := filepath.Join(outputDir, exec.ModelHash("gpt-4o"))
+	require.NoError(t, os.MkdirAll(modelDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(modelDir, "q1_response.md"), []byte("---\nmodel: gpt-4o\nrating: good\n---\n\nFine.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(modelDir, "q2_response.md"), []byte("---\nmodel: gpt-4o\n---\n\nUnrated.\n"), 0644))
+
+	tests := map[string]struct {
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		"csv": {
+			format: "matrix-csv",
+			check: func(t *testing.T, out string) {
+				assert.Equal(t, "query_id,gpt-4o,o1\nq1.md,good,missing\nq2.md,unrated,missing\n", out)
+			},
+		},
+		"grid": {
+			format: "matrix",
+			check: func(t *testing.T, out string) {
+				assert.Regexp(t, `Query\s+.*gpt-4o\s+.*o1`, out)
+				assert.Regexp(t, `q1\.md\s+.*good\s+.*missing`, out)
+				assert.Regexp(t, `q2\.md\s+.*unrated\s+.*missing`, out)
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			cmd := View()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetArgs([]string{"plan-id", "--format", tc.format})
+			require.NoError(t, cmd.Execute())
+			tc.check(t, out.String())
+		})
+	}
+}
@@ -3,27 +3,217 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
 )
 
 // Config represents the root tuna configuration.
 type Config struct {
-	DefaultProvider string            `toml:"default_provider"`
-	Aliases         map[string]string `toml:"aliases"`
-	Providers       []Provider        `toml:"providers"`
+	DefaultProvider  string            `toml:"default_provider" yaml:"default_provider" json:"default_provider"`
+	DefaultAssistant string            `toml:"default_assistant" yaml:"default_assistant" json:"default_assistant"` // Used when commands omit the AssistantID
+	MarkdownStyle    string            `toml:"markdown_style" yaml:"markdown_style" json:"markdown_style"`          // Glamour builtin style name or JSON style file path, relative to the config file
+	DefaultParallel  int               `toml:"default_parallel" yaml:"default_parallel" json:"default_parallel"`    // Used when exec is run without --parallel
+	ConfirmAbove     int               `toml:"confirm_above" yaml:"confirm_above" json:"confirm_above"`             // Request count above which exec asks for confirmation
+	GlobalRateLimit  string            `toml:"global_rate_limit" yaml:"global_rate_limit" json:"global_rate_limit"` // Shared by all providers, e.g. for a common gateway
+	Aliases          map[string]string `toml:"aliases" yaml:"aliases" json:"aliases"`
+	Providers        []Provider        `toml:"providers" yaml:"providers" json:"providers"`
+	RejectIf         *RejectRules      `toml:"reject_if" yaml:"reject_if" json:"reject_if"` // Post-filters applied to generated responses
+
+	// SystemPromptPrefix is a shared preamble prepended to the system
+	// prompt of every assistant when a plan is created, except for the
+	// assistants listed in SystemPromptPrefixSkip.
+	SystemPromptPrefix     string   `toml:"system_prompt_prefix,multiline" yaml:"system_prompt_prefix" json:"system_prompt_prefix"`
+	SystemPromptPrefixSkip []string `toml:"system_prompt_prefix_skip" yaml:"system_prompt_prefix_skip" json:"system_prompt_prefix_skip"`
+
+	// QueryPrefix and QuerySuffix wrap every query of plans created with
+	// `tuna plan` that do not set their own; see QueryWrap.
+	QueryPrefix string `toml:"query_prefix,multiline" yaml:"query_prefix" json:"query_prefix"`
+	QuerySuffix string `toml:"query_suffix,multiline" yaml:"query_suffix" json:"query_suffix"`
+
+	// Assistants holds settings of single assistants, keyed by assistant ID.
+	Assistants map[string]AssistantConfig `toml:"assistants" yaml:"assistants" json:"assistants"`
+
+	// TimestampPrecision truncates executed_at and rated_at in response
+	// metadata, e.g. "1ms" (default "1s").
+	TimestampPrecision string `toml:"timestamp_precision" yaml:"timestamp_precision" json:"timestamp_precision"`
+}
+
+// DefaultConfirmAbove is the request count above which exec asks
+// for confirmation when confirm_above is not set.
+const DefaultConfirmAbove = 50
+
+// ConfirmThreshold returns the request count above which exec asks for confirmation.
+func (c *Config) ConfirmThreshold() int {
+	if c.ConfirmAbove > 0 {
+		return c.ConfirmAbove
+	}
+	return DefaultConfirmAbove
+}
+
+// Precision returns the parsed timestamp precision, or zero for the default.
+// The value is checked by Validate.
+func (c *Config) Precision() time.Duration {
+	precision, _ := ParseTimeout(c.TimestampPrecision)
+	return precision
+}
+
+// PromptPrefix returns the system prompt prefix for the assistant,
+// or empty string if it has opted out.
+func (c *Config) PromptPrefix(assistantID string) string {
+	if slices.Contains(c.SystemPromptPrefixSkip, assistantID) {
+		return ""
+	}
+	return c.SystemPromptPrefix
+}
+
+// AssistantConfig holds settings of a single assistant that take
+// precedence over the global ones.
+type AssistantConfig struct {
+	QueryPrefix string `toml:"query_prefix,multiline" yaml:"query_prefix" json:"query_prefix"`
+	QuerySuffix string `toml:"query_suffix,multiline" yaml:"query_suffix" json:"query_suffix"`
+}
+
+// QueryWrap returns the query prefix and suffix for the assistant:
+// its own where set, otherwise the global ones.
+func (c *Config) QueryWrap(assistantID string) (prefix, suffix string) {
+	prefix, suffix = c.QueryPrefix, c.QuerySuffix
+	if a, ok := c.Assistants[assistantID]; ok {
+		if a.QueryPrefix != "" {
+			prefix = a.QueryPrefix
+		}
+		if a.QuerySuffix != "" {
+			suffix = a.QuerySuffix
+		}
+	}
+	return prefix, suffix
+}
+
+// RejectRules describes heuristics that mark a generated response as failed.
+type RejectRules struct {
+	MinLength int      `toml:"min_length" yaml:"min_length" json:"min_length"` // Minimum length in characters, after trimming whitespace
+	Contains  []string `toml:"contains" yaml:"contains" json:"contains"`       // Case-insensitive substrings that reject the response
+}
+
+// Check returns the reason the content is rejected, or empty string if it passes.
+// A nil receiver accepts everything.
+func (r *RejectRules) Check(content string) string {
+	if r == nil {
+		return ""
+	}
+
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return "empty response"
+	}
+	if n := utf8.RuneCountInString(trimmed); n < r.MinLength {
+		return fmt.Sprintf("response too short (%d < %d chars)", n, r.MinLength)
+	}
+
+	lower := strings.ToLower(content)
+	for _, pattern := range r.Contains {
+		if pattern != "" && strings.Contains(lower, strings.ToLower(pattern)) {
+			return fmt.Sprintf("response contains %q", pattern)
+		}
+	}
+
+	return ""
 }
 
 // Provider describes a single LLM provider configuration.
 type Provider struct {
-	Name        string   `toml:"name"`
-	BaseURL     string   `toml:"base_url"`
-	APIToken    string   `toml:"api_token"`     // Direct token value
-	APITokenEnv string   `toml:"api_token_env"` // Environment variable reference
-	RateLimit   string   `toml:"rate_limit"`
-	Models      []string `toml:"models"`
+	Name        string   `toml:"name" yaml:"name" json:"name"`
+	BaseURL     string   `toml:"base_url" yaml:"base_url" json:"base_url"`
+	APIToken    string   `toml:"api_token" yaml:"api_token" json:"api_token"`             // Direct token value
+	APITokenEnv string   `toml:"api_token_env" yaml:"api_token_env" json:"api_token_env"` // Environment variable reference
+	RateLimit   string   `toml:"rate_limit" yaml:"rate_limit" json:"rate_limit"`
+	Models      []string `toml:"models" yaml:"models" json:"models"`
+	Moderate    bool     `toml:"moderate" yaml:"moderate" json:"moderate"` // Run moderation check before each request
+	Weight      int      `toml:"weight" yaml:"weight" json:"weight"`       // Share of requests for models listed by several providers (default 1)
+
+	// ConnectTimeout limits the dial and TLS handshake phases, e.g. "5s".
+	// It is independent of how long generation itself may take.
+	ConnectTimeout string `toml:"connect_timeout" yaml:"connect_timeout" json:"connect_timeout"`
+	// RequestTimeout limits a whole chat request, including retries and
+	// streamed generation, e.g. "5m" (default DefaultRequestTimeout).
+	RequestTimeout string `toml:"request_timeout" yaml:"request_timeout" json:"request_timeout"`
+
+	// MaxRetries re-sends requests failing with 429/5xx or network errors.
+	MaxRetries int `toml:"max_retries" yaml:"max_retries" json:"max_retries"`
+	// RetryJitter randomizes retry delays: "none" (default), "full" or "equal".
+	RetryJitter string `toml:"retry_jitter" yaml:"retry_jitter" json:"retry_jitter"`
+	// RetryMaxElapsed bounds the total time spent retrying, e.g. "2m".
+	RetryMaxElapsed string `toml:"retry_max_elapsed" yaml:"retry_max_elapsed" json:"retry_max_elapsed"`
+
+	// Proxy settings override HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables for this provider.
+	HTTPProxy  string `toml:"http_proxy" yaml:"http_proxy" json:"http_proxy"`
+	HTTPSProxy string `toml:"https_proxy" yaml:"https_proxy" json:"https_proxy"`
+	NoProxy    string `toml:"no_proxy" yaml:"no_proxy" json:"no_proxy"` // Comma-separated hosts, domains or CIDRs
+
+	// Headers are sent with every request. A value of the form "$VAR"
+	// or "${VAR}" is read from the environment, keeping secrets out of
+	// the configuration file.
+	Headers map[string]string `toml:"headers" yaml:"headers" json:"headers"`
+
+	// Token prices per 1000 tokens, used to record the cost of responses.
+	InputCostPer1K  float64 `toml:"input_cost_per_1k" yaml:"input_cost_per_1k" json:"input_cost_per_1k"`
+	OutputCostPer1K float64 `toml:"output_cost_per_1k" yaml:"output_cost_per_1k" json:"output_cost_per_1k"`
+	// ModelCosts overrides the prices for individual models.
+	ModelCosts map[string]Pricing `toml:"model_costs" yaml:"model_costs" json:"model_costs"`
+
+	// ContextWindows holds the context window in tokens of individual
+	// models, used to warn about requests that may not fit.
+	ContextWindows map[string]int `toml:"context_windows" yaml:"context_windows" json:"context_windows"`
+}
+
+// DefaultRequestTimeout is used when a provider sets no request_timeout.
+const DefaultRequestTimeout = 120 * time.Second
+
+// Timeout returns the parsed request timeout, or DefaultRequestTimeout
+// if it is not set.
+func (p *Provider) Timeout() (time.Duration, error) {
+	timeout, err := ParseTimeout(p.RequestTimeout)
+	if err != nil {
+		return 0, err
+	}
+	if timeout == 0 {
+		return DefaultRequestTimeout, nil
+	}
+	return timeout, nil
+}
+
+// Pricing holds token prices per 1000 tokens.
+type Pricing struct {
+	InputPer1K  float64 `toml:"input_cost_per_1k" yaml:"input_cost_per_1k" json:"input_cost_per_1k"`
+	OutputPer1K float64 `toml:"output_cost_per_1k" yaml:"output_cost_per_1k" json:"output_cost_per_1k"`
+}
+
+// Cost returns the cost of a request with the given token usage.
+func (p Pricing) Cost(promptTokens, outputTokens int) float64 {
+	return (float64(promptTokens)*p.InputPer1K + float64(outputTokens)*p.OutputPer1K) / 1000
+}
+
+// Pricing returns the token prices of a model served by the provider.
+func (p *Provider) Pricing(model string) Pricing {
+	if pricing, ok := p.ModelCosts[model]; ok {
+		return pricing
+	}
+	return Pricing{InputPer1K: p.InputCostPer1K, OutputPer1K: p.OutputCostPer1K}
+}
+
+// ContextWindow returns the context window of a model served by the
+// provider in tokens, or zero if it is not configured.
+func (p *Provider) ContextWindow(model string) int {
+	return p.ContextWindows[model]
 }
 
 // ResolveAPIToken returns the API token using priority:
@@ -43,6 +233,39 @@ func (p *Provider) ResolveAPIToken() (string, error) {
 	return "", errors.New("neither api_token nor api_token_env is specified")
 }
 
+// envRefRegex matches a header value referencing an environment variable.
+var envRefRegex = regexp.MustCompile(`^\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))$`)
+
+// EnvReference returns the environment variable a header value refers
+// to as "$VAR" or "${VAR}", if it is such a reference.
+func EnvReference(value string) (string, bool) {
+	if m := envRefRegex.FindStringSubmatch(value); m != nil {
+		return m[1] + m[2], true
+	}
+	return "", false
+}
+
+// ResolveHeaders returns the provider headers with environment variable
+// references expanded. Returns error if a referenced variable is not set.
+func (p *Provider) ResolveHeaders() (map[string]string, error) {
+	if len(p.Headers) == 0 {
+		return nil, nil
+	}
+
+	headers := make(map[string]string, len(p.Headers))
+	for name, value := range p.Headers {
+		if env, ok := EnvReference(value); ok {
+			resolved := os.Getenv(env)
+			if resolved == "" {
+				return nil, fmt.Errorf("header %q: environment variable %q is not set", name, env)
+			}
+			value = resolved
+		}
+		headers[name] = value
+	}
+	return headers, nil
+}
+
 // RateLimit represents a parsed rate limit value.
 type RateLimit struct {
 	Value int           // Number of requests
@@ -92,6 +315,78 @@ func ParseRateLimit(s string) (*RateLimit, error) {
 	}, nil
 }
 
+// ResolveModel returns the full model name for a model or alias and the
+// provider serving it: the one listing the model, or the default provider.
+// Provider is nil if the default provider is not configured.
+func (c *Config) ResolveModel(model string) (fullName string, provider *Provider) {
+	fullName = model
+	if name, ok := c.Aliases[model]; ok {
+		fullName = name
+	}
+
+	var fallback *Provider
+	for i := range c.Providers {
+		p := &c.Providers[i]
+		for _, m := range p.Models {
+			if m == fullName {
+				return fullName, p
+			}
+		}
+		if p.Name == c.DefaultProvider {
+			fallback = p
+		}
+	}
+	return fullName, fallback
+}
+
+// Provider returns the provider with the given name, or nil if none.
+func (c *Config) Provider(name string) *Provider {
+	for i := range c.Providers {
+		if c.Providers[i].Name == name {
+			return &c.Providers[i]
+		}
+	}
+	return nil
+}
+
+// Pricing returns the token prices of a model or alias, from the
+// provider serving it. It is zero if no provider serves the model.
+func (c *Config) Pricing(model string) Pricing {
+	fullName, provider := c.ResolveModel(model)
+	if provider == nil {
+		return Pricing{}
+	}
+	return provider.Pricing(fullName)
+}
+
+// ContextWindow returns the context window of a model or alias in tokens,
+// from the provider serving it. It is zero if not configured.
+func (c *Config) ContextWindow(model string) int {
+	fullName, provider := c.ResolveModel(model)
+	if provider == nil {
+		return 0
+	}
+	return provider.ContextWindow(fullName)
+}
+
+// ParseTimeout parses a timeout string like "5s" or "1m30s".
+// Returns zero if empty string (no timeout).
+func ParseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: expected format like '5s' or '1m30s'", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %q", s)
+	}
+
+	return d, nil
+}
+
 // Validate validates the configuration and returns an error if invalid.
 func (c *Config) Validate() error {
 	var errs []error
@@ -136,12 +431,91 @@ func (c *Config) Validate() error {
 				errs = append(errs, fmt.Errorf("provider[%d] %q: %w", i, p.Name, err))
 			}
 		}
+
+		if p.MaxRetries < 0 {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: max_retries must not be negative, got %d", i, p.Name, p.MaxRetries))
+		}
+
+		if _, err := ParseTimeout(p.ConnectTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: connect_timeout: %w", i, p.Name, err))
+		}
+
+		if p.Weight < 0 {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: weight must not be negative, got %d", i, p.Name, p.Weight))
+		}
+
+		if _, err := ParseTimeout(p.RequestTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: request_timeout: %w", i, p.Name, err))
+		}
+
+		switch p.RetryJitter {
+		case "", "none", "full", "equal":
+		default:
+			errs = append(errs, fmt.Errorf("provider[%d] %q: retry_jitter must be none, full or equal, got %q", i, p.Name, p.RetryJitter))
+		}
+
+		if _, err := ParseTimeout(p.RetryMaxElapsed); err != nil {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: retry_max_elapsed: %w", i, p.Name, err))
+		}
+
+		if _, err := url.Parse(p.HTTPProxy); err != nil {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: http_proxy: %w", i, p.Name, err))
+		}
+		if _, err := url.Parse(p.HTTPSProxy); err != nil {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: https_proxy: %w", i, p.Name, err))
+		}
+
+		if p.InputCostPer1K < 0 || p.OutputCostPer1K < 0 {
+			errs = append(errs, fmt.Errorf("provider[%d] %q: token costs must not be negative", i, p.Name))
+		}
+		for model, pricing := range p.ModelCosts {
+			if pricing.InputPer1K < 0 || pricing.OutputPer1K < 0 {
+				errs = append(errs, fmt.Errorf("provider[%d] %q: model_costs %q: token costs must not be negative", i, p.Name, model))
+			}
+		}
+		for model, window := range p.ContextWindows {
+			if window <= 0 {
+				errs = append(errs, fmt.Errorf("provider[%d] %q: context_windows %q: must be positive, got %d", i, p.Name, model, window))
+			}
+		}
 	}
 
 	if c.DefaultProvider != "" && len(c.Providers) > 0 && !defaultProviderFound {
 		errs = append(errs, fmt.Errorf("default_provider %q not found in providers list", c.DefaultProvider))
 	}
 
+	if c.DefaultAssistant != "" {
+		if err := assistant.ValidateID(c.DefaultAssistant); err != nil {
+			errs = append(errs, fmt.Errorf("default_assistant %q: %w", c.DefaultAssistant, err))
+		}
+	}
+
+	for _, id := range c.SystemPromptPrefixSkip {
+		if err := assistant.ValidateID(id); err != nil {
+			errs = append(errs, fmt.Errorf("system_prompt_prefix_skip %q: %w", id, err))
+		}
+	}
+
+	if _, err := ParseRateLimit(c.GlobalRateLimit); err != nil {
+		errs = append(errs, fmt.Errorf("global_rate_limit: %w", err))
+	}
+
+	if _, err := ParseTimeout(c.TimestampPrecision); err != nil {
+		errs = append(errs, fmt.Errorf("timestamp_precision: %w", err))
+	}
+
+	if c.ConfirmAbove < 0 {
+		errs = append(errs, fmt.Errorf("confirm_above must not be negative, got %d", c.ConfirmAbove))
+	}
+
+	if c.DefaultParallel < 0 {
+		errs = append(errs, fmt.Errorf("default_parallel must not be negative, got %d", c.DefaultParallel))
+	}
+
+	if c.RejectIf != nil && c.RejectIf.MinLength < 0 {
+		errs = append(errs, fmt.Errorf("reject_if: min_length must not be negative, got %d", c.RejectIf.MinLength))
+	}
+
 	// Validate aliases reference valid model names (optional: just check format)
 	for alias, model := range c.Aliases {
 		if alias == "" {
@@ -158,3 +532,58 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+// Warnings returns non-fatal configuration issues:
+//   - an alias whose key equals a provider model name, which makes
+//     the model unreachable by its own name
+//   - several aliases mapping to the same model
+func (c *Config) Warnings() []string {
+	var warnings []string
+
+	models := make(map[string]string) // model -> provider name
+	for _, p := range c.Providers {
+		for _, m := range p.Models {
+			models[m] = p.Name
+		}
+	}
+
+	// Sort aliases for consistent output
+	aliases := make([]string, 0, len(c.Aliases))
+	for alias := range c.Aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	byModel := make(map[string][]string) // model -> aliases
+	var targets []string
+	for _, alias := range aliases {
+		model := c.Aliases[alias]
+		if provider, ok := models[alias]; ok && model != alias {
+			warnings = append(warnings, fmt.Sprintf(
+				"alias %q shadows model %q of provider %q: requests for it resolve to %q",
+				alias, alias, provider, model))
+		}
+		if _, seen := byModel[model]; !seen {
+			targets = append(targets, model)
+		}
+		byModel[model] = append(byModel[model], alias)
+	}
+
+	for _, model := range targets {
+		if names := byModel[model]; len(names) > 1 {
+			warnings = append(warnings, fmt.Sprintf(
+				"aliases %s all map to model %q", strings.Join(quoteAll(names), ", "), model))
+		}
+	}
+
+	return warnings
+}
+
+// quoteAll returns each string in Go-quoted form.
+func quoteAll(items []string) []string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return quoted
+}
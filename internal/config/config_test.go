@@ -0,0 +1,252 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_QueryWrap(t *testing.T) {
+	cfg := Config{
+		QueryPrefix: "Answer concisely.",
+		QuerySuffix: "Cite sources.",
+		Assistants: map[string]AssistantConfig{
+			"json": {QueryPrefix: "Reply in JSON."},
+		},
+	}
+
+	prefix, suffix := cfg.QueryWrap("other")
+	assert.Equal(t, "Answer concisely.", prefix)
+	assert.Equal(t, "Cite sources.", suffix)
+
+	prefix, suffix = cfg.QueryWrap("json")
+	assert.Equal(t, "Reply in JSON.", prefix)
+	assert.Equal(t, "Cite sources.", suffix, "unset assistant values fall back to the global ones")
+
+	prefix, suffix = (&Config{}).QueryWrap("json")
+	assert.Empty(t, prefix)
+	assert.Empty(t, suffix)
+}
+
+func TestRejectRules_Check(t *testing.T) {
+	rules := &RejectRules{MinLength: 10, Contains: []string{"I cannot", ""}}
+
+	tests := map[string]struct {
+		rules   *RejectRules
+		content string
+		want    string
+	}{
+		"nil rules":            {rules: nil, content: "", want: ""},
+		"empty":                {rules: &RejectRules{}, content: " \n\t", want: "empty response"},
+		"too short":            {rules: rules, content: "  Short.  ", want: "response too short (6 < 10 chars)"},
+		"runes counted":        {rules: rules, content: "Привет, мир", want: ""},
+		"pattern":              {rules: rules, content: "Sorry, i CANNOT help with that.", want: `response contains "I cannot"`},
+		"passes":               {rules: rules, content: "Here is a thorough answer.", want: ""},
+		"length checked first": {rules: rules, content: "I cannot", want: "response too short (8 < 10 chars)"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rules.Check(tc.content))
+		})
+	}
+}
+
+func TestConfig_Warnings(t *testing.T) {
+	providers := []Provider{
+		{Name: "openai", Models: []string{"gpt-4o", "gpt-4o-mini"}},
+		{Name: "anthropic", Models: []string{"claude-sonnet-4"}},
+	}
+
+	tests := map[string]struct {
+		aliases map[string]string
+		want    []string
+	}{
+		"none": {
+			aliases: map[string]string{"4o": "gpt-4o", "sonnet": "claude-sonnet-4"},
+		},
+		"shadowing": {
+			aliases: map[string]string{"gpt-4o": "gpt-4o-mini"},
+			want:    []string{`alias "gpt-4o" shadows model "gpt-4o" of provider "openai": requests for it resolve to "gpt-4o-mini"`},
+		},
+		"identity is not shadowing": {
+			aliases: map[string]string{"gpt-4o": "gpt-4o"},
+		},
+		"same model": {
+			aliases: map[string]string{"sonnet": "claude-sonnet-4", "claude": "claude-sonnet-4", "4o": "gpt-4o"},
+			want:    []string{`aliases "claude", "sonnet" all map to model "claude-sonnet-4"`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{Providers: providers, Aliases: tc.aliases}
+			assert.Equal(t, tc.want, cfg.Warnings())
+		})
+	}
+}
+
+func TestLoadFromFile_QueryWrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".tuna.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`default_provider = "p"
+query_suffix = "Cite sources."
+
+[assistants.json]
+query_prefix = """
+Reply in JSON.
+"""
+
+[[providers]]
+name = "p"
+base_url = "https://example.com/v1"
+api_token = "token"
+`), 0644))
+
+	cfg, err := LoadFromFile(path)
+	require.NoError(t, err)
+	prefix, suffix := cfg.QueryWrap("json")
+	assert.Equal(t, "Reply in JSON.\n", prefix)
+	assert.Equal(t, "Cite sources.", suffix)
+}
+
+func TestLoadResult_MarkdownStyle(t *testing.T) {
+	source := filepath.Join("home", "user", ".config", "tuna", "config.toml")
+	dir := filepath.Dir(source)
+	abs, err := filepath.Abs("style.json")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		style      string
+		deprecated bool
+		expected   string
+	}{
+		"empty":          {"", false, ""},
+		"builtin":        {"dracula", false, "dracula"},
+		"relative file":  {"style.json", false, filepath.Join(dir, "style.json")},
+		"relative path":  {"styles/mine", false, filepath.Join(dir, "styles", "mine")},
+		"absolute path":  {abs, false, abs},
+		"deprecated env": {"style.json", true, "style.json"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := &LoadResult{
+				Config:     &Config{MarkdownStyle: test.style},
+				Source:     source,
+				Deprecated: test.deprecated,
+			}
+			assert.Equal(t, test.expected, result.MarkdownStyle())
+		})
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    time.Duration
+		wantErr string
+	}{
+		"empty":    {in: "", want: 0},
+		"seconds":  {in: "5s", want: 5 * time.Second},
+		"combined": {in: "1m30s", want: 90 * time.Second},
+		"no unit":  {in: "5", wantErr: "invalid timeout"},
+		"zero":     {in: "0s", wantErr: "must be positive"},
+		"negative": {in: "-1s", wantErr: "must be positive"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseTimeout(tc.in)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestProvider_Pricing(t *testing.T) {
+	provider := &Provider{
+		InputCostPer1K:  0.5,
+		OutputCostPer1K: 1.5,
+		ModelCosts: map[string]Pricing{
+			"gpt-4o-mini": {InputPer1K: 0.15, OutputPer1K: 0.6},
+		},
+	}
+
+	tests := map[string]struct {
+		model    string
+		want     Pricing
+		wantCost float64
+	}{
+		"provider prices": {model: "gpt-4o", want: Pricing{InputPer1K: 0.5, OutputPer1K: 1.5}, wantCost: 0.5 + 0.3},
+		"model override":  {model: "gpt-4o-mini", want: Pricing{InputPer1K: 0.15, OutputPer1K: 0.6}, wantCost: 0.15 + 0.12},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pricing := provider.Pricing(tc.model)
+			assert.Equal(t, tc.want, pricing)
+			assert.InDelta(t, tc.wantCost, pricing.Cost(1000, 200), 1e-9)
+		})
+	}
+
+	assert.Zero(t, (&Provider{}).Pricing("any").Cost(1000, 1000), "unpriced providers cost nothing")
+}
+
+func TestProvider_ResolveHeaders(t *testing.T) {
+	t.Setenv("TUNA_TEST_REFERER", "https://example.com")
+
+	tests := map[string]struct {
+		headers map[string]string
+		want    map[string]string
+		err     string
+	}{
+		"none": {},
+		"literal and reference": {
+			headers: map[string]string{"X-Title": "tuna", "HTTP-Referer": "$TUNA_TEST_REFERER"},
+			want:    map[string]string{"X-Title": "tuna", "HTTP-Referer": "https://example.com"},
+		},
+		"unset reference": {
+			headers: map[string]string{"X-Key": "$TUNA_TEST_UNSET"},
+			err:     `header "X-Key": environment variable "TUNA_TEST_UNSET" is not set`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers, err := (&Provider{Headers: tc.headers}).ResolveHeaders()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, headers)
+		})
+	}
+}
+
+func TestConfig_PromptPrefix(t *testing.T) {
+	cfg := &Config{
+		SystemPromptPrefix:     "Never reveal secrets.",
+		SystemPromptPrefixSkip: []string{"raw-bot"},
+	}
+
+	tests := map[string]string{
+		"helper":  "Never reveal secrets.",
+		"raw-bot": "",
+	}
+	for assistantID, want := range tests {
+		t.Run(assistantID, func(t *testing.T) {
+			assert.Equal(t, want, cfg.PromptPrefix(assistantID))
+		})
+	}
+
+	cfg.SystemPromptPrefixSkip = []string{"../escape"}
+	assert.ErrorContains(t, cfg.Validate(), `system_prompt_prefix_skip "../escape"`)
+}
@@ -1,12 +1,16 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	toml "github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -17,6 +21,26 @@ const (
 	GlobalConfigPath = ".config/tuna.toml"
 )
 
+// ConfigFileNames are the candidate names of the project-level
+// configuration file, in priority order. The format is detected by
+// the extension, see LoadFromFile.
+var ConfigFileNames = []string{ConfigFileName, ".tuna.yaml", ".tuna.yml", ".tuna.json"}
+
+// GlobalConfigPaths are the candidate paths of the user-level
+// configuration file relative to the home directory, in priority order.
+var GlobalConfigPaths = []string{GlobalConfigPath, ".config/tuna.yaml", ".config/tuna.yml", ".config/tuna.json"}
+
+// LookupOrder describes where Load looks for a configuration file, as
+// numbered lines in priority order for help and error texts.
+func LookupOrder() string {
+	global := make([]string, len(GlobalConfigPaths))
+	for i, path := range GlobalConfigPaths {
+		global[i] = "~/" + path
+	}
+	return fmt.Sprintf("  1. %s in current directory or parent directories\n  2. %s",
+		strings.Join(ConfigFileNames, ", "), strings.Join(global, ", "))
+}
+
 // Environment variable names for backward compatibility.
 const (
 	EnvAPIToken = "LLM_API_TOKEN"
@@ -38,9 +62,24 @@ type LoadResult struct {
 	Deprecated bool   // True if using deprecated environment variables
 }
 
-// Load loads configuration with priority:
-// 1. .tuna.toml in current/parent directories
-// 2. ~/.config/tuna.toml
+// MarkdownStyle returns the configured markdown style. A relative style
+// file path, i.e. one containing a slash or ending in ".json", is resolved
+// against the directory of the config file, so it does not depend on the
+// working directory; builtin style names are returned as they are.
+func (r *LoadResult) MarkdownStyle() string {
+	style := r.Config.MarkdownStyle
+	if style == "" || r.Deprecated || filepath.IsAbs(style) {
+		return style
+	}
+	if !strings.ContainsAny(style, `/\`) && !strings.EqualFold(filepath.Ext(style), ".json") {
+		return style
+	}
+	return filepath.Join(filepath.Dir(r.Source), style)
+}
+
+// Load loads configuration with priority, see LookupOrder:
+// 1. The first of ConfigFileNames in current/parent directories
+// 2. The first of GlobalConfigPaths in the home directory
 // 3. Fallback to env variables (backward compatibility).
 func Load() (*LoadResult, error) {
 	// Try to find project-level config
@@ -57,19 +96,15 @@ func Load() (*LoadResult, error) {
 	}
 
 	// Try user-level config
-	home, err := os.UserHomeDir()
-	if err == nil {
-		globalPath := filepath.Join(home, GlobalConfigPath)
-		if _, err := os.Stat(globalPath); err == nil {
-			cfg, err := LoadFromFile(globalPath)
-			if err != nil {
-				return nil, err
-			}
-			return &LoadResult{
-				Config: cfg,
-				Source: globalPath,
-			}, nil
+	if globalPath, ok := findGlobalConfigFile(); ok {
+		cfg, err := LoadFromFile(globalPath)
+		if err != nil {
+			return nil, err
 		}
+		return &LoadResult{
+			Config: cfg,
+			Source: globalPath,
+		}, nil
 	}
 
 	// Fallback to environment variables (backward compatibility)
@@ -86,6 +121,8 @@ func Load() (*LoadResult, error) {
 }
 
 // LoadFromFile loads configuration from a specific file.
+// Files ending in .yaml or .yml are parsed as YAML, .json as JSON,
+// and anything else as TOML.
 func LoadFromFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -93,7 +130,7 @@ func LoadFromFile(path string) (*Config, error) {
 	}
 
 	var cfg Config
-	if err := toml.Unmarshal(data, &cfg); err != nil {
+	if err := unmarshal(path, data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
 	}
 
@@ -104,7 +141,45 @@ func LoadFromFile(path string) (*Config, error) {
 	return &cfg, nil
 }
 
-// findConfigFile searches for .tuna.toml up the directory tree.
+// unmarshal decodes a configuration file in the format given by its extension.
+func unmarshal(path string, data []byte, cfg *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".json":
+		return json.Unmarshal(data, cfg)
+	default:
+		return toml.Unmarshal(data, cfg)
+	}
+}
+
+// IsTOML reports whether a configuration file is in TOML format,
+// the only one that can be edited in place.
+func IsTOML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return false
+	}
+	return true
+}
+
+// findGlobalConfigFile returns the first user-level configuration file that exists.
+func findGlobalConfigFile() (string, bool) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	for _, name := range GlobalConfigPaths {
+		path := filepath.Join(home, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// findConfigFile searches for a project-level configuration file up the
+// directory tree. In each directory, ConfigFileNames are tried in order.
 func findConfigFile() (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -113,9 +188,11 @@ func findConfigFile() (string, error) {
 
 	dir := cwd
 	for {
-		configPath := filepath.Join(dir, ConfigFileName)
-		if _, err := os.Stat(configPath); err == nil {
-			return configPath, nil
+		for _, name := range ConfigFileNames {
+			configPath := filepath.Join(dir, name)
+			if _, err := os.Stat(configPath); err == nil {
+				return configPath, nil
+			}
 		}
 
 		parent := filepath.Dir(dir)
@@ -126,19 +203,20 @@ func findConfigFile() (string, error) {
 		dir = parent
 	}
 
-	return "", fmt.Errorf("config file %s not found in %s or parent directories", ConfigFileName, cwd)
+	return "", fmt.Errorf("config file %s not found in %s or parent directories",
+		strings.Join(ConfigFileNames, ", "), cwd)
 }
 
 // loadFromEnv creates a configuration from environment variables for backward compatibility.
 func loadFromEnv() (*Config, error) {
 	token := os.Getenv(EnvAPIToken)
 	if token == "" {
-		return nil, fmt.Errorf("missing %s environment variable and no config file found\n\nCreate a config file (.tuna.toml) or set environment variables:\n  export %s=your-api-token\n  export %s=https://api.example.com/v1", EnvAPIToken, EnvAPIToken, EnvBaseURL)
+		return nil, fmt.Errorf("missing %s environment variable and no config file found\n\nCreate a config file, looked up in this order:\n%s\n\nOr set environment variables:\n  export %s=your-api-token\n  export %s=https://api.example.com/v1", EnvAPIToken, LookupOrder(), EnvAPIToken, EnvBaseURL)
 	}
 
 	baseURL := os.Getenv(EnvBaseURL)
 	if baseURL == "" {
-		return nil, fmt.Errorf("missing %s environment variable and no config file found\n\nCreate a config file (.tuna.toml) or set environment variables:\n  export %s=your-api-token\n  export %s=https://api.example.com/v1", EnvBaseURL, EnvAPIToken, EnvBaseURL)
+		return nil, fmt.Errorf("missing %s environment variable and no config file found\n\nCreate a config file, looked up in this order:\n%s\n\nOr set environment variables:\n  export %s=your-api-token\n  export %s=https://api.example.com/v1", EnvBaseURL, LookupOrder(), EnvAPIToken, EnvBaseURL)
 	}
 
 	// Create an implicit "default" provider from environment variables
@@ -165,17 +243,68 @@ func FindConfigFile() (string, error) {
 	}
 
 	// Try user-level config
-	home, err := os.UserHomeDir()
-	if err == nil {
-		globalPath := filepath.Join(home, GlobalConfigPath)
-		if _, err := os.Stat(globalPath); err == nil {
-			return globalPath, nil
-		}
+	if globalPath, ok := findGlobalConfigFile(); ok {
+		return globalPath, nil
 	}
 
 	return "", ErrNoConfig
 }
 
+// MigrateEnv writes a configuration file at path equivalent to the
+// deprecated environment variables: the base URL is stored as is and
+// the token is referenced via api_token_env, so it never lands on disk.
+// It refuses to run if a configuration file is already in use.
+func MigrateEnv(path string) (*Config, error) {
+	if existing, err := FindConfigFile(); err == nil {
+		return nil, fmt.Errorf("configuration file already exists: %s", existing)
+	}
+
+	cfg, err := loadFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	provider := cfg.Providers[0]
+
+	content := fmt.Sprintf(`# Migrated from %s and %s environment variables
+default_provider = %s
+
+[[providers]]
+name = %s
+base_url = %s
+api_token_env = %s
+`, EnvAPIToken, EnvBaseURL,
+		strconv.Quote(cfg.DefaultProvider),
+		strconv.Quote(provider.Name),
+		strconv.Quote(provider.BaseURL),
+		strconv.Quote(provider.APITokenEnv))
+
+	// Make sure the generated file loads back into the same configuration
+	var migrated Config
+	if err := toml.Unmarshal([]byte(content), &migrated); err != nil {
+		return nil, fmt.Errorf("failed to generate config file: %w", err)
+	}
+	if err := migrated.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config file: %w", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+
+	return &migrated, nil
+}
+
 // DeprecationWarning returns a warning message about deprecated configuration.
 func DeprecationWarning() string {
 	return fmt.Sprintf(`Warning: Using deprecated environment variables (%s, %s).
@@ -190,6 +319,7 @@ Consider creating a configuration file for better flexibility:
   base_url = "$%s"
   api_token_env = "%s"
 
+Run 'tuna config migrate' to create it automatically.
 See documentation for more examples.
 `, EnvAPIToken, EnvBaseURL, EnvBaseURL, EnvAPIToken)
 }
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFromFile_Formats(t *testing.T) {
+	files := map[string]string{
+		".tuna.toml": `default_provider = "openai"
+default_parallel = 4
+
+[aliases]
+4o = "gpt-4o"
+
+[[providers]]
+name = "openai"
+base_url = "https://api.openai.com/v1"
+api_token_env = "OPENAI_API_KEY"
+rate_limit = "60rpm"
+models = ["gpt-4o", "o1"]
+`,
+		".tuna.yaml": `default_provider: openai
+default_parallel: 4
+aliases:
+  4o: gpt-4o
+providers:
+  - name: openai
+    base_url: https://api.openai.com/v1
+    api_token_env: OPENAI_API_KEY
+    rate_limit: 60rpm
+    models: [gpt-4o, o1]
+`,
+		".tuna.json": `{
+  "default_provider": "openai",
+  "default_parallel": 4,
+  "aliases": {"4o": "gpt-4o"},
+  "providers": [{
+    "name": "openai",
+    "base_url": "https://api.openai.com/v1",
+    "api_token_env": "OPENAI_API_KEY",
+    "rate_limit": "60rpm",
+    "models": ["gpt-4o", "o1"]
+  }]
+}`,
+	}
+
+	dir := t.TempDir()
+	configs := make(map[string]*Config, len(files))
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		cfg, err := LoadFromFile(path)
+		require.NoError(t, err, name)
+		configs[name] = cfg
+	}
+
+	expected := configs[".tuna.toml"]
+	assert.Equal(t, "openai", expected.DefaultProvider)
+	assert.Equal(t, []string{"gpt-4o", "o1"}, expected.Providers[0].Models)
+	assert.Equal(t, expected, configs[".tuna.yaml"])
+	assert.Equal(t, expected, configs[".tuna.json"])
+}
+
+func TestFindConfigFile_Priority(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	t.Chdir(sub)
+
+	for _, name := range []string{".tuna.json", ".tuna.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644))
+	}
+	path, err := findConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".tuna.yaml"), path, "found up the tree, in ConfigFileNames order")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), nil, 0644))
+	path, err = findConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ConfigFileName), path)
+}
+
+func TestLookupOrder(t *testing.T) {
+	order := LookupOrder()
+	project, global, ok := strings.Cut(order, "\n")
+	require.True(t, ok)
+	for _, name := range ConfigFileNames {
+		assert.Contains(t, project, name)
+	}
+	for _, path := range GlobalConfigPaths {
+		assert.Contains(t, global, "~/"+path)
+	}
+	assert.Less(t, strings.Index(project, ".tuna.toml"), strings.Index(project, ".tuna.json"), "listed in priority order")
+
+	t.Setenv(EnvAPIToken, "")
+	_, err := loadFromEnv()
+	assert.ErrorContains(t, err, order, "the error names every file Load looks for")
+}
+
+func TestMigrateEnv(t *testing.T) {
+	tests := map[string]struct {
+		token, baseURL string
+		existing       string
+		wantErr        string
+	}{
+		"migrated": {
+			token:   "secret-token",
+			baseURL: "https://llm.example.com/v1",
+		},
+		"existing project config": {
+			token:    "secret-token",
+			baseURL:  "https://llm.example.com/v1",
+			existing: ".tuna.yaml",
+			wantErr:  "configuration file already exists",
+		},
+		"existing global config": {
+			token:    "secret-token",
+			baseURL:  "https://llm.example.com/v1",
+			existing: filepath.Join("home", GlobalConfigPaths[0]),
+			wantErr:  "configuration file already exists",
+		},
+		"missing base URL": {
+			token:   "secret-token",
+			wantErr: "missing " + EnvBaseURL,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			t.Setenv("HOME", filepath.Join(dir, "home"))
+			t.Setenv(EnvAPIToken, tc.token)
+			t.Setenv(EnvBaseURL, tc.baseURL)
+			if tc.existing != "" {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, tc.existing)), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, tc.existing), []byte("{}"), 0644))
+			}
+
+			path := filepath.Join(dir, ConfigFileName)
+			migrated, err := MigrateEnv(path)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				assert.NoFileExists(t, path)
+				return
+			}
+			require.NoError(t, err)
+
+			cfg, err := LoadFromFile(path)
+			require.NoError(t, err)
+			require.NoError(t, cfg.Validate())
+			assert.Equal(t, migrated, cfg)
+			assert.Equal(t, "default", cfg.DefaultProvider)
+			require.Len(t, cfg.Providers, 1)
+			assert.Equal(t, tc.baseURL, cfg.Providers[0].BaseURL)
+			assert.Equal(t, EnvAPIToken, cfg.Providers[0].APITokenEnv)
+
+			token, err := cfg.Providers[0].ResolveAPIToken()
+			require.NoError(t, err)
+			assert.Equal(t, tc.token, token)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), tc.token, "the token is not written to disk")
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// ProviderSync describes how a provider's configuration differs
+// from what the provider reports.
+type ProviderSync struct {
+	Models    []string // Configured models the provider still serves
+	Stale     []string // Configured models the provider no longer serves
+	New       []string // Served models that are not configured
+	RateLimit string   // Suggested rate_limit, empty if unknown
+}
+
+// Changed reports whether applying the sync would modify the provider.
+func (s ProviderSync) Changed(p Provider) bool {
+	return s.SyncedModels(p) != nil || (s.RateLimit != "" && s.RateLimit != p.RateLimit)
+}
+
+// SyncedModels returns the models of the provider after the sync: the
+// configured models still served, followed by the newly served ones.
+// It returns nil if the models are unchanged, and for a provider without
+// models, which serves everything and is left that way.
+func (s ProviderSync) SyncedModels(p Provider) []string {
+	if len(p.Models) == 0 || (len(s.Stale) == 0 && len(s.New) == 0) {
+		return nil
+	}
+	return append(append([]string{}, s.Models...), s.New...)
+}
+
+// SyncProvider compares the provider's models with the live catalog.
+// requestsPerMinute is the observed request limit (0 if unknown).
+// Configured order is preserved; a provider without models serves
+// everything and is never reported as stale.
+func SyncProvider(p Provider, live []string, requestsPerMinute int) ProviderSync {
+	var sync ProviderSync
+
+	served := make(map[string]bool, len(live))
+	for _, m := range live {
+		served[m] = true
+	}
+	configured := make(map[string]bool, len(p.Models))
+	for _, m := range p.Models {
+		configured[m] = true
+		if served[m] {
+			sync.Models = append(sync.Models, m)
+		} else {
+			sync.Stale = append(sync.Stale, m)
+		}
+	}
+	for _, m := range live {
+		if !configured[m] {
+			sync.New = append(sync.New, m)
+		}
+	}
+
+	if requestsPerMinute > 0 {
+		sync.RateLimit = fmt.Sprintf("%drpm", requestsPerMinute)
+	}
+
+	return sync
+}
+
+var (
+	tableHeaderRegex  = regexp.MustCompile(`^\s*\[`)
+	providerNameRegex = regexp.MustCompile(`^\s*name\s*=\s*["']([^"']*)["']`)
+	modelsKeyRegex    = regexp.MustCompile(`^\s*models\s*=`)
+	rateLimitKeyRegex = regexp.MustCompile(`^\s*rate_limit\s*=`)
+)
+
+// UpdateProvider rewrites the models and rate_limit keys of the named
+// provider in the config file at path, leaving the rest of the file,
+// including comments, untouched. A nil models or empty rateLimit keeps
+// the current value. Only TOML files are supported.
+func UpdateProvider(path, name string, models []string, rateLimit string) error {
+	if !IsTOML(path) {
+		return fmt.Errorf("cannot update %s: only TOML config files can be edited in place", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	lines := strings.Split(string(data), "\n")
+
+	// Locate the provider's [[providers]] table
+	start, end := -1, len(lines)
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "[[providers]]" {
+			continue
+		}
+		j := i + 1
+		for j < len(lines) && !tableHeaderRegex.MatchString(lines[j]) {
+			j++
+		}
+		for _, line := range lines[i+1 : j] {
+			if m := providerNameRegex.FindStringSubmatch(line); m != nil && m[1] == name {
+				start, end = i, j
+			}
+		}
+		if start >= 0 {
+			break
+		}
+		i = j - 1
+	}
+	if start < 0 {
+		return fmt.Errorf("provider %q not found in %s", name, path)
+	}
+
+	block := lines[start+1 : end]
+	if models != nil {
+		quoted := make([]string, len(models))
+		for i, m := range models {
+			quoted[i] = strconv.Quote(m)
+		}
+		block = setKey(block, modelsKeyRegex, "models = ["+strings.Join(quoted, ", ")+"]")
+	}
+	if rateLimit != "" {
+		block = setKey(block, rateLimitKeyRegex, "rate_limit = "+strconv.Quote(rateLimit))
+	}
+
+	updated := append(append(append([]string{}, lines[:start+1]...), block...), lines[end:]...)
+	content := strings.Join(updated, "\n")
+
+	// Make sure the edited file still loads into a valid configuration
+	var cfg Config
+	if err := toml.Unmarshal([]byte(content), &cfg); err != nil {
+		return fmt.Errorf("failed to update config file: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// setKey replaces the key matched by key in a table body with line,
+// including the continuation lines of a multi-line array, or inserts
+// line after the name key if the key is absent.
+func setKey(block []string, key *regexp.Regexp, line string) []string {
+	for i, l := range block {
+		if !key.MatchString(l) {
+			continue
+		}
+		j := i + 1
+		if depth := strings.Count(l, "[") - strings.Count(l, "]"); depth > 0 {
+			for j < len(block) && depth > 0 {
+				depth += strings.Count(block[j], "[") - strings.Count(block[j], "]")
+				j++
+			}
+		}
+		return append(append(append([]string{}, block[:i]...), line), block[j:]...)
+	}
+
+	at := 0
+	for i, l := range block {
+		if providerNameRegex.MatchString(l) {
+			at = i + 1
+			break
+		}
+	}
+	return append(append(append([]string{}, block[:at]...), line), block[at:]...)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncProvider(t *testing.T) {
+	p := Provider{Name: "openai", Models: []string{"gpt-4o", "gpt-3.5"}, RateLimit: "10rpm"}
+
+	sync := SyncProvider(p, []string{"o1", "gpt-4o"}, 500)
+	assert.Equal(t, []string{"gpt-4o"}, sync.Models)
+	assert.Equal(t, []string{"gpt-3.5"}, sync.Stale)
+	assert.Equal(t, []string{"o1"}, sync.New)
+	assert.Equal(t, "500rpm", sync.RateLimit)
+	assert.Equal(t, []string{"gpt-4o", "o1"}, sync.SyncedModels(p), "synced both ways")
+	assert.True(t, sync.Changed(p))
+
+	added := SyncProvider(Provider{Models: []string{"gpt-4o"}}, []string{"gpt-4o", "o1"}, 0)
+	assert.Equal(t, []string{"gpt-4o", "o1"}, added.SyncedModels(Provider{Models: []string{"gpt-4o"}}))
+
+	inSync := SyncProvider(p, []string{"gpt-4o", "gpt-3.5"}, 10)
+	assert.Nil(t, inSync.SyncedModels(p))
+	assert.False(t, inSync.Changed(p))
+
+	all := Provider{Name: "local"}
+	serving := SyncProvider(all, []string{"llama"}, 0)
+	assert.Nil(t, serving.SyncedModels(all), "providers without models serve everything")
+	assert.False(t, serving.Changed(all))
+}
+
+func TestUpdateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".tuna.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`default_provider = "openai"
+
+[[providers]]
+name = "openai"
+base_url = "https://api.openai.com/v1"
+api_token = "token"
+# Keep in sync with the account
+models = [
+  "gpt-4o",
+  "gpt-3.5",
+]
+
+[[providers]]
+name = "other"
+base_url = "https://example.com/v1"
+api_token = "token"
+models = ["m"]
+`), 0644))
+
+	require.NoError(t, UpdateProvider(path, "openai", []string{"gpt-4o", "o1"}, "500rpm"))
+
+	cfg, err := LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o", "o1"}, cfg.Providers[0].Models)
+	assert.Equal(t, "500rpm", cfg.Providers[0].RateLimit)
+	assert.Equal(t, []string{"m"}, cfg.Providers[1].Models)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Keep in sync with the account")
+
+	assert.ErrorContains(t, UpdateProvider(path, "missing", nil, "1rpm"), `provider "missing" not found`)
+}
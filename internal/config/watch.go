@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch calls onChange every time the config file at path is written,
+// created or replaced, until ctx is done. It watches the parent directory,
+// so editors that save by renaming a temporary file are noticed too.
+// Watch blocks; watcher errors are passed to onError, which may be nil.
+func Watch(ctx context.Context, path string, onChange func(), onError func(error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	const changed = fsnotify.Write | fsnotify.Create | fsnotify.Rename
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path && event.Op&changed != 0 {
+				onChange()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrorLogFileName is the log of failed tasks in the plan output directory.
+const ErrorLogFileName = "errors.log"
+
+// TaskFailure records a failed task for the error log.
+type TaskFailure struct {
+	Model    string
+	QueryID  string
+	Err      error
+	FailedAt time.Time
+}
+
+// writeErrorLog updates the error log in outputDir and returns its path.
+// Entries of tasks that ran are replaced by the failures of the current
+// run, while entries of other tasks, e.g. ones left out by --model,
+// --query or --continue, are kept. Once no entries are left, the log is
+// removed and the path is empty.
+func writeErrorLog(outputDir string, ran map[task]bool, failures []TaskFailure) (string, error) {
+	path := filepath.Join(outputDir, ErrorLogFileName)
+
+	var sb strings.Builder
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read error log: %w", err)
+	}
+	for line := range strings.Lines(string(data)) {
+		if t, ok := parseErrorLogTask(line); ok && !ran[t] {
+			sb.WriteString(line)
+		}
+	}
+	for _, f := range failures {
+		fmt.Fprintf(&sb, "%s model=%s query=%s: %v\n",
+			f.FailedAt.Format(time.RFC3339), f.Model, f.QueryID, singleLine(f.Err.Error()))
+	}
+
+	if sb.Len() == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to remove error log: %w", err)
+		}
+		return "", nil
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write error log: %w", err)
+	}
+	return path, nil
+}
+
+// parseErrorLogTask returns the task of an error log line,
+// "<time> model=<model> query=<query>: <error>".
+func parseErrorLogTask(line string) (task, bool) {
+	_, rest, ok := strings.Cut(line, " model=")
+	if !ok {
+		return task{}, false
+	}
+	model, rest, ok := strings.Cut(rest, " query=")
+	if !ok {
+		return task{}, false
+	}
+	queryID, _, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return task{}, false
+	}
+	return task{model: model, queryID: queryID}, true
+}
+
+// singleLine joins the lines of a multi-line error message, so that
+// every log entry is a single line.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
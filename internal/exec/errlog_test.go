@@ -0,0 +1,54 @@
+package exec
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteErrorLog(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	path, err := writeErrorLog(dir, map[task]bool{{"a", "q1.md"}: true, {"b", "q1.md"}: true}, []TaskFailure{
+		{Model: "a", QueryID: "q1.md", Err: errors.New("timeout"), FailedAt: at},
+		{Model: "b", QueryID: "q1.md", Err: errors.New("bad\ngateway"), FailedAt: at},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ErrorLogFileName), path)
+	assertLog(t, path,
+		"2025-01-02T03:04:05Z model=a query=q1.md: timeout\n"+
+			"2025-01-02T03:04:05Z model=b query=q1.md: bad gateway\n")
+
+	// A filtered run succeeding for model a keeps the failure of model b
+	path, err = writeErrorLog(dir, map[task]bool{{"a", "q1.md"}: true}, nil)
+	require.NoError(t, err)
+	assertLog(t, path, "2025-01-02T03:04:05Z model=b query=q1.md: bad gateway\n")
+
+	// A failure of a task that ran replaces its entry
+	path, err = writeErrorLog(dir, map[task]bool{{"b", "q1.md"}: true}, []TaskFailure{
+		{Model: "b", QueryID: "q1.md", Err: errors.New("again"), FailedAt: at.Add(time.Hour)},
+	})
+	require.NoError(t, err)
+	assertLog(t, path, "2025-01-02T04:04:05Z model=b query=q1.md: again\n")
+
+	// Once every task succeeded, the log is removed
+	path, err = writeErrorLog(dir, map[task]bool{{"b", "q1.md"}: true}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+	_, err = os.Stat(filepath.Join(dir, ErrorLogFileName))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func assertLog(t *testing.T, path, expected string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
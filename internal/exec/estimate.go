@@ -0,0 +1,168 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+// charsPerToken is the rough number of characters per token used
+// to approximate token counts offline.
+const charsPerToken = 4
+
+// TaskEstimate holds the input token count measured for a task.
+type TaskEstimate struct {
+	Model       string
+	QueryID     string
+	InputTokens int
+	Cost        float64 // Cost of the measuring request
+	Err         error
+
+	// OutputTokens is the expected response length (0 = use the default).
+	OutputTokens int
+}
+
+// ApproxTokens approximates the token count of text without a tokenizer.
+func ApproxTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// ApproxEstimate approximates the token usage of every task offline:
+// input tokens from the length of the prompts, output tokens from the
+// request's max tokens, i.e. the worst case. Tasks whose query cannot
+// be prepared are recorded with TaskEstimate.Err, as Execute fails
+// only those tasks. With Options.Continue, tasks completed in an
+// earlier run are left out, as Execute does not send them again.
+func (e *Executor) ApproxEstimate() ([]TaskEstimate, error) {
+	basePrompt, err := e.systemPrompt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile system prompt: %w", err)
+	}
+	queries := e.prepareQueries(basePrompt)
+
+	var pending map[task]bool
+	if e.options.Continue {
+		pending = e.pendingTasks(e.store(NewResponseWriter(e.assistantDir, e.plan.DirName())))
+	}
+
+	estimates := make([]TaskEstimate, 0, len(e.plan.Assistant.LLM.Models)*len(e.plan.Queries))
+	for _, model := range e.plan.Assistant.LLM.Models {
+		for _, q := range e.plan.Queries {
+			if pending != nil && !pending[task{model, q.ID}] {
+				continue
+			}
+			query := queries[q.ID]
+			if query.err != nil {
+				estimates = append(estimates, TaskEstimate{Model: model, QueryID: q.ID, Err: query.err})
+				continue
+			}
+			request := e.request(model, query)
+			estimates = append(estimates, TaskEstimate{
+				Model:        model,
+				QueryID:      q.ID,
+				InputTokens:  ApproxTokens(request.SystemPrompt) + ApproxTokens(request.UserMessage),
+				OutputTokens: request.MaxTokens,
+			})
+		}
+	}
+	return estimates, nil
+}
+
+// Estimate sends every request of the plan with max_tokens=1, so the
+// provider reports the exact input token count at minimal cost.
+// Nothing is written to the plan output. Per-task failures are
+// recorded in TaskEstimate.Err.
+func (e *Executor) Estimate(ctx context.Context) ([]TaskEstimate, error) {
+	requests, err := e.Requests()
+	if err != nil {
+		return nil, err
+	}
+
+	estimates := make([]TaskEstimate, 0, len(requests))
+	for _, task := range requests {
+		estimate := TaskEstimate{Model: task.Model, QueryID: task.QueryID}
+
+		req := task.Request
+		req.MaxTokens = 1
+		resp, err := e.llmClient.Chat(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			estimate.Err = err
+		} else {
+			estimate.InputTokens = resp.PromptTokens
+			estimate.Cost = resp.Cost
+		}
+		estimates = append(estimates, estimate)
+	}
+
+	return estimates, nil
+}
+
+// CostProjection is the projected token usage and cost of a full run.
+type CostProjection struct {
+	Tasks        int // Tasks with a measured input token count
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+}
+
+// ProjectCost projects the cost of running the measured tasks, assuming
+// responses without an expected length are outputTokens long. Pricing
+// returns the token prices of a model. Failed estimates are left out.
+func ProjectCost(estimates []TaskEstimate, outputTokens int, pricing func(model string) config.Pricing) CostProjection {
+	var projection CostProjection
+	for _, estimate := range estimates {
+		if estimate.Err != nil {
+			continue
+		}
+		output := outputTokens
+		if estimate.OutputTokens > 0 {
+			output = estimate.OutputTokens
+		}
+		projection.Tasks++
+		projection.InputTokens += estimate.InputTokens
+		projection.OutputTokens += output
+		projection.Cost += pricing(estimate.Model).Cost(estimate.InputTokens, output)
+	}
+	return projection
+}
+
+// ContextOverflow is a task whose request may not fit the context window
+// of its model: the input and the maximum response length together
+// exceed it.
+type ContextOverflow struct {
+	Model   string
+	QueryID string
+	Tokens  int // Approximate input tokens plus max tokens
+	Window  int
+}
+
+// CheckContext returns the tasks whose estimated tokens exceed the
+// context window of their model. Window returns the context window of
+// a model, or zero if it is unknown; such models and failed estimates
+// are not checked.
+func CheckContext(estimates []TaskEstimate, window func(model string) int) []ContextOverflow {
+	var overflows []ContextOverflow
+	for _, estimate := range estimates {
+		if estimate.Err != nil {
+			continue
+		}
+		limit := window(estimate.Model)
+		if limit <= 0 {
+			continue
+		}
+		if tokens := estimate.InputTokens + estimate.OutputTokens; tokens > limit {
+			overflows = append(overflows, ContextOverflow{
+				Model:   estimate.Model,
+				QueryID: estimate.QueryID,
+				Tokens:  tokens,
+				Window:  limit,
+			})
+		}
+	}
+	return overflows
+}
@@ -0,0 +1,63 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+func TestCheckContext(t *testing.T) {
+	window := func(model string) int {
+		if model == "small" {
+			return 100
+		}
+		return 0
+	}
+
+	estimates := []TaskEstimate{
+		{Model: "small", QueryID: "fits.md", InputTokens: 60, OutputTokens: 40},
+		{Model: "small", QueryID: "over.md", InputTokens: 61, OutputTokens: 40},
+		{Model: "small", QueryID: "broken.md", Err: os.ErrNotExist},
+		{Model: "unknown", QueryID: "over.md", InputTokens: 1000, OutputTokens: 1000},
+	}
+
+	overflows := CheckContext(estimates, window)
+	assert.Equal(t, []ContextOverflow{
+		{Model: "small", QueryID: "over.md", Tokens: 101, Window: 100},
+	}, overflows)
+}
+
+func TestApproxEstimate(t *testing.T) {
+	assistantDir := t.TempDir()
+	inputDir := filepath.Join(assistantDir, "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "text.md"), []byte(strings.Repeat("a", 40)), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "binary.md"), []byte{0, 1, 2}, 0644))
+
+	p := &plan.Plan{
+		PlanID: "plan",
+		Assistant: plan.Assistant{
+			SystemPrompt: strings.Repeat("s", 20),
+			LLM:          plan.LLM{Models: []string{"model"}, MaxTokens: 50},
+		},
+		Queries: []plan.Query{{ID: "text.md"}, {ID: "binary.md"}, {ID: "missing.md"}},
+	}
+
+	estimates, err := New(p, assistantDir, nil, Options{}).ApproxEstimate()
+	require.NoError(t, err, "unreadable queries must not fail the estimate")
+	require.Len(t, estimates, 3)
+
+	assert.Equal(t, "text.md", estimates[0].QueryID)
+	assert.NoError(t, estimates[0].Err)
+	assert.Equal(t, 15, estimates[0].InputTokens)
+	assert.Equal(t, 50, estimates[0].OutputTokens)
+
+	assert.Error(t, estimates[1].Err)
+	assert.Error(t, estimates[2].Err)
+}
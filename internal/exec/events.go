@@ -0,0 +1,162 @@
+package exec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event types written to the event log in addition to progress events.
+const (
+	EventTypeRunStart = "run_start"
+	EventTypeRunEnd   = "run_end"
+)
+
+// String returns the event log name of the progress event type.
+func (t ProgressEventType) String() string {
+	switch t {
+	case EventTaskStart:
+		return "task_start"
+	case EventTaskDone:
+		return "task_done"
+	case EventTaskError:
+		return "task_error"
+	case EventTaskSkipped:
+		return "task_skipped"
+	case EventTaskOutput:
+		return "task_output"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// Event is a single record of the event log.
+type Event struct {
+	Time         time.Time `json:"time"`
+	Type         string    `json:"type"`
+	PlanID       string    `json:"plan_id"`
+	Model        string    `json:"model,omitempty"`
+	Provider     string    `json:"provider,omitempty"`
+	QueryID      string    `json:"query_id,omitempty"`
+	PromptTokens int       `json:"prompt_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	DurationMS   int64     `json:"duration_ms,omitempty"`
+	Error        string    `json:"error,omitempty"`
+
+	// Run totals (run_start and run_end only)
+	Models  int `json:"models,omitempty"`
+	Queries int `json:"queries,omitempty"`
+	Results int `json:"results,omitempty"`
+	Errors  int `json:"errors,omitempty"`
+}
+
+// EventLog writes execution events to a JSON Lines file.
+// Writes are unbuffered, so a crash preserves all events logged so far.
+type EventLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	planID string
+	err    error // First write error, reported by Close
+}
+
+// NewEventLog creates (or truncates) the event log file at path.
+func NewEventLog(path, planID string) (*EventLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return &EventLog{file: file, planID: planID}, nil
+}
+
+// RunStart records the start of a plan execution.
+func (l *EventLog) RunStart(models, queries int) {
+	l.write(Event{
+		Type:    EventTypeRunStart,
+		Models:  models,
+		Queries: queries,
+	})
+}
+
+// RunEnd records the end of a plan execution with totals from the summary.
+// Summary may be nil if execution failed before producing one.
+func (l *EventLog) RunEnd(summary *ExecutionSummary, err error) {
+	event := Event{Type: EventTypeRunEnd}
+	if summary != nil {
+		event.Models = summary.TotalModels
+		event.Queries = summary.TotalQueries
+		event.Results = len(summary.Results)
+		event.Errors = len(summary.Errors)
+		event.PromptTokens = summary.TotalTokens.Prompt
+		event.OutputTokens = summary.TotalTokens.Output
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	l.write(event)
+}
+
+// Progress records a progress event. It matches the ProgressCallback signature.
+// Output events are not recorded; the response files hold the content.
+func (l *EventLog) Progress(event ProgressEvent) {
+	if event.Type == EventTaskOutput {
+		return
+	}
+	record := Event{
+		Type:         event.Type.String(),
+		Model:        event.Model,
+		Provider:     event.Provider,
+		QueryID:      event.QueryID,
+		PromptTokens: event.Tokens.Prompt,
+		OutputTokens: event.Tokens.Output,
+		DurationMS:   event.Duration.Milliseconds(),
+	}
+	if event.Err != nil {
+		record.Error = event.Err.Error()
+	}
+	l.write(record)
+}
+
+// Wrap returns a callback that logs each event before passing it to next.
+// A nil EventLog returns next unchanged.
+func (l *EventLog) Wrap(next ProgressCallback) ProgressCallback {
+	if l == nil {
+		return next
+	}
+	return func(event ProgressEvent) {
+		l.Progress(event)
+		if next != nil {
+			next(event)
+		}
+	}
+}
+
+// Close closes the file and returns the first write error, if any.
+func (l *EventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.file.Close(); err != nil && l.err == nil {
+		l.err = err
+	}
+	return l.err
+}
+
+// write appends a single JSON line to the log.
+func (l *EventLog) write(event Event) {
+	event.Time = time.Now()
+	event.PlanID = l.planID
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(append(data, '\n')); err != nil && l.err == nil {
+		l.err = fmt.Errorf("failed to write events file: %w", err)
+	}
+}
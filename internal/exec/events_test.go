@@ -0,0 +1,91 @@
+package exec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvents parses the JSON Lines event log at path.
+func readEvents(t *testing.T, path string) []Event {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestEventLog(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"m"}, "good.md", "fail.md")
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	log, err := NewEventLog(path, p.PlanID)
+	require.NoError(t, err)
+
+	log.RunStart(1, 2)
+	summary, err := New(p, assistantDir, failingClient{ChatClient: &roundRobinClient{providers: []string{"provider"}}, fail: "fail"}, Options{OnProgress: log.Wrap(nil)}).Execute(context.Background())
+	require.NoError(t, err)
+	log.RunEnd(summary, nil)
+
+	// Every event is on disk before the log is closed
+	events := readEvents(t, path)
+	require.NoError(t, log.Close())
+
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+		assert.Equal(t, "plan", event.PlanID)
+		assert.False(t, event.Time.IsZero())
+	}
+	require.Len(t, events, 6)
+	assert.Equal(t, EventTypeRunStart, types[0])
+	assert.ElementsMatch(t, []string{"task_start", "task_start", "task_done", "task_error"}, types[1:5])
+	assert.Equal(t, EventTypeRunEnd, types[5])
+
+	for _, event := range events[1:5] {
+		switch event.Type {
+		case "task_done":
+			assert.Equal(t, "good.md", event.QueryID)
+			assert.Equal(t, 1, event.OutputTokens)
+		case "task_error":
+			assert.Equal(t, "fail.md", event.QueryID)
+			assert.Contains(t, event.Error, "connection reset")
+		}
+	}
+
+	start, end := events[0], events[5]
+	assert.Equal(t, 1, start.Models)
+	assert.Equal(t, 2, start.Queries)
+	assert.Equal(t, 1, end.Results)
+	assert.Equal(t, 1, end.Errors)
+	assert.Equal(t, 10, end.PromptTokens)
+	assert.Equal(t, 1, end.OutputTokens)
+}
+
+func TestEventLog_Wrap(t *testing.T) {
+	var nilLog *EventLog
+	called := false
+	nilLog.Wrap(func(ProgressEvent) { called = true })(ProgressEvent{})
+	assert.True(t, called, "a nil log passes events through")
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	log, err := NewEventLog(path, "plan")
+	require.NoError(t, err)
+	log.Wrap(nil)(ProgressEvent{Type: EventTaskOutput, Output: "chunk"})
+	require.NoError(t, log.Close())
+	assert.Empty(t, readEvents(t, path), "output events are not recorded")
+}
@@ -2,27 +2,38 @@ package exec
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"go.octolab.org/toolset/tuna/internal/config"
 	"go.octolab.org/toolset/tuna/internal/llm"
 	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/response"
 )
 
 // ProgressCallback is called during execution to report progress.
+// Calls are serialized, even when tasks run in parallel.
 type ProgressCallback func(event ProgressEvent)
 
 // ProgressEvent represents an execution progress event.
 type ProgressEvent struct {
 	Type     ProgressEventType
 	Model    string
+	Provider string // Empty if the client does not resolve providers
 	QueryID  string
 	Tokens   TokenUsage
+	Cost     float64
 	Duration time.Duration
 	Err      error
+	Output   string // Content delta of EventTaskOutput
 }
 
 // ProgressEventType indicates the type of progress event.
@@ -32,6 +43,8 @@ const (
 	EventTaskStart ProgressEventType = iota
 	EventTaskDone
 	EventTaskError
+	EventTaskSkipped // Completed in an earlier run, see Options.Continue
+	EventTaskOutput  // Response content received, see Options.LiveOutput
 )
 
 // TokenUsage holds token counts for prompt and output.
@@ -42,12 +55,70 @@ type TokenUsage struct {
 
 // Options holds execution options.
 type Options struct {
-	DryRun   bool
+	DryRun bool
+	// Parallel is the number of tasks run at a time (less than 1 = 1).
+	// Tasks start in plan order; results are summarized in plan order.
 	Parallel int
-	Continue bool
+	// Continue skips tasks whose response already has execution metadata,
+	// resuming an interrupted run.
+	Continue   bool
 	OnProgress ProgressCallback
+
+	// MaxTokensPerModel overrides the plan's max_tokens for listed models,
+	// keyed by plan model; see MatchModels.
+	MaxTokensPerModel map[string]int
+
+	// RejectIf marks responses failing these rules as errors (nil = accept all).
+	RejectIf *config.RejectRules
+	// RetryRejected is how many times a rejected response is re-requested.
+	RetryRejected int
+
+	// SkipUnchanged reuses an existing response from any plan of the assistant
+	// whose request_hash matches, instead of sending the request again.
+	SkipUnchanged bool
+
+	// Force overwrites responses pinned in tuna view.
+	Force bool
+
+	// WaitLock waits for another exec of the same plan to finish
+	// instead of failing with ErrLocked.
+	WaitLock bool
+
+	// MaxDurationPerTask caps the wall time of a single task, including
+	// retries; a task exceeding it fails with ErrTaskTimeout (0 = no limit).
+	MaxDurationPerTask time.Duration
+
+	// Store persists responses (nil = files in the plan output directory).
+	// The lock and usage ledger always live in the output directory.
+	Store ResponseStore
+
+	// TimestampPrecision truncates executed_at of written responses
+	// (0 = response.DefaultTimestampPrecision).
+	TimestampPrecision time.Duration
+
+	// ShuffleQueries runs the queries of every model in a different,
+	// random order, to detect order-dependent results such as prompt
+	// caching artifacts. ShuffleSeed makes the orders reproducible.
+	ShuffleQueries bool
+	ShuffleSeed    uint64
+
+	// StreamToDisk streams responses into a partial file next to the
+	// response file as they are generated, so an interrupted run keeps
+	// what was received. It requires a streaming client and the default
+	// file store; see PartialSuffix.
+	StreamToDisk bool
+	// LiveOutput streams responses and reports their content as it
+	// arrives in EventTaskOutput events. It requires a streaming client;
+	// otherwise no such events are sent.
+	LiveOutput bool
 }
 
+// ErrRejected is returned when a response fails the configured post-filters.
+var ErrRejected = errors.New("response rejected")
+
+// ErrTaskTimeout is returned when a task exceeds Options.MaxDurationPerTask.
+var ErrTaskTimeout = errors.New("task timed out")
+
 // Result holds execution result for a single query-model pair.
 type Result struct {
 	Response     string
@@ -56,6 +127,22 @@ type Result struct {
 	OutputPath   string // Path where response was saved
 	PromptTokens int
 	OutputTokens int
+	Chars        int
+	Words        int
+	Flagged      bool   // Query was flagged by moderation and not sent to the model
+	Skipped      bool   // Request unchanged since a previous run; response reused
+	Pinned       bool   // Existing response is pinned and was kept
+	Resumed      bool   // Completed in an earlier run and skipped by Continue
+	Provider     string // Served the request; empty if the client does not resolve providers
+	FinishReason string // Why generation stopped, as reported by the provider
+
+	// RateLimitWait is time spent waiting for the rate limiter,
+	// summed over retries of rejected responses.
+	RateLimitWait time.Duration
+	// Cost is the cost of the requests made, summed the same way.
+	Cost float64
+	// Raw is the provider's response body, if raw capture is enabled.
+	Raw json.RawMessage
 }
 
 // ExecutionSummary holds results for the entire plan execution.
@@ -67,7 +154,47 @@ type ExecutionSummary struct {
 		Prompt int
 		Output int
 	}
-	Errors []error
+	TotalCost float64 // From configured token prices
+	// CumulativeTokens includes usage of tasks completed in earlier,
+	// possibly interrupted, runs of the same plan.
+	CumulativeTokens TokenUsage
+	Errors           []error
+	Providers        map[string]ProviderSummary // Per-provider subtotals, keyed by provider
+
+	// ErrorLog is the path of the failed task log (empty if no task failed).
+	ErrorLog string
+}
+
+// ProviderSummary holds execution subtotals for a single provider.
+type ProviderSummary struct {
+	Provider string
+	Results  int
+	Errors   int
+	Tokens   TokenUsage
+}
+
+// addProvider adds delta to the subtotals of its provider.
+func (s *ExecutionSummary) addProvider(delta ProviderSummary) {
+	if s.Providers == nil {
+		s.Providers = make(map[string]ProviderSummary)
+	}
+	subtotal := s.Providers[delta.Provider]
+	subtotal.Provider = delta.Provider
+	subtotal.Results += delta.Results
+	subtotal.Errors += delta.Errors
+	subtotal.Tokens.Prompt += delta.Tokens.Prompt
+	subtotal.Tokens.Output += delta.Tokens.Output
+	s.Providers[delta.Provider] = subtotal
+}
+
+// ProviderNames returns the providers with subtotals, sorted by name.
+func (s *ExecutionSummary) ProviderNames() []string {
+	names := make([]string, 0, len(s.Providers))
+	for name := range s.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // Executor handles plan execution.
@@ -76,6 +203,9 @@ type Executor struct {
 	assistantDir string
 	llmClient    llm.ChatClient
 	options      Options
+	previous     map[string]string // request hash -> existing response path
+	prompt       *string           // Cached system prompt, see systemPrompt
+	progressMu   sync.Mutex        // Serializes OnProgress calls of parallel tasks
 }
 
 // New creates a new executor for the given plan.
@@ -99,17 +229,24 @@ func (e *Executor) DryRun() string {
 	for _, model := range e.plan.Assistant.LLM.Models {
 		hash := ModelHash(model)
 		output += fmt.Sprintf("\n  Model: %s (hash: %s)\n", model, hash)
-		for _, query := range e.plan.Queries {
-			baseName := strings.TrimSuffix(query.ID, filepath.Ext(query.ID))
+		for _, query := range e.queryOrder(model) {
+			baseName := plan.ResponseBaseName(query.ID)
 			outputPath := fmt.Sprintf("Output/%s/%s/%s_response.md",
-				e.plan.PlanID, hash, baseName)
+				e.plan.DirName(), hash, baseName)
 			output += fmt.Sprintf("    %s -> %s\n", query.ID, outputPath)
 		}
 	}
 
 	output += "\nLLM Parameters:\n"
 	output += fmt.Sprintf("  Temperature: %.1f\n", e.plan.Assistant.LLM.Temperature)
-	output += fmt.Sprintf("  Max tokens:  %d\n\n", e.plan.Assistant.LLM.MaxTokens)
+	output += fmt.Sprintf("  Max tokens:  %d\n", e.plan.Assistant.LLM.MaxTokens)
+	for _, model := range e.plan.Assistant.LLM.Models {
+		temperature, maxTokens := e.temperature(model), e.maxTokens(model)
+		if temperature != e.plan.Assistant.LLM.Temperature || maxTokens != e.plan.Assistant.LLM.MaxTokens {
+			output += fmt.Sprintf("    %s: temperature %.1f, max tokens %d\n", model, temperature, maxTokens)
+		}
+	}
+	output += "\n"
 
 	total := len(e.plan.Assistant.LLM.Models) * len(e.plan.Queries)
 	output += fmt.Sprintf("Total requests: %d (%d models x %d queries)\n",
@@ -127,100 +264,354 @@ func (e *Executor) Execute(ctx context.Context) (*ExecutionSummary, error) {
 	if len(e.plan.Queries) == 0 {
 		return nil, fmt.Errorf("no queries specified in plan")
 	}
+	if err := CheckModelHashes(e.plan.Assistant.LLM.Models); err != nil {
+		return nil, err
+	}
+	if err := plan.CheckResponseNames(e.plan.Queries); err != nil {
+		return nil, err
+	}
+
+	writer := NewResponseWriter(e.assistantDir, e.plan.DirName())
+
+	// Prevent concurrent execs of the same plan from clobbering files
+	lock, err := AcquireLock(ctx, writer.baseDir, e.options.WaitLock)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	// Keep the model directories decodable for tools reading the output
+	if err := recordModels(writer.baseDir, e.plan.Assistant.LLM.Models); err != nil {
+		return nil, err
+	}
 
-	writer := NewResponseWriter(e.assistantDir, e.plan.PlanID)
 	summary := &ExecutionSummary{
 		TotalQueries: len(e.plan.Queries),
 		TotalModels:  len(e.plan.Assistant.LLM.Models),
 	}
 
-	// Iterate over all models
+	// Token usage is persisted per task so interrupted runs keep accurate totals
+	usage, err := LoadUsage(writer.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	store := e.store(writer)
+
+	var pending map[task]bool
+	if e.options.Continue {
+		pending = e.pendingTasks(store)
+	}
+
+	if e.options.SkipUnchanged {
+		e.previous = indexResponses(filepath.Join(e.assistantDir, "Output"))
+	}
+
+	// Read queries once up front; workers then only wait on the network
+	basePrompt, err := e.systemPrompt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile system prompt: %w", err)
+	}
+	queries := e.prepareQueries(basePrompt)
+
+	// Progress is reported for the primary provider: a model listed by
+	// several providers is only routed once a request is sent. Results
+	// carry the provider that served them.
+	var tasks []scheduledTask
 	for _, model := range e.plan.Assistant.LLM.Models {
-		// Iterate over all queries
-		for _, query := range e.plan.Queries {
-			// Notify start
-			if e.options.OnProgress != nil {
-				e.options.OnProgress(ProgressEvent{
-					Type:    EventTaskStart,
-					Model:   model,
-					QueryID: query.ID,
-				})
-			}
+		provider := e.provider(model)
+		for _, query := range e.queryOrder(model) {
+			t := task{model, query.ID}
+			tasks = append(tasks, scheduledTask{
+				task:     t,
+				provider: provider,
+				query:    queries[query.ID],
+				resumed:  pending != nil && !pending[t],
+			})
+		}
+	}
 
-			start := time.Now()
-			result, err := e.executeOne(ctx, model, query.ID, writer)
-			duration := time.Since(start)
-
-			if err != nil {
-				summary.Errors = append(summary.Errors, fmt.Errorf(
-					"model=%s query=%s: %w", model, query.ID, err,
-				))
-				// Notify error
-				if e.options.OnProgress != nil {
-					e.options.OnProgress(ProgressEvent{
-						Type:     EventTaskError,
-						Model:    model,
-						QueryID:  query.ID,
-						Duration: duration,
-						Err:      err,
-					})
-				}
-				continue
-			}
+	outcomes := make([]taskOutcome, len(tasks))
+	e.runParallel(len(tasks), func(i int) {
+		outcomes[i] = e.runTask(ctx, tasks[i], store, usage)
+	})
 
-			summary.Results = append(summary.Results, *result)
-			summary.TotalTokens.Prompt += result.PromptTokens
-			summary.TotalTokens.Output += result.OutputTokens
-
-			// Notify done
-			if e.options.OnProgress != nil {
-				e.options.OnProgress(ProgressEvent{
-					Type:    EventTaskDone,
-					Model:   model,
-					QueryID: query.ID,
-					Tokens: TokenUsage{
-						Prompt: result.PromptTokens,
-						Output: result.OutputTokens,
-					},
-					Duration: duration,
-				})
-			}
+	// Summarize in plan order, whatever order the tasks finished in
+	var failures []TaskFailure
+	ran := make(map[task]bool) // Tasks sent in this run, see writeErrorLog
+	for i, t := range tasks {
+		outcome := outcomes[i]
+		if !t.resumed {
+			ran[t.task] = true
+		}
+
+		if outcome.err != nil {
+			summary.Errors = append(summary.Errors, fmt.Errorf(
+				"model=%s query=%s: %w", t.model, t.queryID, outcome.err,
+			))
+			summary.addProvider(ProviderSummary{Provider: t.provider, Errors: 1})
+			failures = append(failures, TaskFailure{
+				Model:    t.model,
+				QueryID:  t.queryID,
+				Err:      outcome.err,
+				FailedAt: outcome.failedAt,
+			})
+			continue
 		}
+
+		result := outcome.result
+		summary.Results = append(summary.Results, *result)
+		summary.TotalTokens.Prompt += result.PromptTokens
+		summary.TotalTokens.Output += result.OutputTokens
+		summary.TotalCost += result.Cost
+		summary.addProvider(ProviderSummary{
+			Provider: result.Provider,
+			Results:  1,
+			Tokens:   TokenUsage{Prompt: result.PromptTokens, Output: result.OutputTokens},
+		})
+
+		if outcome.usageErr != nil {
+			summary.Errors = append(summary.Errors, outcome.usageErr)
+		}
+	}
+
+	summary.CumulativeTokens = usage.Total()
+
+	summary.ErrorLog, err = writeErrorLog(writer.baseDir, ran, failures)
+	if err != nil {
+		summary.Errors = append(summary.Errors, err)
 	}
 
 	return summary, nil
 }
 
-// executeOne runs a single query with a single model.
-func (e *Executor) executeOne(ctx context.Context, model, queryID string, writer *ResponseWriter) (*Result, error) {
-	// Read query file
-	queryPath := filepath.Join(e.assistantDir, "Input", queryID)
-	queryContent, err := os.ReadFile(queryPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read query file %s: %w", queryPath, err)
+// task identifies a single model and query combination of the plan.
+type task struct {
+	model   string
+	queryID string
+}
+
+// scheduledTask is a task of a run with what is needed to run it.
+type scheduledTask struct {
+	task
+	provider string // Primary provider of the model, see Executor.provider
+	query    *preparedQuery
+	resumed  bool // Completed in an earlier run, see Options.Continue
+}
+
+// taskOutcome is the outcome of running a task.
+type taskOutcome struct {
+	result   *Result // Nil if the task failed
+	err      error
+	failedAt time.Time
+	usageErr error // Failed to record the token usage of the result
+}
+
+// runParallel calls run with every index below n, running up to
+// Options.Parallel calls at a time. Calls start in index order.
+func (e *Executor) runParallel(n int, run func(i int)) {
+	slots := make(chan struct{}, max(e.options.Parallel, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			run(i)
+		}()
 	}
+	wg.Wait()
+}
+
+// runTask runs a single task, reporting its progress and recording its
+// token usage. Resumed tasks are reported as skipped without running.
+// It is safe to call concurrently for different tasks.
+func (e *Executor) runTask(ctx context.Context, t scheduledTask, store ResponseStore, usage *UsageLedger) taskOutcome {
+	event := ProgressEvent{Model: t.model, Provider: t.provider, QueryID: t.queryID}
+
+	if t.resumed {
+		event.Type = EventTaskSkipped
+		e.notify(event)
+		return taskOutcome{result: &Result{
+			Model:      t.model,
+			QueryID:    t.queryID,
+			OutputPath: store.Path(t.model, t.queryID),
+			Skipped:    true,
+			Resumed:    true,
+			Provider:   t.provider,
+		}}
+	}
+
+	event.Type = EventTaskStart
+	e.notify(event)
+
+	start := time.Now()
+	result, err := e.executeTask(ctx, t.model, t.queryID, t.query, store)
+	event.Duration = time.Since(start)
 
-	// Make LLM request
-	resp, err := e.llmClient.Chat(ctx, llm.ChatRequest{
-		Model:        model,
-		SystemPrompt: e.plan.Assistant.SystemPrompt,
-		UserMessage:  string(queryContent),
-		Temperature:  e.plan.Assistant.LLM.Temperature,
-		MaxTokens:    e.plan.Assistant.LLM.MaxTokens,
-	})
 	if err != nil {
-		return nil, err
+		event.Type = EventTaskError
+		event.Err = err
+		e.notify(event)
+		return taskOutcome{err: err, failedAt: time.Now()}
+	}
+
+	if result.Provider == "" {
+		result.Provider = t.provider // Not routed, e.g. skipped
+	}
+	outcome := taskOutcome{result: result}
+	if !result.Skipped {
+		outcome.usageErr = usage.Record(TaskUsage{
+			Model:   t.model,
+			QueryID: t.queryID,
+			Prompt:  result.PromptTokens,
+			Output:  result.OutputTokens,
+		})
+	}
+
+	event.Type = EventTaskDone
+	event.Tokens = TokenUsage{Prompt: result.PromptTokens, Output: result.OutputTokens}
+	event.Cost = result.Cost
+	e.notify(event)
+	return outcome
+}
+
+// notify reports a progress event to Options.OnProgress, one at a time.
+func (e *Executor) notify(event ProgressEvent) {
+	if e.options.OnProgress == nil {
+		return
+	}
+	e.progressMu.Lock()
+	defer e.progressMu.Unlock()
+	e.options.OnProgress(event)
+}
+
+// store returns Options.Store, or writer if it is not set.
+func (e *Executor) store(writer *ResponseWriter) ResponseStore {
+	if e.options.Store != nil {
+		return e.options.Store
 	}
+	return writer
+}
+
+// PendingTasks returns the number of tasks Execute sends requests for:
+// every task of the plan, or with Options.Continue only those without
+// a completed response.
+func (e *Executor) PendingTasks() int {
+	if !e.options.Continue {
+		return len(e.plan.Assistant.LLM.Models) * len(e.plan.Queries)
+	}
+	return len(e.pendingTasks(e.store(NewResponseWriter(e.assistantDir, e.plan.DirName()))))
+}
+
+// pendingTasks returns the tasks that have no completed response yet.
+// A response is complete if it has execution metadata (executed_at),
+// so hand-written or imported files without it are re-run.
+func (e *Executor) pendingTasks(store ResponseStore) map[task]bool {
+	pending := make(map[task]bool)
+	for _, model := range e.plan.Assistant.LLM.Models {
+		for _, query := range e.plan.Queries {
+			if meta, _, err := store.Read(model, query.ID); err == nil && !meta.ExecutedAt.IsZero() {
+				continue
+			}
+			pending[task{model, query.ID}] = true
+		}
+	}
+	return pending
+}
+
+// executeTask runs executeOne within the per-task time limit, if any.
+func (e *Executor) executeTask(ctx context.Context, model, queryID string, query *preparedQuery, store ResponseStore) (*Result, error) {
+	limit := e.options.MaxDurationPerTask
+	if limit <= 0 {
+		return e.executeOne(ctx, model, queryID, query, store)
+	}
+
+	taskCtx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+
+	result, err := e.executeOne(taskCtx, model, queryID, query, store)
+	if err != nil && ctx.Err() == nil && errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w after %s", ErrTaskTimeout, limit)
+	}
+	return result, err
+}
+
+// executeOne runs a single query with a single model.
+func (e *Executor) executeOne(ctx context.Context, model, queryID string, query *preparedQuery, store ResponseStore) (*Result, error) {
+	if query.err != nil {
+		return nil, query.err
+	}
+
+	// Make LLM request, re-requesting responses rejected by post-filters
+	req := e.request(model, query)
+	requestHash := RequestHash(req)
+
+	// Keep pinned responses unless forced
+	if !e.options.Force {
+		if meta, content, err := store.Read(model, queryID); err == nil && meta.Pinned {
+			result := skipped(meta, content, queryID, store.Path(model, queryID))
+			result.Pinned = true
+			return result, nil
+		}
+	}
+
+	if path, ok := e.previous[requestHash]; ok {
+		return e.reuse(path, model, queryID, req.SystemPrompt, store)
+	}
+
+	var (
+		resp *llm.ChatResponse
+		wait time.Duration
+		cost float64 // Includes rejected attempts
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err = e.chat(ctx, req, model, queryID, store)
+		if err != nil {
+			return nil, err // The partial file is removed by streamToFile
+		}
+		wait += resp.RateLimitWait
+		cost += resp.Cost
+		if resp.Moderation != nil && resp.Moderation.Flagged {
+			break
+		}
+		reason := e.options.RejectIf.Check(resp.Content)
+		if reason == "" {
+			break
+		}
+		if attempt >= e.options.RetryRejected {
+			return nil, errors.Join(fmt.Errorf("%w: %s", ErrRejected, reason),
+				removePartial(store, model, queryID))
+		}
+	}
+
+	chars, words := response.CountText(resp.Content)
 
 	// Save response to file with metadata
-	outputPath, err := writer.Write(model, queryID, resp.Content, WriteOptions{
+	outputPath, err := store.Write(model, queryID, resp.Content, WriteOptions{
 		ProviderURL:  resp.ProviderURL,
 		Model:        resp.Model,
 		Duration:     resp.Duration,
 		InputTokens:  resp.PromptTokens,
 		OutputTokens: resp.OutputTokens,
+		Chars:        chars,
+		Words:        words,
+		Temperature:  req.Temperature,
+		Cost:         cost,
+		SystemPrompt: query.systemPrompt,
+		RequestHash:  requestHash,
+		QueryWrapped: query.wrapped,
+		Moderation:   resp.Moderation,
+		Precision:    e.options.TimestampPrecision,
+		HTTPStatus:   resp.HTTPStatus,
+		TTFB:         resp.TTFB,
 	})
 	if err != nil {
+		return nil, errors.Join(err, removePartial(store, model, queryID))
+	}
+	if err := removePartial(store, model, queryID); err != nil {
 		return nil, err
 	}
 
@@ -231,9 +622,135 @@ func (e *Executor) executeOne(ctx context.Context, model, queryID string, writer
 		OutputPath:   outputPath,
 		PromptTokens: resp.PromptTokens,
 		OutputTokens: resp.OutputTokens,
+		Chars:        chars,
+		Words:        words,
+		Flagged:      resp.Moderation != nil && resp.Moderation.Flagged,
+		Provider:     resp.Provider,
+		FinishReason: resp.FinishReason,
+
+		RateLimitWait: wait,
+		Cost:          cost,
+		Raw:           resp.Raw,
 	}, nil
 }
 
+// reuse copies a previously generated response into this plan's output
+// (if it lives elsewhere) and reports it as a skipped result. The system
+// prompt is the one of the unchanged request, whose metadata only keeps
+// its hash.
+func (e *Executor) reuse(path, model, queryID, systemPrompt string, store ResponseStore) (*Result, error) {
+	meta, content, err := response.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previous response %s: %w", path, err)
+	}
+
+	// Other backends get a fresh copy; ratings stay with the original
+	if _, ok := store.(*ResponseWriter); !ok {
+		outputPath, err := store.Write(model, queryID, content, WriteOptions{
+			ProviderURL:  meta.Provider,
+			Model:        meta.Model,
+			Duration:     meta.Duration,
+			InputTokens:  meta.Input,
+			OutputTokens: meta.Output,
+			Chars:        meta.Chars,
+			Words:        meta.Words,
+			Temperature:  e.temperature(model), // Same request hash
+			Cost:         meta.Cost,
+			SystemPrompt: systemPrompt,
+			RequestHash:  meta.RequestHash,
+			QueryWrapped: meta.QueryWrapped,
+			Precision:    e.options.TimestampPrecision,
+			HTTPStatus:   meta.HTTPStatus,
+			TTFB:         meta.TTFB,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return skipped(meta, content, queryID, outputPath), nil
+	}
+
+	outputPath := store.Path(model, queryID)
+	if outputPath != path {
+		// The copy is a new cell of this plan and is rated on its own
+		copied := *meta
+		copied.Rating = ""
+		copied.RatedAt = time.Time{}
+		data, err := response.Format(&copied, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format response: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := WriteResponseFile(outputPath, []byte(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	return skipped(meta, content, queryID, outputPath), nil
+}
+
+// skipped reports a stored response as a result that was not re-requested.
+func skipped(meta *response.Metadata, content, queryID, outputPath string) *Result {
+	return &Result{
+		Response:   content,
+		Model:      meta.Model,
+		QueryID:    queryID,
+		OutputPath: outputPath,
+		Chars:      meta.Chars,
+		Words:      meta.Words,
+		Skipped:    true,
+	}
+}
+
+// indexResponses maps request hashes to response files across all plans
+// in the output directory. Files without a request hash are ignored.
+func indexResponses(outputDir string) map[string]string {
+	index := make(map[string]string)
+
+	matches, _ := filepath.Glob(filepath.Join(outputDir, "*", "*", "*_response.md"))
+	for _, path := range matches {
+		meta, _, err := response.Parse(path)
+		if err != nil || meta.RequestHash == "" {
+			continue
+		}
+		// Keep the most recent response for each request
+		if prev, ok := index[meta.RequestHash]; ok {
+			if prevMeta, _, err := response.Parse(prev); err == nil && !meta.ExecutedAt.After(prevMeta.ExecutedAt) {
+				continue
+			}
+		}
+		index[meta.RequestHash] = path
+	}
+
+	return index
+}
+
+// provider returns the primary provider of a model, or empty string
+// if the client does not resolve providers. Requests may be balanced
+// to other providers listing the model; see Result.Provider.
+func (e *Executor) provider(model string) string {
+	if resolver, ok := e.llmClient.(llm.ModelResolver); ok {
+		_, provider := resolver.ResolveModel(model)
+		return provider
+	}
+	return ""
+}
+
+// maxTokens returns the max tokens for a model, preferring per-model
+// overrides of exec, then of the plan.
+func (e *Executor) maxTokens(model string) int {
+	if n, ok := e.options.MaxTokensPerModel[model]; ok {
+		return n
+	}
+	return e.plan.Assistant.LLM.MaxTokensFor(model)
+}
+
+// temperature returns the temperature for a model, preferring plan overrides.
+func (e *Executor) temperature(model string) float64 {
+	return e.plan.Assistant.LLM.TemperatureFor(model)
+}
+
 // Models returns the list of models from the plan.
 func (e *Executor) Models() []string {
 	return e.plan.Assistant.LLM.Models
@@ -247,3 +764,82 @@ func (e *Executor) QueryIDs() []string {
 	}
 	return ids
 }
+
+// ParseModelInts parses a comma-separated "model=value" list, e.g.
+// "gpt-4o=8000,claude=4000". Each value must be a positive integer.
+func ParseModelInts(s string) (map[string]int, error) {
+	result := make(map[string]int)
+	if strings.TrimSpace(s) == "" {
+		return result, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		// Split on the last "=" so model names may contain it
+		idx := strings.LastIndex(pair, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid override %q: expected model=value", pair)
+		}
+
+		model := strings.TrimSpace(pair[:idx])
+		value, err := strconv.Atoi(strings.TrimSpace(pair[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid override %q: %w", pair, err)
+		}
+		if value <= 0 {
+			return nil, fmt.Errorf("invalid override %q: value must be positive", pair)
+		}
+		result[model] = value
+	}
+
+	return result, nil
+}
+
+// MatchModels keys per-model values by the plan models they apply to.
+// A key applies to a model if both are the same or resolve to the same
+// model through aliases (alias -> full name). Keys that apply to none of
+// the models are an error, as are two keys applying to the same model.
+func MatchModels(models []string, values map[string]int, aliases map[string]string) (map[string]int, error) {
+	resolve := func(name string) string {
+		if fullName, ok := aliases[name]; ok {
+			return fullName
+		}
+		return name
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	matched := make(map[string]int, len(values))
+	matchedBy := make(map[string]string, len(values))
+	var unknown []string
+	for _, key := range keys {
+		found := false
+		for _, model := range models {
+			if key != model && resolve(key) != resolve(model) {
+				continue
+			}
+			found = true
+			if other, ok := matchedBy[model]; ok {
+				return nil, fmt.Errorf("%s and %s both set model %s", other, key, model)
+			}
+			matched[model] = values[key]
+			matchedBy[model] = key
+		}
+		if !found {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("not plan models: %s (plan models: %s)",
+			strings.Join(unknown, ", "), strings.Join(models, ", "))
+	}
+	return matched, nil
+}
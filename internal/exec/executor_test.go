@@ -0,0 +1,646 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
+	"go.octolab.org/toolset/tuna/internal/config"
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/response"
+)
+
+// roundRobinClient answers requests from its providers in turn.
+type roundRobinClient struct {
+	providers []string
+	requests  int
+}
+
+func (c *roundRobinClient) Chat(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	provider := c.providers[c.requests%len(c.providers)]
+	c.requests++
+	return &llm.ChatResponse{
+		Content:      "Response from " + provider,
+		Model:        req.Model,
+		Provider:     provider,
+		PromptTokens: 10,
+		OutputTokens: 1,
+	}, nil
+}
+
+func (c *roundRobinClient) ResolveModel(model string) (string, string) {
+	return model, c.providers[0]
+}
+
+// testPlan creates an assistant directory with the queries as input
+// files and a plan running them with the models.
+func testPlan(t *testing.T, models []string, queries ...string) (*plan.Plan, string) {
+	t.Helper()
+	assistantDir := t.TempDir()
+	inputDir := filepath.Join(assistantDir, "Input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+
+	p := &plan.Plan{
+		PlanID:      "plan",
+		AssistantID: "assistant",
+		Assistant: plan.Assistant{
+			SystemPrompt: "You are helpful.",
+			LLM:          plan.LLM{Models: models, MaxTokens: 100},
+		},
+	}
+	for _, query := range queries {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, query), []byte("Question "+query), 0644))
+		p.Queries = append(p.Queries, plan.Query{ID: query})
+	}
+	return p, assistantDir
+}
+
+func TestExecutor_Execute_BalancedProviders(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"model"}, "q1.md", "q2.md", "q3.md")
+	client := &roundRobinClient{providers: []string{"primary", "secondary"}}
+
+	summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+
+	var providers []string
+	for _, result := range summary.Results {
+		providers = append(providers, result.Provider)
+	}
+	assert.Equal(t, []string{"primary", "secondary", "primary"}, providers)
+	assert.Equal(t, map[string]ProviderSummary{
+		"primary":   {Provider: "primary", Results: 2, Tokens: TokenUsage{Prompt: 20, Output: 2}},
+		"secondary": {Provider: "secondary", Results: 1, Tokens: TokenUsage{Prompt: 10, Output: 1}},
+	}, summary.Providers)
+	assert.Equal(t, []string{"primary", "secondary"}, summary.ProviderNames())
+}
+
+func TestExecutionSummary_addProvider(t *testing.T) {
+	var summary ExecutionSummary
+	summary.addProvider(ProviderSummary{Provider: "b", Results: 1, Tokens: TokenUsage{Prompt: 10, Output: 1}})
+	summary.addProvider(ProviderSummary{Provider: "a", Errors: 1})
+	summary.addProvider(ProviderSummary{Provider: "b", Results: 1, Tokens: TokenUsage{Prompt: 5, Output: 2}})
+	summary.addProvider(ProviderSummary{Provider: "b", Errors: 1})
+
+	assert.Equal(t, map[string]ProviderSummary{
+		"a": {Provider: "a", Errors: 1},
+		"b": {Provider: "b", Results: 2, Errors: 1, Tokens: TokenUsage{Prompt: 15, Output: 3}},
+	}, summary.Providers)
+	assert.Equal(t, []string{"a", "b"}, summary.ProviderNames())
+}
+
+func TestExecutor_Execute_DiscardStore(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"model"}, "q1.md")
+	client := &roundRobinClient{providers: []string{"provider"}}
+
+	for range 2 {
+		summary, err := New(p, assistantDir, client, Options{Store: DiscardStore{}, Continue: true}).Execute(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, summary.Errors)
+		require.Len(t, summary.Results, 1)
+		assert.Equal(t, "model/q1.md", summary.Results[0].OutputPath)
+		assert.False(t, summary.Results[0].Resumed, "nothing is read back")
+	}
+	assert.Equal(t, 2, client.requests)
+	assert.NoFileExists(t, NewResponseWriter(assistantDir, p.DirName()).Path("model", "q1.md"))
+}
+
+func TestExecutor_PendingTasks(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"a", "b"}, "q1.md", "q2.md")
+	_, err := NewResponseWriter(assistantDir, p.DirName()).Write("a", "q1.md", "Answer", WriteOptions{Model: "a"})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		continueOp bool
+		want       int
+	}{
+		"every task":            {want: 4},
+		"continue, one is done": {continueOp: true, want: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			executor := New(p, assistantDir, nil, Options{Continue: tc.continueOp})
+			assert.Equal(t, tc.want, executor.PendingTasks())
+
+			estimates, err := executor.ApproxEstimate()
+			require.NoError(t, err)
+			assert.Len(t, estimates, tc.want, "the projection covers the same tasks")
+		})
+	}
+}
+
+func TestExecutor_Execute_SkipUnchangedResetsRating(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"model"}, "q1.md")
+	client := &roundRobinClient{providers: []string{"provider"}}
+
+	_, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	original := NewResponseWriter(assistantDir, p.DirName()).Path("model", "q1.md")
+	meta, content, err := response.Parse(original)
+	require.NoError(t, err)
+	meta.Rating = "good"
+	meta.RatedAt = time.Now()
+	data, err := response.Format(meta, content)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(original, []byte(data), 0644))
+
+	next := *p
+	next.PlanID = "next"
+	summary, err := New(&next, assistantDir, client, Options{SkipUnchanged: true}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, summary.Results, 1)
+	assert.True(t, summary.Results[0].Skipped)
+	assert.Equal(t, 1, client.requests)
+
+	copied, copiedContent, err := response.Parse(summary.Results[0].OutputPath)
+	require.NoError(t, err)
+	assert.NotEqual(t, original, summary.Results[0].OutputPath)
+	assert.Equal(t, content, copiedContent)
+	assert.Equal(t, meta.RequestHash, copied.RequestHash)
+	assert.Empty(t, copied.Rating)
+	assert.True(t, copied.RatedAt.IsZero())
+
+	meta, _, err = response.Parse(original)
+	require.NoError(t, err)
+	assert.Equal(t, "good", meta.Rating, "the original keeps its rating")
+}
+
+// optionsStore is a response store other than the file store that
+// records the options of every write.
+type optionsStore struct {
+	plainStore
+	writes []WriteOptions
+}
+
+func (s *optionsStore) Write(model, queryID, _ string, opts WriteOptions) (string, error) {
+	s.writes = append(s.writes, opts)
+	return model + "/" + queryID, nil
+}
+
+func (s *optionsStore) Read(string, string) (*response.Metadata, string, error) {
+	return nil, "", os.ErrNotExist
+}
+
+func TestExecutor_Execute_SkipUnchangedOtherStore(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"model"}, "q1.md")
+	client := &roundRobinClient{providers: []string{"provider"}}
+
+	_, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	original := NewResponseWriter(assistantDir, p.DirName()).Path("model", "q1.md")
+	meta, content, err := response.Parse(original)
+	require.NoError(t, err)
+	meta.Cost = 0.25
+	data, err := response.Format(meta, content)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(original, []byte(data), 0644))
+
+	next := *p
+	next.PlanID = "next"
+	store := &optionsStore{}
+	summary, err := New(&next, assistantDir, client, Options{SkipUnchanged: true, Store: store}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, summary.Results, 1)
+	assert.True(t, summary.Results[0].Skipped)
+	assert.Equal(t, 1, client.requests)
+
+	require.Len(t, store.writes, 1)
+	opts := store.writes[0]
+	assert.Equal(t, 0.25, opts.Cost)
+	assert.Equal(t, p.Assistant.SystemPrompt, opts.SystemPrompt)
+	assert.Equal(t, meta.SystemPromptHash, ContentHash(opts.SystemPrompt))
+	assert.Equal(t, meta.RequestHash, opts.RequestHash)
+}
+
+func TestExecutor_Execute_Pinned(t *testing.T) {
+	tests := map[string]struct {
+		force       bool
+		wantPinned  bool
+		wantContent string
+	}{
+		"kept":        {wantPinned: true, wantContent: "First answer."},
+		"overwritten": {force: true, wantContent: "Second answer."},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, assistantDir := testPlan(t, []string{"m"}, "q1.md")
+			client := &scriptedClient{replies: []string{"First answer.", "Second answer."}}
+
+			_, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+			require.NoError(t, err)
+			path := NewResponseWriter(assistantDir, p.DirName()).Path("m", "q1.md")
+			meta, content, err := response.Parse(path)
+			require.NoError(t, err)
+			meta.Pinned = true
+			data, err := response.Format(meta, content)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+			summary, err := New(p, assistantDir, client, Options{Force: tc.force}).Execute(context.Background())
+			require.NoError(t, err)
+			require.Empty(t, summary.Errors)
+			require.Len(t, summary.Results, 1)
+			assert.Equal(t, tc.wantPinned, summary.Results[0].Pinned)
+
+			_, content, err = response.Parse(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantContent, strings.TrimSpace(content))
+			if tc.wantPinned {
+				assert.Equal(t, 1, client.requests, "a pinned response is not requested again")
+			}
+		})
+	}
+}
+
+// recordingClient answers every request and keeps the requests by model.
+type recordingClient struct {
+	mu       sync.Mutex
+	requests map[string]llm.ChatRequest
+}
+
+func (c *recordingClient) Chat(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.requests == nil {
+		c.requests = make(map[string]llm.ChatRequest)
+	}
+	c.requests[req.Model] = req
+	return &llm.ChatResponse{Content: "ok", Model: req.Model}, nil
+}
+
+func TestExecutor_Execute_ImportedModels(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"gpt-4o"}, "q1.md")
+	p.ImportedModels = []string{"imported"}
+	client := &recordingClient{}
+
+	summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+	assert.Len(t, summary.Results, 1)
+	assert.NotContains(t, client.requests, "imported", "imported models are never sent")
+	assert.NotContains(t, New(p, assistantDir, nil, Options{DryRun: true}).DryRun(), "imported")
+}
+
+func TestExecutor_Execute_ModelOverrides(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"gpt-4o", "o1"}, "q1.md")
+	temperature := 0.2
+	p.Assistant.LLM.Temperature = 0.7
+	p.Assistant.LLM.Overrides = map[string]plan.ModelOverride{
+		"gpt-4o": {Temperature: &temperature, MaxTokens: 8000},
+	}
+	client := &recordingClient{}
+
+	summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+	assert.Equal(t, 0.2, client.requests["gpt-4o"].Temperature)
+	assert.Equal(t, 8000, client.requests["gpt-4o"].MaxTokens)
+	assert.Equal(t, 0.7, client.requests["o1"].Temperature)
+	assert.Equal(t, 100, client.requests["o1"].MaxTokens)
+
+	dryRun := New(p, assistantDir, nil, Options{DryRun: true}).DryRun()
+	assert.Contains(t, dryRun, "gpt-4o: temperature 0.2, max tokens 8000")
+	assert.NotContains(t, dryRun, "o1: temperature")
+}
+
+func TestExecutor_Execute_Variables(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"m"}, "q1.md", "q2.md")
+	p.Assistant.Variables = map[string]string{"Lang": "Go"}
+	require.NoError(t, os.WriteFile(filepath.Join(assistantDir, "Input", "q1.md"), []byte("Explain {{.Lang}} channels"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(assistantDir, "Input", "q2.md"), []byte("Explain {{.Missing}}"), 0644))
+	client := &recordingClient{}
+
+	summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, summary.Errors, 1)
+	assert.Contains(t, summary.Errors[0].Error(), "query q2.md:")
+	require.Len(t, summary.Results, 1)
+	assert.Equal(t, "Explain Go channels", client.requests["m"].UserMessage, "the model receives the expanded query")
+}
+
+func TestExecutor_Execute_ModerationFlagged(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		if input, ok := body["input"].(string); ok {
+			flagged := strings.Contains(input, "bad")
+			_, _ = fmt.Fprintf(w, `{"results":[{"flagged":%t,"categories":{"harassment":%t}}]}`, flagged, flagged)
+			return
+		}
+		messages := body["messages"].([]any)
+		sent = append(sent, messages[len(messages)-1].(map[string]any)["content"].(string))
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"Answer"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(server.Close)
+	router, err := llm.NewRouter(&config.Config{
+		Providers: []config.Provider{{Name: "p", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}, Moderate: true}},
+	})
+	require.NoError(t, err)
+
+	p, assistantDir := testPlan(t, []string{"m"}, "bad.md", "good.md")
+	summary, err := New(p, assistantDir, router, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+	assert.Equal(t, []string{"Question good.md"}, sent, "the flagged query is not sent to the model")
+
+	require.Len(t, summary.Results, 2)
+	flagged, passed := summary.Results[0], summary.Results[1]
+	assert.True(t, flagged.Flagged)
+	assert.False(t, passed.Flagged)
+
+	meta, content, err := response.Parse(flagged.OutputPath)
+	require.NoError(t, err)
+	assert.Equal(t, response.ModerationFlagged, meta.Moderation)
+	assert.Equal(t, []string{"harassment"}, meta.ModerationCategories)
+	assert.Empty(t, strings.TrimSpace(content))
+
+	meta, content, err = response.Parse(passed.OutputPath)
+	require.NoError(t, err)
+	assert.Equal(t, response.ModerationPassed, meta.Moderation)
+	assert.Equal(t, "Answer", strings.TrimSpace(content))
+}
+
+func TestExecutor_Execute_ResultDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"Cut"},"finish_reason":"length"}],"usage":{"prompt_tokens":5,"completion_tokens":100}}`)
+	}))
+	t.Cleanup(server.Close)
+	router, err := llm.NewRouter(&config.Config{
+		Providers: []config.Provider{{Name: "local", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}, RateLimit: "5rps"}},
+	})
+	require.NoError(t, err)
+
+	p, assistantDir := testPlan(t, []string{"m"}, "q1.md", "q2.md", "q3.md")
+	summary, err := New(p, assistantDir, router, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+	require.Len(t, summary.Results, 3)
+
+	var wait time.Duration
+	for _, result := range summary.Results {
+		assert.Equal(t, "local", result.Provider)
+		assert.Equal(t, "length", result.FinishReason)
+		wait += result.RateLimitWait
+	}
+	// One request every 200ms: the queries of a model run in turn,
+	// so the second and third one wait about 200ms each
+	assert.GreaterOrEqual(t, wait, 350*time.Millisecond)
+}
+
+func TestExecutor_Execute_MaxDurationPerTask(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		messages := body["messages"].([]any)
+		if messages[len(messages)-1].(map[string]any)["content"] == "Question slow.md" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"Answer"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(server.Close)
+	router, err := llm.NewRouter(&config.Config{
+		Providers: []config.Provider{{Name: "p", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}}},
+	})
+	require.NoError(t, err)
+
+	p, assistantDir := testPlan(t, []string{"m"}, "a.md", "slow.md", "z.md")
+	start := time.Now()
+	summary, err := New(p, assistantDir, router, Options{MaxDurationPerTask: 200 * time.Millisecond}).Execute(context.Background())
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "the slow task is cut off")
+
+	require.Len(t, summary.Errors, 1)
+	assert.ErrorIs(t, summary.Errors[0], ErrTaskTimeout)
+	assert.Contains(t, summary.Errors[0].Error(), "query=slow.md")
+
+	var done []string
+	for _, result := range summary.Results {
+		done = append(done, result.QueryID)
+	}
+	assert.ElementsMatch(t, []string{"a.md", "z.md"}, done, "tasks after the slow one still run")
+}
+
+// scriptedClient answers requests with its replies in turn,
+// repeating the last one.
+type scriptedClient struct {
+	replies  []string
+	requests int
+}
+
+func (c *scriptedClient) Chat(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	reply := c.replies[min(c.requests, len(c.replies)-1)]
+	c.requests++
+	return &llm.ChatResponse{Content: reply, Model: req.Model, Cost: 0.01}, nil
+}
+
+func TestExecutor_Execute_RejectIf(t *testing.T) {
+	rules := &config.RejectRules{MinLength: 10}
+
+	tests := map[string]struct {
+		replies      []string
+		retries      int
+		wantRequests int
+		wantErr      string
+	}{
+		"rejected": {
+			replies:      []string{"No."},
+			wantRequests: 1,
+			wantErr:      "response rejected: response too short (3 < 10 chars)",
+		},
+		"accepted on retry": {
+			replies:      []string{"No.", "Nope.", "A complete answer."},
+			retries:      2,
+			wantRequests: 3,
+		},
+		"retries exhausted": {
+			replies:      []string{"No.", "Nope."},
+			retries:      2,
+			wantRequests: 3,
+			wantErr:      "response rejected: response too short (5 < 10 chars)",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, assistantDir := testPlan(t, []string{"m"}, "q1.md")
+			client := &scriptedClient{replies: tc.replies}
+
+			summary, err := New(p, assistantDir, client, Options{RejectIf: rules, RetryRejected: tc.retries}).Execute(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRequests, client.requests)
+
+			if tc.wantErr == "" {
+				require.Empty(t, summary.Errors)
+				require.Len(t, summary.Results, 1)
+				assert.Equal(t, "A complete answer.", summary.Results[0].Response)
+				assert.InDelta(t, 0.03, summary.Results[0].Cost, 1e-9, "rejected attempts are paid for")
+				return
+			}
+			require.Len(t, summary.Errors, 1)
+			assert.ErrorIs(t, summary.Errors[0], ErrRejected)
+			assert.ErrorContains(t, summary.Errors[0], tc.wantErr)
+			assert.Empty(t, summary.Results)
+			assert.NoFileExists(t, NewResponseWriter(assistantDir, p.DirName()).Path("m", "q1.md"))
+		})
+	}
+}
+
+func TestMatchModels(t *testing.T) {
+	models := []string{"gpt-4o", "sonnet", "o1"}
+	aliases := map[string]string{"sonnet": "claude-sonnet-4", "4o": "gpt-4o"}
+
+	matched, err := MatchModels(models, map[string]int{"4o": 8000, "claude-sonnet-4": 4000}, aliases)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"gpt-4o": 8000, "sonnet": 4000}, matched)
+
+	_, err = MatchModels(models, map[string]int{"gpt-4": 100, "o1": 10}, aliases)
+	assert.EqualError(t, err, "not plan models: gpt-4 (plan models: gpt-4o, sonnet, o1)")
+
+	_, err = MatchModels(models, map[string]int{"4o": 1, "gpt-4o": 2}, aliases)
+	assert.EqualError(t, err, "4o and gpt-4o both set model gpt-4o")
+}
+
+// editingClient runs edit before answering its first request and
+// records every request it receives.
+type editingClient struct {
+	mu       sync.Mutex
+	edit     func()
+	requests []llm.ChatRequest
+}
+
+func (c *editingClient) Chat(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.requests) == 0 {
+		c.edit()
+	}
+	c.requests = append(c.requests, req)
+	return &llm.ChatResponse{Content: "ok", Model: req.Model}, nil
+}
+
+func TestExecutor_Execute_QueriesReadOnce(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"a", "b", "c"}, "q1.md", "q2.md")
+	inputDir := filepath.Join(assistantDir, "Input")
+	client := &editingClient{edit: func() {
+		// Reading a query again after dispatch would pick this up
+		for _, query := range p.Queries {
+			require.NoError(t, os.WriteFile(filepath.Join(inputDir, query.ID), []byte("Edited"), 0644))
+		}
+	}}
+
+	summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+
+	require.Len(t, client.requests, 6)
+	for _, req := range client.requests {
+		assert.Contains(t, []string{"Question q1.md", "Question q2.md"}, req.UserMessage, "model %s", req.Model)
+	}
+}
+
+func TestExecutor_Execute_SystemPromptCompiledOnce(t *testing.T) {
+	tests := map[string]struct {
+		stored string
+		frozen bool
+		want   string
+	}{
+		"stored prompt":       {stored: "You are helpful.", want: "You are helpful."},
+		"recompiled":          {want: "Be brief."},
+		"frozen empty prompt": {frozen: true, want: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, assistantDir := testPlan(t, []string{"a", "b"}, "q1.md", "q2.md")
+			p.Assistant.SystemPrompt = tc.stored
+			if tc.frozen {
+				// Frozen plans read the copies of their queries
+				p.Frozen = true
+				require.NoError(t, os.MkdirAll(p.InputDir(assistantDir), 0755))
+				for _, query := range p.Queries {
+					require.NoError(t, os.WriteFile(filepath.Join(p.InputDir(assistantDir), query.ID), []byte("Question"), 0644))
+				}
+			}
+			promptDir := filepath.Join(assistantDir, assistant.SystemPromptDir)
+			require.NoError(t, os.MkdirAll(promptDir, 0755))
+			fragment := filepath.Join(promptDir, "role.md")
+			require.NoError(t, os.WriteFile(fragment, []byte("Be brief."), 0644))
+			client := &editingClient{edit: func() {
+				// Compiling the prompt again would pick this up
+				require.NoError(t, os.WriteFile(fragment, []byte("Be verbose."), 0644))
+			}}
+
+			summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+			require.NoError(t, err)
+			require.Empty(t, summary.Errors)
+
+			require.Len(t, client.requests, 4)
+			for _, req := range client.requests {
+				assert.Contains(t, req.SystemPrompt, tc.want)
+				assert.NotContains(t, req.SystemPrompt, "Be verbose.")
+				if tc.want == "" {
+					assert.Empty(t, req.SystemPrompt)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutor_Execute_Frozen(t *testing.T) {
+	tests := map[string]struct {
+		freeze bool
+		want   string
+	}{
+		"frozen": {freeze: true, want: "Original question"},
+		"live":   {want: "Edited question"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			assistantDir := filepath.Join(baseDir, "bot")
+			require.NoError(t, os.MkdirAll(filepath.Join(assistantDir, assistant.SystemPromptDir), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(assistantDir, assistant.SystemPromptDir, "role.md"), []byte("Be brief."), 0644))
+			inputPath := filepath.Join(assistantDir, "Input", "q1.md")
+			require.NoError(t, os.MkdirAll(filepath.Dir(inputPath), 0755))
+			require.NoError(t, os.WriteFile(inputPath, []byte("Original question"), 0644))
+
+			result, err := plan.Generate(baseDir, "bot", plan.Config{Models: []string{"m"}, MaxTokens: 10, Freeze: tc.freeze})
+			require.NoError(t, err)
+			p, err := plan.LoadFromPath(result.PlanPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.freeze, p.Frozen)
+
+			require.NoError(t, os.WriteFile(inputPath, []byte("Edited question"), 0644))
+
+			client := &recordingClient{}
+			summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+			require.NoError(t, err)
+			require.Empty(t, summary.Errors)
+			assert.Equal(t, tc.want, client.requests["m"].UserMessage)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package exec
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+// FilterPlan returns a copy of the plan restricted to the given models
+// and query IDs, keeping the plan order. An empty list keeps everything.
+// Names that are not part of the plan are reported as an error.
+func FilterPlan(p *plan.Plan, models, queries []string) (*plan.Plan, error) {
+	if len(models) == 0 && len(queries) == 0 {
+		return p, nil
+	}
+
+	var unknown []string
+	for _, model := range models {
+		if !slices.Contains(p.Assistant.LLM.Models, model) {
+			unknown = append(unknown, "model "+model)
+		}
+	}
+	for _, id := range queries {
+		if !slices.ContainsFunc(p.Queries, func(q plan.Query) bool { return q.ID == id }) {
+			unknown = append(unknown, "query "+id)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("not in plan %s: %s", p.PlanID, strings.Join(unknown, ", "))
+	}
+
+	filtered := *p
+	if len(models) > 0 {
+		filtered.Assistant.LLM.Models = nil
+		for _, model := range p.Assistant.LLM.Models {
+			if slices.Contains(models, model) {
+				filtered.Assistant.LLM.Models = append(filtered.Assistant.LLM.Models, model)
+			}
+		}
+	}
+	if len(queries) > 0 {
+		filtered.Queries = nil
+		for _, q := range p.Queries {
+			if slices.Contains(queries, q.ID) {
+				filtered.Queries = append(filtered.Queries, q)
+			}
+		}
+	}
+	return &filtered, nil
+}
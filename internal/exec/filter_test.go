@@ -0,0 +1,80 @@
+package exec
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterPlan(t *testing.T) {
+	tests := map[string]struct {
+		models, queries []string
+		wantModels      []string
+		wantQueries     []string
+		wantTotal       string
+		err             string
+	}{
+		"no filter": {
+			wantModels:  []string{"gpt-4o", "o1", "sonnet"},
+			wantQueries: []string{"q1.md", "q2.md", "q3.md"},
+			wantTotal:   "Total requests: 9 (3 models x 3 queries)",
+		},
+		"query only": {
+			queries:     []string{"q3.md", "q1.md"},
+			wantModels:  []string{"gpt-4o", "o1", "sonnet"},
+			wantQueries: []string{"q1.md", "q3.md"},
+			wantTotal:   "Total requests: 6 (3 models x 2 queries)",
+		},
+		"model only": {
+			models:      []string{"o1"},
+			wantModels:  []string{"o1"},
+			wantQueries: []string{"q1.md", "q2.md", "q3.md"},
+			wantTotal:   "Total requests: 3 (1 models x 3 queries)",
+		},
+		"model and query": {
+			models:      []string{"sonnet", "gpt-4o"},
+			queries:     []string{"q2.md"},
+			wantModels:  []string{"gpt-4o", "sonnet"},
+			wantQueries: []string{"q2.md"},
+			wantTotal:   "Total requests: 2 (2 models x 1 queries)",
+		},
+		"unknown names": {
+			models:  []string{"o1", "llama"},
+			queries: []string{"q4.md"},
+			err:     "not in plan plan: model llama, query q4.md",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, assistantDir := testPlan(t, []string{"gpt-4o", "o1", "sonnet"}, "q1.md", "q2.md", "q3.md")
+
+			filtered, err := FilterPlan(p, tc.models, tc.queries)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantModels, filtered.Assistant.LLM.Models)
+			var queries []string
+			for _, q := range filtered.Queries {
+				queries = append(queries, q.ID)
+			}
+			assert.Equal(t, tc.wantQueries, queries)
+			assert.Len(t, p.Queries, 3, "the original plan is not modified")
+
+			dryRun := New(filtered, assistantDir, nil, Options{DryRun: true}).DryRun()
+			assert.Contains(t, dryRun, tc.wantTotal)
+			for _, model := range []string{"gpt-4o", "o1", "sonnet"} {
+				want := "Model: " + model + " "
+				if slices.Contains(tc.wantModels, model) {
+					assert.Contains(t, dryRun, want)
+				} else {
+					assert.NotContains(t, dryRun, want)
+				}
+			}
+		})
+	}
+}
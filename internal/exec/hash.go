@@ -3,11 +3,57 @@ package exec
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+
+	"go.octolab.org/toolset/tuna/internal/llm"
 )
 
 // ModelHash generates a short hash from model name for directory naming.
 // Returns first 8 characters of SHA-256 hash.
+//
+// The mapping is part of the output layout: existing plan outputs are
+// found by it, so it must never change. Collisions within a plan are
+// detected by CheckModelHashes, and ModelManifest records the model
+// behind each directory.
 func ModelHash(model string) string {
 	hash := sha256.Sum256([]byte(model))
 	return hex.EncodeToString(hash[:])[:8]
 }
+
+// CheckModelHashes fails if two different models share a ModelHash,
+// which would make them write to the same output directory.
+func CheckModelHashes(models []string) error {
+	seen := make(map[string]string, len(models))
+	for _, model := range models {
+		hash := ModelHash(model)
+		if other, ok := seen[hash]; ok && other != model {
+			return fmt.Errorf("models %q and %q have the same output directory %s", other, model, hash)
+		}
+		seen[hash] = model
+	}
+	return nil
+}
+
+// ContentHash generates a short hash of arbitrary content, such as a system prompt.
+// Returns first 8 characters of SHA-256 hash.
+func ContentHash(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])[:8]
+}
+
+// RequestHash identifies a chat request by everything that affects generation:
+// model, system prompt, user message, and sampling parameters.
+// Returns first 16 characters of SHA-256 hash.
+func RequestHash(req llm.ChatRequest) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%g\x00%d",
+		req.Model, req.SystemPrompt, req.UserMessage, req.Temperature, req.MaxTokens)
+	// Added only when set, so hashes of earlier requests stay valid
+	if req.TopP != 0 {
+		fmt.Fprintf(hash, "\x00top_p=%g", req.TopP)
+	}
+	if req.Seed != nil {
+		fmt.Fprintf(hash, "\x00seed=%d", *req.Seed)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
@@ -0,0 +1,24 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestModelHash_Golden pins the model directory names. If it fails,
+// existing plan outputs would no longer be found: do not update the
+// expected values, restore the algorithm instead.
+func TestModelHash_Golden(t *testing.T) {
+	tests := map[string]string{
+		"gpt-4o":                                 "a2a69af7",
+		"claude-sonnet-4":                        "f7d451e0",
+		"openrouter/anthropic/claude-3.5-sonnet": "8487b7fe",
+	}
+
+	for model, want := range tests {
+		t.Run(model, func(t *testing.T) {
+			assert.Equal(t, want, ModelHash(model))
+		})
+	}
+}
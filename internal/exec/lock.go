@@ -0,0 +1,186 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// LockFileName is the advisory lock file created in a plan's output directory.
+const LockFileName = ".exec.lock"
+
+// lockPollInterval is how often a waiting exec retries to acquire the lock.
+const lockPollInterval = 500 * time.Millisecond
+
+// ErrLocked is returned when another exec is running the same plan.
+var ErrLocked = errors.New("plan output is locked by another exec")
+
+// lockInfo identifies the lock owner; it is stored in the lock file.
+type lockInfo struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Lock is an acquired advisory lock on a plan's output directory.
+type Lock struct {
+	path string
+	info []byte // Lock file content, identifying this owner
+}
+
+// AcquireLock locks the output directory against concurrent execs.
+// A lock left by a crashed process on this host is removed as stale.
+// If wait is set, it polls until the lock is free or ctx is done;
+// otherwise it fails with ErrLocked.
+func AcquireLock(ctx context.Context, dir string, wait bool) (*Lock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	path := filepath.Join(dir, LockFileName)
+	host, _ := os.Hostname()
+	info, err := json.Marshal(lockInfo{PID: os.Getpid(), Host: host, StartedAt: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lock: %w", err)
+	}
+
+	for {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, writeErr := file.Write(info)
+			closeErr := file.Close()
+			if err := errors.Join(writeErr, closeErr); err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file: %w", err)
+			}
+			return &Lock{path: path, info: info}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		owner, stale := readLock(path, host)
+		if stale {
+			acquired, err := takeOverLock(path, host, info)
+			if err != nil {
+				return nil, err
+			}
+			if acquired {
+				return &Lock{path: path, info: info}, nil
+			}
+			continue
+		}
+
+		if !wait {
+			return nil, lockedError(owner)
+		}
+
+		timer := time.NewTimer(lockPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// CheckLock returns an ErrLocked error if an exec holds the lock on the
+// output directory, e.g. for commands that write to it outside of exec.
+// Stale locks are ignored.
+func CheckLock(dir string) error {
+	host, _ := os.Hostname()
+	path := filepath.Join(dir, LockFileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	owner, stale := readLock(path, host)
+	if stale {
+		return nil
+	}
+	return lockedError(owner)
+}
+
+// lockedError describes the lock owner, if known, in an ErrLocked error.
+func lockedError(owner *lockInfo) error {
+	if owner == nil {
+		return ErrLocked
+	}
+	return fmt.Errorf("%w (pid %d on %s, since %s)", ErrLocked,
+		owner.PID, owner.Host, owner.StartedAt.Format(time.RFC3339))
+}
+
+// takeOverLock replaces a stale lock with ours. The new lock is written
+// to a temporary file and renamed over the stale one, so the lock file
+// never disappears and no process can create it in between. Processes
+// taking over at the same time all rename, and the last one wins: each
+// reads the lock back and reports whether it holds it.
+func takeOverLock(path, host string, info []byte) (bool, error) {
+	tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmp, info, 0644); err != nil {
+		return false, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	// Another process may have taken over since the lock was found stale
+	if _, stale := readLock(path, host); !stale {
+		return false, nil
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return false, fmt.Errorf("failed to replace stale lock: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, nil
+	}
+	return bytes.Equal(data, info), nil
+}
+
+// Release removes the lock file, unless another process has taken it
+// over in the meantime. It is safe to call on a nil lock.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	if data, err := os.ReadFile(l.path); err == nil && !bytes.Equal(data, l.info) {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// readLock returns the lock owner and whether the lock is stale,
+// i.e. held by a process on this host that no longer exists.
+// Unreadable locks may be mid-write and are never considered stale.
+func readLock(path, host string) (*lockInfo, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var owner lockInfo
+	if err := json.Unmarshal(data, &owner); err != nil {
+		return nil, false
+	}
+
+	return &owner, owner.Host == host && !processAlive(owner.PID)
+}
+
+// processAlive reports whether a process with the given PID exists.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
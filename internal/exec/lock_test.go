@@ -0,0 +1,107 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadPID returns the PID of a process that has exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+
+	cmd := osexec.Command("true")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+// writeLock writes a lock file owned by pid on this host.
+func writeLock(t *testing.T, dir string, pid int) {
+	t.Helper()
+
+	host, _ := os.Hostname()
+	data, err := json.Marshal(lockInfo{PID: pid, Host: host, StartedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, LockFileName), data, 0644))
+}
+
+func TestAcquireLock(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := AcquireLock(context.Background(), dir, false)
+	require.NoError(t, err)
+
+	_, err = AcquireLock(context.Background(), dir, false)
+	require.ErrorIs(t, err, ErrLocked, "a second exec must detect the lock")
+	assert.ErrorIs(t, CheckLock(dir), ErrLocked)
+
+	require.NoError(t, lock.Release())
+	assert.NoError(t, CheckLock(dir))
+
+	lock, err = AcquireLock(context.Background(), dir, false)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestAcquireLock_Wait(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := AcquireLock(context.Background(), dir, false)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*lockPollInterval)
+	defer cancel()
+	_, err = AcquireLock(ctx, dir, true)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(lockPollInterval / 2)
+		_ = lock.Release()
+	}()
+	waited, err := AcquireLock(context.Background(), dir, true)
+	require.NoError(t, err)
+	require.NoError(t, waited.Release())
+}
+
+func TestAcquireLock_Stale(t *testing.T) {
+	dir := t.TempDir()
+	writeLock(t, dir, deadPID(t))
+
+	assert.NoError(t, CheckLock(dir), "a stale lock does not block writers")
+
+	lock, err := AcquireLock(context.Background(), dir, false)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	require.NoError(t, err)
+	var owner lockInfo
+	require.NoError(t, json.Unmarshal(data, &owner))
+	assert.Equal(t, os.Getpid(), owner.PID)
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	require.NoError(t, lock.Release())
+}
+
+func TestLock_ReleaseTakenOver(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := AcquireLock(context.Background(), dir, false)
+	require.NoError(t, err)
+
+	// Another process has taken the lock over in the meantime
+	writeLock(t, dir, os.Getppid())
+	require.NoError(t, lock.Release())
+
+	_, err = os.Stat(filepath.Join(dir, LockFileName))
+	assert.NoError(t, err, "release must not remove a lock of another process")
+}
@@ -0,0 +1,84 @@
+package exec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ManifestFileName is the name of the model manifest in the plan output directory.
+const ManifestFileName = "models.json"
+
+// ModelManifest maps output directory names (see ModelHash) to model names,
+// so the directories of a plan output remain decodable.
+type ModelManifest map[string]string
+
+// LoadModelManifest reads the manifest from the plan output directory.
+// A missing file yields an empty manifest.
+func LoadModelManifest(outputDir string) (ModelManifest, error) {
+	manifest := make(ModelManifest)
+
+	data, err := os.ReadFile(filepath.Join(outputDir, ManifestFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse model manifest %s: %w", filepath.Join(outputDir, ManifestFileName), err)
+	}
+	return manifest, nil
+}
+
+// Model returns the model written to the output directory hash.
+func (m ModelManifest) Model(hash string) (string, bool) {
+	model, ok := m[hash]
+	return model, ok
+}
+
+// Add records models, failing if a directory already belongs to another model.
+func (m ModelManifest) Add(models ...string) error {
+	for _, model := range models {
+		hash := ModelHash(model)
+		if other, ok := m[hash]; ok && other != model {
+			return fmt.Errorf("models %q and %q have the same output directory %s", other, model, hash)
+		}
+		m[hash] = model
+	}
+	return nil
+}
+
+// Save writes the manifest to the plan output directory atomically
+// via a temporary file.
+func (m ModelManifest) Save(outputDir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal model manifest: %w", err)
+	}
+
+	path := filepath.Join(outputDir, ManifestFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write model manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write model manifest: %w", err)
+	}
+	return nil
+}
+
+// recordModels adds the models to the manifest of the plan output directory.
+func recordModels(outputDir string, models []string) error {
+	manifest, err := LoadModelManifest(outputDir)
+	if err != nil {
+		return err
+	}
+	if err := manifest.Add(models...); err != nil {
+		return err
+	}
+	return manifest.Save(outputDir)
+}
@@ -0,0 +1,45 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelManifest_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	empty, err := LoadModelManifest(dir)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	require.NoError(t, recordModels(dir, []string{"gpt-4o", "claude-sonnet-4"}))
+	require.NoError(t, recordModels(dir, []string{"gpt-4o", "llama-3"}))
+
+	manifest, err := LoadModelManifest(dir)
+	require.NoError(t, err)
+	assert.Len(t, manifest, 3)
+	for _, model := range []string{"gpt-4o", "claude-sonnet-4", "llama-3"} {
+		decoded, ok := manifest.Model(ModelHash(model))
+		assert.True(t, ok)
+		assert.Equal(t, model, decoded)
+	}
+	_, ok := manifest.Model("00000000")
+	assert.False(t, ok)
+}
+
+func TestModelManifest_Add_Collision(t *testing.T) {
+	manifest := ModelManifest{ModelHash("gpt-4o"): "impostor"}
+	assert.Error(t, manifest.Add("gpt-4o"))
+}
+
+func TestLoadModelManifest_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte("{"), 0644))
+
+	_, err := LoadModelManifest(dir)
+	assert.ErrorContains(t, err, "failed to parse model manifest")
+}
@@ -0,0 +1,32 @@
+package exec
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+// queryOrder returns the plan's queries in the order they run for a model:
+// plan order, or with Options.ShuffleQueries a per-model permutation that
+// is the same for every run with the same Options.ShuffleSeed.
+func (e *Executor) queryOrder(model string) []plan.Query {
+	if !e.options.ShuffleQueries {
+		return e.plan.Queries
+	}
+	return ShuffleQueries(e.plan.Queries, model, e.options.ShuffleSeed)
+}
+
+// ShuffleQueries returns a shuffled copy of queries. The permutation is
+// determined by seed and model, so each model sees its own order.
+func ShuffleQueries(queries []plan.Query, model string, seed uint64) []plan.Query {
+	h := fnv.New64a()
+	h.Write([]byte(model))
+
+	shuffled := append([]plan.Query(nil), queries...)
+	rng := rand.New(rand.NewPCG(seed, h.Sum64()))
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
@@ -0,0 +1,35 @@
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+func TestShuffleQueries(t *testing.T) {
+	queries := make([]plan.Query, 20)
+	for i := range queries {
+		queries[i] = plan.Query{ID: fmt.Sprintf("q%02d.md", i)}
+	}
+	original := append([]plan.Query(nil), queries...)
+
+	shuffled := ShuffleQueries(queries, "gpt-4o", 42)
+	assert.ElementsMatch(t, queries, shuffled)
+	assert.NotEqual(t, queries, shuffled)
+	assert.Equal(t, original, queries, "the plan order is not changed")
+
+	assert.Equal(t, shuffled, ShuffleQueries(queries, "gpt-4o", 42), "same seed, same order")
+	assert.NotEqual(t, shuffled, ShuffleQueries(queries, "gpt-4o", 43), "different seeds differ")
+	assert.NotEqual(t, shuffled, ShuffleQueries(queries, "o1", 42), "each model has its own order")
+}
+
+func TestExecutor_queryOrder(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"model"}, "a.md", "b.md", "c.md", "d.md", "e.md")
+
+	assert.Equal(t, p.Queries, New(p, assistantDir, nil, Options{ShuffleSeed: 7}).queryOrder("model"))
+	assert.Equal(t, ShuffleQueries(p.Queries, "model", 7),
+		New(p, assistantDir, nil, Options{ShuffleQueries: true, ShuffleSeed: 7}).queryOrder("model"))
+}
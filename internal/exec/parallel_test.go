@@ -0,0 +1,97 @@
+package exec
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/llm"
+)
+
+// slowClient answers every request after a delay, tracking how many
+// requests are in flight at once.
+type slowClient struct {
+	delay    time.Duration
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *slowClient) Chat(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	return &llm.ChatResponse{Content: "Answer to " + req.UserMessage, Model: req.Model, PromptTokens: 1}, nil
+}
+
+func TestExecutor_runParallel(t *testing.T) {
+	for _, parallel := range []int{0, 1, 3} {
+		e := New(nil, "", nil, Options{Parallel: parallel})
+
+		var (
+			mu      sync.Mutex
+			running int
+			peak    int
+			ran     []int
+		)
+		e.runParallel(10, func(i int) {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			ran = append(ran, i)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+
+		assert.Len(t, ran, 10)
+		assert.Equal(t, max(parallel, 1), peak, "parallel=%d", parallel)
+	}
+}
+
+func TestExecutor_Execute_Parallel(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"a", "b"}, "q1.md", "q2.md", "q3.md")
+	client := &slowClient{delay: 20 * time.Millisecond}
+
+	var calls, concurrent atomic.Int32
+	opts := Options{
+		Parallel: 4,
+		OnProgress: func(ProgressEvent) {
+			if concurrent.Add(1) > 1 {
+				t.Error("progress callback called concurrently")
+			}
+			calls.Add(1)
+			time.Sleep(time.Millisecond)
+			concurrent.Add(-1)
+		},
+	}
+
+	summary, err := New(p, assistantDir, client, opts).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+
+	assert.Equal(t, int32(4), client.peak.Load())
+	assert.Equal(t, int32(12), calls.Load(), "start and done for every task")
+
+	var order []string
+	for _, result := range summary.Results {
+		order = append(order, result.Model+"/"+result.QueryID)
+		assert.FileExists(t, result.OutputPath)
+	}
+	assert.Equal(t, []string{"a/q1.md", "a/q2.md", "a/q3.md", "b/q1.md", "b/q2.md", "b/q3.md"}, order)
+	assert.Equal(t, 6, summary.TotalTokens.Prompt)
+}
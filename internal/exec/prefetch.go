@@ -0,0 +1,144 @@
+package exec
+
+import (
+	"fmt"
+	"sync"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+// prefetchConcurrency bounds the number of query files read at once.
+const prefetchConcurrency = 8
+
+// preparedQuery holds a query ready to be sent: read from disk, with
+// front matter overrides resolved and the plan's prefix/suffix applied.
+type preparedQuery struct {
+	systemPrompt string
+	userMessage  string
+	wrapped      bool
+	err          error // Reported for every task of the query
+}
+
+// systemPrompt returns the plan's compiled system prompt. A plan stored
+// with an empty prompt while fragments exist is recompiled once, and
+// the result is cached for the rest of the run. Frozen plans always use
+// the prompt compiled at creation.
+func (e *Executor) systemPrompt() (string, error) {
+	if e.prompt != nil {
+		return *e.prompt, nil
+	}
+
+	prompt := e.plan.Assistant.SystemPrompt
+	if prompt == "" && !e.plan.Frozen {
+		variant := e.plan.Assistant.PromptVariant
+		promptDir := assistant.PromptDir(e.assistantDir, variant)
+		if files, _ := assistant.ListFiles(promptDir, assistant.DefaultFilter()); len(files) > 0 {
+			compiled, err := assistant.CompileSystemPromptVariant(e.assistantDir, variant)
+			if err != nil {
+				return "", err
+			}
+			prompt = compiled
+		}
+	}
+
+	e.prompt = &prompt
+	return prompt, nil
+}
+
+// TaskRequest is the request that would be sent for a model and query.
+type TaskRequest struct {
+	Model   string
+	QueryID string
+	Request llm.ChatRequest
+}
+
+// Requests assembles the requests for every task of the plan, exactly
+// as Execute would send them, without contacting any provider.
+func (e *Executor) Requests() ([]TaskRequest, error) {
+	basePrompt, err := e.systemPrompt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile system prompt: %w", err)
+	}
+	queries := e.prepareQueries(basePrompt)
+
+	var requests []TaskRequest
+	for _, model := range e.plan.Assistant.LLM.Models {
+		for _, q := range e.plan.Queries {
+			query := queries[q.ID]
+			if query.err != nil {
+				return nil, query.err
+			}
+			requests = append(requests, TaskRequest{
+				Model:   model,
+				QueryID: q.ID,
+				Request: e.request(model, query),
+			})
+		}
+	}
+	return requests, nil
+}
+
+// request builds the chat request for a model and a prepared query.
+func (e *Executor) request(model string, query *preparedQuery) llm.ChatRequest {
+	return llm.ChatRequest{
+		Model:        model,
+		SystemPrompt: query.systemPrompt,
+		UserMessage:  query.userMessage,
+		Temperature:  e.temperature(model),
+		MaxTokens:    e.maxTokens(model),
+		TopP:         e.plan.Assistant.LLM.TopP,
+		Seed:         e.plan.Assistant.LLM.Seed,
+	}
+}
+
+// prepareQueries reads and prepares all plan queries up front, so that
+// each query file is read once per run rather than once per model.
+func (e *Executor) prepareQueries(basePrompt string) map[string]*preparedQuery {
+	prepared := make(map[string]*preparedQuery, len(e.plan.Queries))
+	for _, query := range e.plan.Queries {
+		prepared[query.ID] = &preparedQuery{}
+	}
+
+	sem := make(chan struct{}, prefetchConcurrency)
+	var wg sync.WaitGroup
+	for queryID, query := range prepared {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			*query = e.prepareQuery(queryID, basePrompt)
+		}()
+	}
+	wg.Wait()
+
+	return prepared
+}
+
+// prepareQuery reads a single query file and applies query-level overrides.
+func (e *Executor) prepareQuery(queryID, basePrompt string) preparedQuery {
+	queryContent, err := plan.ReadQuery(e.plan.InputDir(e.assistantDir), queryID)
+	if err != nil {
+		return preparedQuery{err: err}
+	}
+
+	// Apply query-level overrides from front matter
+	queryOpts, userMessage := ParseQuery(queryContent)
+	systemPrompt, err := queryOpts.systemPrompt(e.assistantDir, basePrompt)
+	if err != nil {
+		return preparedQuery{err: err}
+	}
+	userMessage, err = expandQuery(queryID, userMessage, e.plan.Assistant.Variables)
+	if err != nil {
+		return preparedQuery{err: err}
+	}
+	userMessage, wrapped := wrapQuery(userMessage, e.plan.Assistant.QueryPrefix, e.plan.Assistant.QuerySuffix)
+
+	return preparedQuery{
+		systemPrompt: systemPrompt,
+		userMessage:  userMessage,
+		wrapped:      wrapped,
+	}
+}
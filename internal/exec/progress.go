@@ -0,0 +1,115 @@
+package exec
+
+import "sync"
+
+// ProgressAggregator consumes progress events and maintains task counts
+// and token totals, so that every progress renderer derives its numbers
+// from the same state. It is safe for concurrent use.
+type ProgressAggregator struct {
+	mu        sync.Mutex
+	snapshot  ProgressSnapshot
+	providers map[string]int // provider -> index in snapshot.Providers
+}
+
+// ProgressSnapshot is a point-in-time view of execution progress.
+type ProgressSnapshot struct {
+	Total     int
+	Running   int
+	Completed int
+	Failed    int
+	Skipped   int // Completed in an earlier run; included in Completed
+	Tokens    TokenUsage
+	Cost      float64
+	Providers []ProviderProgress // In order of first appearance in the plan
+}
+
+// Finished returns the number of tasks that completed or failed.
+func (s ProgressSnapshot) Finished() int {
+	return s.Completed + s.Failed
+}
+
+// ProviderProgress holds progress counts for a single provider.
+type ProviderProgress struct {
+	Provider  string
+	Total     int
+	Running   int
+	Completed int
+	Failed    int
+	Tokens    TokenUsage
+}
+
+// NewProgressAggregator creates an aggregator for every model running
+// queryCount queries. Providers maps each model to its resolved provider;
+// it may be nil.
+func NewProgressAggregator(models []string, queryCount int, providers map[string]string) *ProgressAggregator {
+	a := &ProgressAggregator{providers: make(map[string]int)}
+	for _, model := range models {
+		a.snapshot.Total += queryCount
+		a.provider(providers[model]).Total += queryCount
+	}
+	return a
+}
+
+// provider returns the progress entry for the named provider, creating it if needed.
+func (a *ProgressAggregator) provider(name string) *ProviderProgress {
+	i, ok := a.providers[name]
+	if !ok {
+		i = len(a.snapshot.Providers)
+		a.providers[name] = i
+		a.snapshot.Providers = append(a.snapshot.Providers, ProviderProgress{Provider: name})
+	}
+	return &a.snapshot.Providers[i]
+}
+
+// Handle updates the counts with a progress event.
+func (a *ProgressAggregator) Handle(event ProgressEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	provider := a.provider(event.Provider)
+	switch event.Type {
+	case EventTaskStart:
+		a.snapshot.Running++
+		provider.Running++
+	case EventTaskDone:
+		a.snapshot.Running--
+		a.snapshot.Completed++
+		a.snapshot.Tokens.Prompt += event.Tokens.Prompt
+		a.snapshot.Tokens.Output += event.Tokens.Output
+		a.snapshot.Cost += event.Cost
+		provider.Running--
+		provider.Completed++
+		provider.Tokens.Prompt += event.Tokens.Prompt
+		provider.Tokens.Output += event.Tokens.Output
+	case EventTaskError:
+		a.snapshot.Running--
+		a.snapshot.Failed++
+		provider.Running--
+		provider.Failed++
+	case EventTaskSkipped:
+		a.snapshot.Completed++
+		a.snapshot.Skipped++
+		provider.Completed++
+	}
+}
+
+// Snapshot returns a copy of the current progress.
+func (a *ProgressAggregator) Snapshot() ProgressSnapshot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	snapshot := a.snapshot
+	snapshot.Providers = append([]ProviderProgress(nil), a.snapshot.Providers...)
+	return snapshot
+}
+
+// Wrap returns a callback that updates the aggregator before calling next,
+// so next observes counts that already include the event.
+func (a *ProgressAggregator) Wrap(next ProgressCallback) ProgressCallback {
+	return func(event ProgressEvent) {
+		a.Handle(event)
+		if next != nil {
+			next(event)
+		}
+	}
+}
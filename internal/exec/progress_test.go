@@ -0,0 +1,84 @@
+package exec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressAggregator(t *testing.T) {
+	start := func(model, provider, query string) ProgressEvent {
+		return ProgressEvent{Type: EventTaskStart, Model: model, Provider: provider, QueryID: query}
+	}
+	done := func(model, provider, query string, prompt, output int) ProgressEvent {
+		return ProgressEvent{Type: EventTaskDone, Model: model, Provider: provider, QueryID: query,
+			Tokens: TokenUsage{Prompt: prompt, Output: output}, Cost: 0.5}
+	}
+
+	tests := map[string]struct {
+		events []ProgressEvent
+		want   ProgressSnapshot
+	}{
+		"nothing started": {
+			want: ProgressSnapshot{Total: 4, Providers: []ProviderProgress{
+				{Provider: "openai", Total: 2},
+				{Provider: "local", Total: 2},
+			}},
+		},
+		"running and finished": {
+			events: []ProgressEvent{
+				start("gpt-4o", "openai", "q1.md"),
+				start("llama", "local", "q1.md"),
+				done("gpt-4o", "openai", "q1.md", 10, 5),
+				start("gpt-4o", "openai", "q2.md"),
+				{Type: EventTaskError, Model: "llama", Provider: "local", QueryID: "q1.md", Err: errors.New("timeout")},
+			},
+			want: ProgressSnapshot{
+				Total: 4, Running: 1, Completed: 1, Failed: 1,
+				Tokens: TokenUsage{Prompt: 10, Output: 5}, Cost: 0.5,
+				Providers: []ProviderProgress{
+					{Provider: "openai", Total: 2, Running: 1, Completed: 1, Tokens: TokenUsage{Prompt: 10, Output: 5}},
+					{Provider: "local", Total: 2, Failed: 1},
+				},
+			},
+		},
+		"resumed and output deltas": {
+			events: []ProgressEvent{
+				{Type: EventTaskSkipped, Model: "gpt-4o", Provider: "openai", QueryID: "q1.md"},
+				start("llama", "local", "q2.md"),
+				{Type: EventTaskOutput, Model: "llama", Provider: "local", QueryID: "q2.md", Output: "Hel"},
+				done("llama", "local", "q2.md", 3, 7),
+			},
+			want: ProgressSnapshot{
+				Total: 4, Completed: 2, Skipped: 1,
+				Tokens: TokenUsage{Prompt: 3, Output: 7}, Cost: 0.5,
+				Providers: []ProviderProgress{
+					{Provider: "openai", Total: 2, Completed: 1},
+					{Provider: "local", Total: 2, Completed: 1, Tokens: TokenUsage{Prompt: 3, Output: 7}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			aggregator := NewProgressAggregator([]string{"gpt-4o", "llama"}, 2,
+				map[string]string{"gpt-4o": "openai", "llama": "local"})
+
+			var seen []int
+			callback := aggregator.Wrap(func(ProgressEvent) {
+				seen = append(seen, aggregator.Snapshot().Finished())
+			})
+			for _, event := range tc.events {
+				callback(event)
+			}
+
+			got := aggregator.Snapshot()
+			assert.Equal(t, tc.want, got)
+			if len(seen) > 0 {
+				assert.Equal(t, got.Finished(), seen[len(seen)-1], "the callback sees counts including the event")
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"go.octolab.org/toolset/tuna/internal/response"
+)
+
+// QueryOptions holds per-query settings from the query file front matter.
+//
+//	---
+//	system_prompt_file: System prompt/persona.md
+//	---
+type QueryOptions struct {
+	SystemPrompt     string `yaml:"system_prompt"`      // Inline system prompt override
+	SystemPromptFile string `yaml:"system_prompt_file"` // Path relative to the assistant directory
+}
+
+// ParseQuery splits query file content into options and the user message.
+// Content without front matter is returned unchanged with empty options,
+// and so is content whose leading "---" block is not a YAML mapping, e.g.
+// a query that starts with a Markdown horizontal rule.
+func ParseQuery(data string) (*QueryOptions, string) {
+	frontMatter, content := response.SplitFrontMatter(data)
+	if frontMatter == "" {
+		return &QueryOptions{}, data
+	}
+
+	opts := &QueryOptions{}
+	if err := yaml.Unmarshal([]byte(frontMatter), opts); err != nil {
+		return &QueryOptions{}, data
+	}
+
+	return opts, content
+}
+
+// systemPrompt returns the system prompt for a query, preferring
+// query-level overrides over the plan default.
+func (o *QueryOptions) systemPrompt(assistantDir, fallback string) (string, error) {
+	if o.SystemPrompt != "" {
+		return o.SystemPrompt, nil
+	}
+	if o.SystemPromptFile != "" {
+		path := filepath.Join(assistantDir, o.SystemPromptFile)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read system prompt file %s: %w", path, err)
+		}
+		return string(data), nil
+	}
+	return fallback, nil
+}
+
+// expandQuery renders {{.Var}} placeholders in the query content with
+// the plan variables. Unknown variables are an error naming the query,
+// also when the plan has no variables; literal braces are written as
+// {{"{{"}}. Queries without braces are left unchanged.
+func expandQuery(queryID, content string, vars map[string]string) (string, error) {
+	if !strings.Contains(content, "{{") {
+		return content, nil
+	}
+
+	tmpl, err := template.New(queryID).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("query %s: invalid template: %w", queryID, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("query %s: %w", queryID, err)
+	}
+	return sb.String(), nil
+}
+
+// wrapQuery surrounds the user message with the plan's query prefix and suffix,
+// separated by blank lines. Reports whether any wrapping was applied.
+func wrapQuery(message, prefix, suffix string) (string, bool) {
+	if prefix == "" && suffix == "" {
+		return message, false
+	}
+
+	parts := make([]string, 0, 3)
+	if prefix != "" {
+		parts = append(parts, strings.TrimRight(prefix, "\n"))
+	}
+	parts = append(parts, strings.Trim(message, "\n"))
+	if suffix != "" {
+		parts = append(parts, strings.TrimLeft(suffix, "\n"))
+	}
+	return strings.Join(parts, "\n\n"), true
+}
@@ -0,0 +1,101 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		opts     QueryOptions
+		expected string
+	}{
+		"plain text": {
+			data:     "What is Go?\n",
+			expected: "What is Go?\n",
+		},
+		"front matter": {
+			data:     "---\nsystem_prompt: Be brief.\n---\nWhat is Go?\n",
+			opts:     QueryOptions{SystemPrompt: "Be brief."},
+			expected: "What is Go?\n",
+		},
+		"horizontal rule": {
+			data:     "---\nIntro text.\n---\nWhat is Go?\n",
+			expected: "---\nIntro text.\n---\nWhat is Go?\n",
+		},
+		"invalid yaml": {
+			data:     "---\nsystem_prompt: [unclosed\n---\nWhat is Go?\n",
+			expected: "---\nsystem_prompt: [unclosed\n---\nWhat is Go?\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts, content := ParseQuery(test.data)
+			require.NotNil(t, opts)
+			assert.Equal(t, test.opts, *opts)
+			assert.Equal(t, test.expected, content)
+		})
+	}
+}
+
+func TestExpandQuery(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		vars     map[string]string
+		expected string
+		err      string
+	}{
+		"no placeholders": {
+			content:  "Explain channels in Go",
+			expected: "Explain channels in Go",
+		},
+		"placeholder without variables": {
+			content: "Explain {{.Lang}} channels",
+			err:     `query q1.md: template: q1.md:1:10: executing "q1.md" at <.Lang>: map has no entry for key "Lang"`,
+		},
+		"literal braces without variables": {
+			content:  `Write {{"{{"}}.Name}}`,
+			expected: "Write {{.Name}}",
+		},
+		"substitution": {
+			content:  "Explain {{.Topic}} in {{.Lang}}",
+			vars:     map[string]string{"Topic": "channels", "Lang": "Go"},
+			expected: "Explain channels in Go",
+		},
+		"values are not escaped": {
+			content:  "Compare {{.A}}",
+			vars:     map[string]string{"A": "<a> & \"b\""},
+			expected: "Compare <a> & \"b\"",
+		},
+		"literal braces": {
+			content:  `Write {{"{{"}}.Name}} for {{.Lang}}`,
+			vars:     map[string]string{"Lang": "Go"},
+			expected: "Write {{.Name}} for Go",
+		},
+		"unknown variable": {
+			content: "Explain {{.Missing}}",
+			vars:    map[string]string{"Lang": "Go"},
+			err:     "query q1.md:",
+		},
+		"invalid template": {
+			content: "Explain {{.Lang",
+			vars:    map[string]string{"Lang": "Go"},
+			err:     "query q1.md: invalid template:",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			content, err := expandQuery("q1.md", test.content, test.vars)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, content)
+		})
+	}
+}
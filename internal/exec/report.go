@@ -0,0 +1,78 @@
+package exec
+
+import "time"
+
+// RunReport is a flat, JSON-friendly summary of a single execution,
+// suitable for structured logging.
+type RunReport struct {
+	PlanID       string           `json:"plan_id"`
+	Status       string           `json:"status"` // ReportStatusOK or ReportStatusErrors
+	Queries      int              `json:"queries"`
+	Models       int              `json:"models"`
+	Results      int              `json:"results"`
+	Errors       int              `json:"errors"`
+	Skipped      int              `json:"skipped"`
+	Flagged      int              `json:"flagged"`
+	PromptTokens int              `json:"prompt_tokens"`
+	OutputTokens int              `json:"output_tokens"`
+	DurationMS   int64            `json:"duration_ms"`
+	Providers    []ProviderReport `json:"providers,omitempty"`
+}
+
+// ProviderReport holds report subtotals for a single provider.
+type ProviderReport struct {
+	Provider     string `json:"provider"`
+	Results      int    `json:"results"`
+	Errors       int    `json:"errors"`
+	PromptTokens int    `json:"prompt_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+}
+
+// Report status values.
+const (
+	ReportStatusOK     = "ok"
+	ReportStatusErrors = "errors"
+)
+
+// NewRunReport builds a report from an execution summary.
+func NewRunReport(planID string, summary *ExecutionSummary, duration time.Duration) RunReport {
+	report := RunReport{
+		PlanID:       planID,
+		Status:       ReportStatusOK,
+		Queries:      summary.TotalQueries,
+		Models:       summary.TotalModels,
+		Results:      len(summary.Results),
+		Errors:       len(summary.Errors),
+		PromptTokens: summary.TotalTokens.Prompt,
+		OutputTokens: summary.TotalTokens.Output,
+		DurationMS:   duration.Milliseconds(),
+	}
+	if report.Errors > 0 {
+		report.Status = ReportStatusErrors
+	}
+
+	for _, result := range summary.Results {
+		if result.Skipped {
+			report.Skipped++
+		}
+		if result.Flagged {
+			report.Flagged++
+		}
+	}
+
+	for _, name := range summary.ProviderNames() {
+		ps := summary.Providers[name]
+		if ps.Provider == "" {
+			continue
+		}
+		report.Providers = append(report.Providers, ProviderReport{
+			Provider:     ps.Provider,
+			Results:      ps.Results,
+			Errors:       ps.Errors,
+			PromptTokens: ps.Tokens.Prompt,
+			OutputTokens: ps.Tokens.Output,
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,52 @@
+package exec
+
+import (
+	"os"
+
+	"go.octolab.org/toolset/tuna/internal/response"
+)
+
+// ResponseStore persists generated responses. The filesystem layout
+// written by ResponseWriter is the default; other backends (e.g. a
+// database or object storage) can be plugged in via Options.Store.
+type ResponseStore interface {
+	// Path returns the location of the response for a model and query.
+	// It identifies the response in results and need not be a file path.
+	Path(model, queryID string) string
+
+	// Write saves a response with metadata and returns its location.
+	Write(model, queryID, content string, opts WriteOptions) (string, error)
+
+	// Read returns the metadata and content of a stored response.
+	Read(model, queryID string) (*response.Metadata, string, error)
+}
+
+var (
+	_ ResponseStore = (*ResponseWriter)(nil)
+	_ ResponseStore = DiscardStore{}
+)
+
+// Read parses the response file of a model and query.
+func (w *ResponseWriter) Read(model, queryID string) (*response.Metadata, string, error) {
+	return response.Parse(w.Path(model, queryID))
+}
+
+// DiscardStore is a response store that keeps nothing, for runs whose
+// responses are only printed, e.g. exec --raw. Responses are identified
+// as "<model>/<query>"; as none can be read back, every task is sent.
+type DiscardStore struct{}
+
+// Path returns the identifier of the response for a model and query.
+func (DiscardStore) Path(model, queryID string) string {
+	return model + "/" + queryID
+}
+
+// Write drops the response and returns its identifier.
+func (s DiscardStore) Write(model, queryID, _ string, _ WriteOptions) (string, error) {
+	return s.Path(model, queryID), nil
+}
+
+// Read reports that no response is stored.
+func (DiscardStore) Read(string, string) (*response.Metadata, string, error) {
+	return nil, "", os.ErrNotExist
+}
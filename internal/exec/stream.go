@@ -0,0 +1,136 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.octolab.org/toolset/tuna/internal/llm"
+)
+
+// PartialSuffix is appended to a response file path while the response
+// is streamed to disk, see Options.StreamToDisk. The partial file holds
+// the content received so far, without front matter; it is removed once
+// the response is written, so one left behind marks an interrupted task.
+const PartialSuffix = ".partial"
+
+// PartialPath returns the file a response is streamed to.
+func (w *ResponseWriter) PartialPath(model, queryID string) string {
+	return w.Path(model, queryID) + PartialSuffix
+}
+
+// CheckStreaming returns an error describing why responses cannot be
+// streamed to disk with the client and store (nil = default file store),
+// or nil if they can. Execute then waits for complete responses.
+func CheckStreaming(client llm.ChatClient, store ResponseStore) error {
+	if _, ok := client.(llm.ChatStreamer); !ok {
+		return errors.New("the client does not support streaming")
+	}
+	if _, ok := store.(*ResponseWriter); store != nil && !ok {
+		return errors.New("the response store is not the file store")
+	}
+	return nil
+}
+
+// chat sends a request, streaming the response into the partial file
+// of the task if Options.StreamToDisk is set and supported by the client
+// and the store, see CheckStreaming, and reporting its content if
+// Options.LiveOutput is set. Otherwise it waits for the complete response,
+// as it also does if a stream for live output cannot be opened.
+func (e *Executor) chat(ctx context.Context, req llm.ChatRequest, model, queryID string, store ResponseStore) (*llm.ChatResponse, error) {
+	streamer, ok := e.llmClient.(llm.ChatStreamer)
+	writer, isFile := store.(*ResponseWriter)
+	toDisk := e.options.StreamToDisk && isFile
+	if !ok || (!toDisk && !e.options.LiveOutput) {
+		return e.llmClient.Chat(ctx, req)
+	}
+
+	onChunk := func(string) {}
+	if e.options.LiveOutput {
+		event := ProgressEvent{Type: EventTaskOutput, Model: model, Provider: e.provider(model), QueryID: queryID}
+		onChunk = func(chunk string) {
+			event.Output = chunk
+			e.notify(event)
+		}
+	}
+	if !toDisk {
+		stream, err := streamer.ChatStream(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, llm.ErrRateLimitDeadline) {
+				return nil, err
+			}
+			// Live output is best effort: fall back to a complete response
+			return e.llmClient.Chat(ctx, req)
+		}
+		return readStream(stream, onChunk)
+	}
+	return streamToFile(ctx, streamer, req, writer.PartialPath(model, queryID), onChunk)
+}
+
+// streamChunks streams a response, passing each delta to onChunk,
+// and returns the complete response.
+func streamChunks(ctx context.Context, streamer llm.ChatStreamer, req llm.ChatRequest, onChunk func(string)) (*llm.ChatResponse, error) {
+	stream, err := streamer.ChatStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return readStream(stream, onChunk)
+}
+
+// readStream passes each delta of an open stream to onChunk, closes
+// the stream and returns the complete response.
+func readStream(stream *llm.ChatStream, onChunk func(string)) (*llm.ChatResponse, error) {
+	defer stream.Close()
+
+	for chunk := range stream.Chunks() {
+		onChunk(chunk)
+	}
+	return stream.Response()
+}
+
+// streamToFile streams a response, appending each delta to the file at
+// path, which is truncated first, before passing it to onChunk. If
+// streaming fails, the file is removed unless ctx was cancelled, i.e.
+// the run was interrupted.
+func streamToFile(ctx context.Context, streamer llm.ChatStreamer, req llm.ChatRequest, path string, onChunk func(string)) (resp *llm.ChatResponse, err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create partial response file: %w", err)
+	}
+	defer func() {
+		if err != nil && !errors.Is(ctx.Err(), context.Canceled) {
+			os.Remove(path)
+		}
+	}()
+
+	var writeErr error
+	resp, err = streamChunks(ctx, streamer, req, func(chunk string) {
+		if writeErr == nil {
+			_, writeErr = file.WriteString(chunk)
+		}
+		onChunk(chunk)
+	})
+	if closeErr := errors.Join(writeErr, file.Close()); closeErr != nil {
+		return nil, fmt.Errorf("failed to write partial response file: %w", closeErr)
+	}
+	return resp, err
+}
+
+// removePartial deletes the partial file of a task, if any.
+// It is a no-op for stores other than the file store.
+func removePartial(store ResponseStore, model, queryID string) error {
+	writer, ok := store.(*ResponseWriter)
+	if !ok {
+		return nil
+	}
+	err := os.Remove(writer.PartialPath(model, queryID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove partial response file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/response"
+)
+
+// plainClient is a chat client that cannot stream.
+type plainClient struct{}
+
+func (plainClient) Chat(context.Context, llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{}, nil
+}
+
+// plainStore is a response store other than the file store.
+type plainStore struct{}
+
+func (plainStore) Path(model, queryID string) string { return model + "/" + queryID }
+func (plainStore) Write(model, queryID, _ string, _ WriteOptions) (string, error) {
+	return model + "/" + queryID, nil
+}
+func (plainStore) Read(string, string) (*response.Metadata, string, error) { return nil, "", nil }
+
+// failingStreamer fails to open every stream with err.
+type failingStreamer struct{ err error }
+
+func (s failingStreamer) ChatStream(context.Context, llm.ChatRequest) (*llm.ChatStream, error) {
+	return nil, s.err
+}
+
+func TestStreamToFile_Error(t *testing.T) {
+	failure := errors.New("connection reset")
+
+	t.Run("removes the partial file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "model", "q001.md"+PartialSuffix)
+
+		_, err := streamToFile(context.Background(), failingStreamer{failure}, llm.ChatRequest{}, path, func(string) {})
+		require.ErrorIs(t, err, failure)
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("keeps the partial file if interrupted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "model", "q001.md"+PartialSuffix)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := streamToFile(ctx, failingStreamer{context.Canceled}, llm.ChatRequest{}, path, func(string) {})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.FileExists(t, path)
+	})
+}
+
+func TestRemovePartial(t *testing.T) {
+	writer := NewResponseWriter(t.TempDir(), "run")
+	path := writer.PartialPath("model", "q001")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("partial"), 0644))
+
+	require.NoError(t, removePartial(writer, "model", "q001"))
+	assert.NoFileExists(t, path)
+	assert.NoError(t, removePartial(writer, "model", "q001"), "missing file")
+}
+
+func TestCheckStreaming(t *testing.T) {
+	streaming := llm.NewClient(&llm.Config{})
+
+	assert.NoError(t, CheckStreaming(streaming, nil))
+	assert.NoError(t, CheckStreaming(streaming, NewResponseWriter(t.TempDir(), "run")))
+	assert.EqualError(t, CheckStreaming(plainClient{}, nil), "the client does not support streaming")
+	assert.EqualError(t, CheckStreaming(streaming, plainStore{}), "the response store is not the file store")
+}
+
+func TestExecutor_Execute_LiveOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{"Hel", "lo"} {
+			_, _ = fmt.Fprintf(w, "data: {\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", delta)
+		}
+		_, _ = fmt.Fprint(w, "data: {\"model\":\"m\",\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2}}\n\ndata: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	client := llm.NewClient(&llm.Config{APIToken: "token", BaseURL: server.URL})
+
+	p, assistantDir := testPlan(t, []string{"m"}, "q1.md")
+	var output []string
+	summary, err := New(p, assistantDir, client, Options{
+		LiveOutput: true,
+		OnProgress: func(event ProgressEvent) {
+			if event.Type == EventTaskOutput {
+				assert.Equal(t, "q1.md", event.QueryID)
+				output = append(output, event.Output)
+			}
+		},
+	}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+	assert.Equal(t, []string{"Hel", "lo"}, output)
+	require.Len(t, summary.Results, 1)
+	assert.Equal(t, "Hello", summary.Results[0].Response)
+	assert.Equal(t, 2, summary.Results[0].OutputTokens)
+	assert.NoFileExists(t, summary.Results[0].OutputPath+PartialSuffix, "only streamed to disk with StreamToDisk")
+}
+
+func TestExecutor_Execute_LiveOutputFallback(t *testing.T) {
+	var streamed, buffered int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream bool `json:"stream"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		if body.Stream {
+			streamed++
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"stream_options is not supported","type":"invalid_request_error"}}`)
+			return
+		}
+		buffered++
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	}))
+	t.Cleanup(server.Close)
+	client := llm.NewClient(&llm.Config{APIToken: "token", BaseURL: server.URL})
+
+	p, assistantDir := testPlan(t, []string{"m"}, "q1.md")
+	summary, err := New(p, assistantDir, client, Options{LiveOutput: true}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+	assert.Equal(t, 1, streamed, "a stream is tried first")
+	assert.Equal(t, 1, buffered, "then the request is sent without streaming")
+	require.Len(t, summary.Results, 1)
+	assert.Equal(t, "Hello", summary.Results[0].Response)
+	assert.Equal(t, 2, summary.Results[0].OutputTokens)
+}
@@ -0,0 +1,102 @@
+package exec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// UsageFileName is the name of the token usage ledger in the plan output directory.
+const UsageFileName = "usage.json"
+
+// TaskUsage holds token usage of a single completed task.
+type TaskUsage struct {
+	Model   string `json:"model"`
+	QueryID string `json:"query_id"`
+	Prompt  int    `json:"prompt_tokens"`
+	Output  int    `json:"output_tokens"`
+}
+
+// UsageLedger persists per-task token usage across runs, so totals survive
+// interrupted executions. Usage of a re-executed task adds to its previous
+// entry, as every request made for it was paid for.
+type UsageLedger struct {
+	mu    sync.Mutex
+	path  string
+	tasks []TaskUsage
+}
+
+// LoadUsage reads the ledger from the plan output directory.
+// A missing file yields an empty ledger.
+func LoadUsage(outputDir string) (*UsageLedger, error) {
+	ledger := &UsageLedger{path: filepath.Join(outputDir, UsageFileName)}
+
+	data, err := os.ReadFile(ledger.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &ledger.tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file %s: %w", ledger.path, err)
+	}
+
+	return ledger, nil
+}
+
+// Record adds usage of a task and writes the ledger to disk.
+func (l *UsageLedger) Record(usage TaskUsage) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recorded := false
+	for i, t := range l.tasks {
+		if t.Model == usage.Model && t.QueryID == usage.QueryID {
+			l.tasks[i].Prompt += usage.Prompt
+			l.tasks[i].Output += usage.Output
+			recorded = true
+			break
+		}
+	}
+	if !recorded {
+		l.tasks = append(l.tasks, usage)
+	}
+
+	return l.save()
+}
+
+// Total returns cumulative token usage of all recorded tasks.
+func (l *UsageLedger) Total() TokenUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var total TokenUsage
+	for _, t := range l.tasks {
+		total.Prompt += t.Prompt
+		total.Output += t.Output
+	}
+	return total
+}
+
+// save writes the ledger atomically via a temporary file.
+func (l *UsageLedger) save() error {
+	data, err := json.MarshalIndent(l.tasks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+
+	return nil
+}
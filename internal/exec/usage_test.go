@@ -0,0 +1,60 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/llm"
+)
+
+func TestUsageLedger_Record(t *testing.T) {
+	dir := t.TempDir()
+
+	ledger, err := LoadUsage(dir)
+	require.NoError(t, err)
+	require.NoError(t, ledger.Record(TaskUsage{Model: "m", QueryID: "q1", Prompt: 10, Output: 5}))
+	require.NoError(t, ledger.Record(TaskUsage{Model: "m", QueryID: "q2", Prompt: 1, Output: 1}))
+	require.NoError(t, ledger.Record(TaskUsage{Model: "m", QueryID: "q1", Prompt: 10, Output: 7}))
+
+	reloaded, err := LoadUsage(dir)
+	require.NoError(t, err)
+	assert.Equal(t, TokenUsage{Prompt: 21, Output: 13}, reloaded.Total(), "re-executed tasks accumulate")
+}
+
+// failingClient fails requests whose message contains fail.
+type failingClient struct {
+	llm.ChatClient
+	fail string
+}
+
+func (c failingClient) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if strings.Contains(req.UserMessage, c.fail) {
+		return nil, errors.New("connection reset")
+	}
+	return c.ChatClient.Chat(ctx, req)
+}
+
+func TestExecutor_Execute_CumulativeTokens(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"model"}, "q1.md", "q2.md")
+	client := &roundRobinClient{providers: []string{"provider"}}
+
+	first, err := New(p, assistantDir, failingClient{client, "q2"}, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, first.Errors, 1)
+	assert.Equal(t, TokenUsage{Prompt: 10, Output: 1}, first.CumulativeTokens)
+
+	resumed, err := New(p, assistantDir, client, Options{Continue: true}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, resumed.Errors)
+	assert.Equal(t, 10, resumed.TotalTokens.Prompt, "only q2 was sent")
+	assert.Equal(t, TokenUsage{Prompt: 20, Output: 2}, resumed.CumulativeTokens)
+
+	forced, err := New(p, assistantDir, client, Options{Force: true}).Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, TokenUsage{Prompt: 40, Output: 4}, forced.CumulativeTokens, "re-executed tasks are paid again")
+}
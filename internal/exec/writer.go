@@ -4,24 +4,34 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/plan"
 	"go.octolab.org/toolset/tuna/internal/response"
 )
 
 // ResponseWriter handles saving LLM responses to files.
 type ResponseWriter struct {
-	baseDir string // {AssistantID}/Output/{plan_id}
+	baseDir string // {AssistantID}/Output/{plan_id or name slug}
 }
 
-// NewResponseWriter creates a writer for the given plan output directory.
-func NewResponseWriter(assistantDir, planID string) *ResponseWriter {
+// NewResponseWriter creates a writer for the given plan output directory,
+// named as returned by plan.Plan.DirName.
+func NewResponseWriter(assistantDir, dirName string) *ResponseWriter {
 	return &ResponseWriter{
-		baseDir: filepath.Join(assistantDir, "Output", planID),
+		baseDir: filepath.Join(assistantDir, "Output", dirName),
 	}
 }
 
+// Path returns the response file path for a model and query:
+// {baseDir}/{model_hash}/{query_id}_response.md
+func (w *ResponseWriter) Path(model, queryID string) string {
+	// Build response filename: query_001.md -> query_001_response.md,
+	// queries.md#2 -> queries_2_response.md
+	return filepath.Join(w.baseDir, ModelHash(model), plan.ResponseBaseName(queryID)+"_response.md")
+}
+
 // WriteOptions contains metadata to embed in the response file.
 type WriteOptions struct {
 	ProviderURL  string
@@ -29,24 +39,35 @@ type WriteOptions struct {
 	Duration     time.Duration
 	InputTokens  int
 	OutputTokens int
+	Chars        int
+	Words        int
+	Temperature  float64
+	Cost         float64
+	SystemPrompt string                // Recorded as a hash
+	RequestHash  string                // See RequestHash
+	QueryWrapped bool                  // Query prefix/suffix was applied
+	Moderation   *llm.ModerationResult // nil if moderation is disabled
+
+	// Precision truncates ExecutedAt (0 = response.DefaultTimestampPrecision).
+	Precision time.Duration
+
+	// HTTPStatus and TTFB break down the request latency, see llm.ChatResponse.
+	HTTPStatus int
+	TTFB       time.Duration
 }
 
 // Write saves a response to the appropriate file with metadata.
 // Path: {baseDir}/{model_hash}/{query_id}_response.md
-// Note: This completely overwrites any existing file, including previous ratings.
+// Note: This completely overwrites any existing file, including previous ratings;
+// only its execution history is carried over.
 func (w *ResponseWriter) Write(model, queryID, content string, opts WriteOptions) (string, error) {
-	modelDir := filepath.Join(w.baseDir, ModelHash(model))
+	responsePath := w.Path(model, queryID)
 
 	// Create model directory if not exists
-	if err := os.MkdirAll(modelDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(responsePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Build response filename: query_001.md -> query_001_response.md
-	baseName := strings.TrimSuffix(queryID, filepath.Ext(queryID))
-	responseFile := baseName + "_response.md"
-	responsePath := filepath.Join(modelDir, responseFile)
-
 	// Build metadata (rating fields empty = omitted in YAML)
 	meta := &response.Metadata{
 		Provider:   opts.ProviderURL,
@@ -54,9 +75,33 @@ func (w *ResponseWriter) Write(model, queryID, content string, opts WriteOptions
 		Duration:   opts.Duration,
 		Input:      opts.InputTokens,
 		Output:     opts.OutputTokens,
-		ExecutedAt: time.Now(),
+		ExecutedAt: response.Now(opts.Precision),
+		Chars:      opts.Chars,
+		Words:      opts.Words,
+
+		Temperature: &opts.Temperature,
+		Cost:        opts.Cost,
+		HTTPStatus:  opts.HTTPStatus,
+		TTFB:        opts.TTFB,
+
+		RequestHash:  opts.RequestHash,
+		QueryWrapped: opts.QueryWrapped,
+
 		// Rating and RatedAt will be set by tuna view
 	}
+	if opts.SystemPrompt != "" {
+		meta.SystemPromptHash = ContentHash(opts.SystemPrompt)
+	}
+	if prev, _, err := response.Parse(responsePath); err == nil {
+		meta.Supersede(prev)
+	}
+	if opts.Moderation != nil {
+		meta.Moderation = response.ModerationPassed
+		if opts.Moderation.Flagged {
+			meta.Moderation = response.ModerationFlagged
+			meta.ModerationCategories = opts.Moderation.Categories
+		}
+	}
 
 	// Format content with metadata
 	formatted, err := response.Format(meta, content)
@@ -65,9 +110,22 @@ func (w *ResponseWriter) Write(model, queryID, content string, opts WriteOptions
 	}
 
 	// Write response content
-	if err := os.WriteFile(responsePath, []byte(formatted), 0644); err != nil {
-		return "", fmt.Errorf("failed to write response file: %w", err)
+	if err := WriteResponseFile(responsePath, []byte(formatted)); err != nil {
+		return "", err
 	}
 
 	return responsePath, nil
 }
+
+// WriteResponseFile replaces a response file atomically via a temporary
+// file, so that a concurrent tuna view never reads it half written.
+func WriteResponseFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write response file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write response file: %w", err)
+	}
+	return nil
+}
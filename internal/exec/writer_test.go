@@ -0,0 +1,181 @@
+package exec
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/response"
+)
+
+func TestResponseWriter_Write_SystemPromptHash(t *testing.T) {
+	writer := NewResponseWriter(t.TempDir(), "run")
+
+	path, err := writer.Write("model", "q1.md", "answer", WriteOptions{Model: "model"})
+	require.NoError(t, err)
+	meta, _, err := response.Parse(path)
+	require.NoError(t, err)
+	assert.Empty(t, meta.SystemPromptHash)
+
+	path, err = writer.Write("model", "q2.md", "answer", WriteOptions{Model: "model", SystemPrompt: "Be brief."})
+	require.NoError(t, err)
+	meta, _, err = response.Parse(path)
+	require.NoError(t, err)
+	assert.Equal(t, ContentHash("Be brief."), meta.SystemPromptHash)
+	assert.NoFileExists(t, path+".tmp", "the file is replaced atomically")
+}
+
+func TestResponseWriter_Write_Timing(t *testing.T) {
+	writer := NewResponseWriter(t.TempDir(), "run")
+
+	path, err := writer.Write("model", "q1.md", "answer", WriteOptions{
+		Model:      "model",
+		Duration:   2 * time.Second,
+		HTTPStatus: 200,
+		TTFB:       300 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	meta, _, err := response.Parse(path)
+	require.NoError(t, err)
+	assert.Equal(t, 200, meta.HTTPStatus)
+	assert.Equal(t, 300*time.Millisecond, meta.TTFB)
+	assert.Equal(t, 2*time.Second, meta.Duration)
+}
+
+func TestResponseWriter_Write_Cost(t *testing.T) {
+	tests := map[string]struct {
+		cost     float64
+		wantLine string
+	}{
+		"priced":   {cost: 0.0123, wantLine: "\ncost: 0.0123\n"},
+		"unpriced": {cost: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			writer := NewResponseWriter(t.TempDir(), "run")
+			path, err := writer.Write("model", "q1.md", "answer", WriteOptions{Model: "model", Cost: tc.cost})
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if tc.wantLine != "" {
+				assert.Contains(t, string(data), tc.wantLine)
+			} else {
+				assert.NotContains(t, string(data), "cost:", "omitted when unpriced")
+			}
+
+			meta, _, err := response.Parse(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.cost, meta.Cost)
+		})
+	}
+}
+
+func TestResponseWriter_Write_Precision(t *testing.T) {
+	tests := map[string]struct {
+		precision time.Duration
+		want      time.Duration
+		wantLine  string // Pattern of the executed_at line
+	}{
+		"default":     {want: time.Second, wantLine: `(?m)^executed_at: \S+:\d\d(Z|[+-]\d\d:\d\d)$`},
+		"millisecond": {precision: time.Millisecond, want: time.Millisecond, wantLine: `(?m)^executed_at: \S+:\d\d(\.\d{1,3})?(Z|[+-]\d\d:\d\d)$`},
+		"minute":      {precision: time.Minute, want: time.Minute, wantLine: `(?m)^executed_at: \S+:00(Z|[+-]\d\d:\d\d)$`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			writer := NewResponseWriter(t.TempDir(), "run")
+			before := time.Now().Truncate(tc.want)
+			path, err := writer.Write("model", "q1.md", "answer", WriteOptions{Model: "model", Precision: tc.precision})
+			require.NoError(t, err)
+			after := time.Now()
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Regexp(t, tc.wantLine, string(data))
+
+			meta, _, err := response.Parse(path)
+			require.NoError(t, err)
+			assert.True(t, meta.ExecutedAt.Equal(meta.ExecutedAt.Truncate(tc.want)), "rounded to the precision")
+			assert.False(t, meta.ExecutedAt.Before(before))
+			assert.False(t, meta.ExecutedAt.After(after))
+		})
+	}
+}
+
+func TestResponseWriter_Write_History(t *testing.T) {
+	tests := map[string]struct {
+		writes      int
+		wantHistory int
+	}{
+		"first execution":    {writes: 1, wantHistory: 0},
+		"re-execution":       {writes: 2, wantHistory: 1},
+		"history is capped":  {writes: response.MaxHistory + 3, wantHistory: response.MaxHistory},
+		"cap exactly filled": {writes: response.MaxHistory + 1, wantHistory: response.MaxHistory},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			writer := NewResponseWriter(t.TempDir(), "run")
+			var path string
+			for i := 1; i <= tc.writes; i++ {
+				var err error
+				path, err = writer.Write("model", "q1.md", "answer", WriteOptions{Model: "model", OutputTokens: i})
+				require.NoError(t, err)
+			}
+
+			meta, _, err := response.Parse(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.writes, meta.Output, "the latest execution is current")
+			require.Len(t, meta.History, tc.wantHistory)
+			for i, run := range meta.History {
+				// Oldest first, ending with the execution before the current one
+				assert.Equal(t, tc.writes-tc.wantHistory+i, run.Output)
+				assert.Equal(t, "model", run.Model)
+				assert.False(t, run.ExecutedAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestExecutor_Execute_TextCounts(t *testing.T) {
+	p, assistantDir := testPlan(t, []string{"model"}, "q1.md")
+	client := &scriptedClient{replies: []string{"Привет, мир!\n\nTwo  paragraphs here."}}
+
+	summary, err := New(p, assistantDir, client, Options{}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, summary.Results, 1)
+	result := summary.Results[0]
+	assert.Equal(t, 35, result.Chars, "runes, not bytes")
+	assert.Equal(t, 5, result.Words)
+
+	meta, _, err := response.Parse(result.OutputPath)
+	require.NoError(t, err)
+	assert.Equal(t, 35, meta.Chars)
+	assert.Equal(t, 5, meta.Words)
+}
+
+func TestCountText(t *testing.T) {
+	tests := map[string]struct {
+		content      string
+		chars, words int
+	}{
+		"empty":      {content: "", chars: 0, words: 0},
+		"whitespace": {content: " \n\t", chars: 3, words: 0},
+		"words":      {content: "one two\nthree", chars: 13, words: 3},
+		"unicode":    {content: "日本語 テキスト", chars: 8, words: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			chars, words := response.CountText(tc.content)
+			assert.Equal(t, tc.chars, chars)
+			assert.Equal(t, tc.words, words)
+		})
+	}
+}
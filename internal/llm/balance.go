@@ -0,0 +1,49 @@
+package llm
+
+import "sync"
+
+// balancer spreads requests for a model across the providers serving it,
+// in proportion to their weights, using smooth weighted round-robin:
+// with weights 3 and 1 the sequence is a, a, b, a, and so on.
+// It is safe for concurrent use.
+type balancer struct {
+	mu        sync.Mutex
+	providers []string
+	weights   []int
+	current   []int
+	total     int
+}
+
+// newBalancer creates a balancer over the providers with the given
+// weights; non-positive weights count as 1.
+func newBalancer(providers []string, weights []int) *balancer {
+	b := &balancer{
+		providers: providers,
+		weights:   make([]int, len(providers)),
+		current:   make([]int, len(providers)),
+	}
+	for i, w := range weights {
+		if w <= 0 {
+			w = 1
+		}
+		b.weights[i] = w
+		b.total += w
+	}
+	return b
+}
+
+// next returns the provider to send the next request to.
+func (b *balancer) next() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	best := 0
+	for i, w := range b.weights {
+		b.current[i] += w
+		if b.current[i] > b.current[best] {
+			best = i
+		}
+	}
+	b.current[best] -= b.total
+	return b.providers[best]
+}
@@ -2,11 +2,20 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"sort"
 	"time"
 
 	api "github.com/sashabaranov/go-openai"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -16,8 +25,29 @@ const (
 
 // Config holds LLM client configuration.
 type Config struct {
-	APIToken string
-	BaseURL  string
+	APIToken       string
+	BaseURL        string
+	ConnectTimeout time.Duration // Dial and TLS handshake timeout (0 = transport default)
+	Timeout        time.Duration // Whole chat request timeout, including retries (0 = none)
+
+	// Proxy settings override the corresponding environment variables when set.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+
+	// Transport middleware, applied in order: rate limit, retry, logging, headers.
+	// The Router leaves RateLimiter unset and waits for its own limiters
+	// before a request is sent, so that global and provider limits are
+	// reserved together and the wait is reported in ChatResponse.
+	RateLimiter     *rate.Limiter                    // nil = unlimited
+	MaxRetries      int                              // 0 = no retries
+	RetryJitter     Jitter                           // Empty = no jitter
+	RetryMaxElapsed time.Duration                    // Total retry time budget (0 = unlimited)
+	Logf            func(format string, args ...any) // nil = no request logging
+	Headers         map[string]string                // Extra headers for every request
+
+	// CaptureRaw records the raw JSON body of chat responses in ChatResponse.Raw.
+	CaptureRaw bool
 }
 
 // ConfigFromEnv reads LLM configuration from environment variables.
@@ -38,9 +68,95 @@ func ConfigFromEnv() (*Config, error) {
 	}, nil
 }
 
+// middlewares returns the transport middleware stack described by the config.
+func (cfg *Config) middlewares() []Middleware {
+	var stack []Middleware
+	if cfg.RateLimiter != nil {
+		stack = append(stack, RateLimit(cfg.RateLimiter))
+	}
+	if cfg.MaxRetries > 0 {
+		stack = append(stack, Retry(RetryPolicy{
+			MaxRetries: cfg.MaxRetries,
+			Backoff:    DefaultRetryBackoff,
+			Jitter:     cfg.RetryJitter,
+			MaxElapsed: cfg.RetryMaxElapsed,
+		}))
+	}
+	if cfg.Logf != nil {
+		stack = append(stack, Logging(cfg.Logf))
+	}
+	if len(cfg.Headers) > 0 {
+		stack = append(stack, Headers(cfg.Headers))
+	}
+	if cfg.CaptureRaw {
+		// Innermost, so that only the final attempt is recorded
+		stack = append(stack, Tap())
+	}
+	// Below Tap, which reads the body, so the first byte is still timed
+	stack = append(stack, Timing())
+	return stack
+}
+
+// hasProxy reports whether any proxy setting is configured.
+func (cfg *Config) hasProxy() bool {
+	return cfg.HTTPProxy != "" || cfg.HTTPSProxy != "" || cfg.NoProxy != ""
+}
+
+// proxy returns the proxy function combining configured settings
+// with the environment ones (HTTP_PROXY, HTTPS_PROXY, NO_PROXY).
+func (cfg *Config) proxy() func(*http.Request) (*url.URL, error) {
+	settings := httpproxy.FromEnvironment()
+	if cfg.HTTPProxy != "" {
+		settings.HTTPProxy = cfg.HTTPProxy
+	}
+	if cfg.HTTPSProxy != "" {
+		settings.HTTPSProxy = cfg.HTTPSProxy
+	}
+	if cfg.NoProxy != "" {
+		settings.NoProxy = cfg.NoProxy
+	}
+
+	proxyFunc := settings.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// transport returns the base HTTP transport, or http.DefaultTransport
+// if the config does not customize it.
+func (cfg *Config) transport() http.RoundTripper {
+	if cfg.ConnectTimeout <= 0 && !cfg.hasProxy() {
+		return http.DefaultTransport
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ConnectTimeout > 0 {
+		t.DialContext = (&net.Dialer{
+			Timeout:   cfg.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		t.TLSHandshakeTimeout = cfg.ConnectTimeout
+	}
+	if cfg.hasProxy() {
+		t.Proxy = cfg.proxy()
+	}
+	return t
+}
+
+// ErrRequestTimeout is returned when a chat request exceeds Config.Timeout.
+var ErrRequestTimeout = errors.New("request timed out")
+
+// ErrRateLimitDeadline is returned when the rate limiter would release
+// the next request only after the request's context deadline.
+var ErrRateLimitDeadline = errors.New("rate limit wait would exceed deadline")
+
 // Client wraps the OpenAI-compatible client for LLM interactions.
 type Client struct {
-	client *api.Client
+	client     *api.Client
+	captureRaw bool
+	timeout    time.Duration
+	baseURL    string
+	proxy      func(*http.Request) (*url.URL, error) // nil = direct connection
 }
 
 // NewClient creates a new LLM client with the given configuration.
@@ -48,9 +164,35 @@ func NewClient(cfg *Config) *Client {
 	config := api.DefaultConfig(cfg.APIToken)
 	config.BaseURL = cfg.BaseURL
 
-	return &Client{
-		client: api.NewClientWithConfig(config),
+	transport := cfg.transport()
+	if middlewares := cfg.middlewares(); len(middlewares) > 0 || transport != http.DefaultTransport {
+		config.HTTPClient = &http.Client{Transport: Chain(transport, middlewares...)}
+	}
+
+	client := &Client{
+		client:     api.NewClientWithConfig(config),
+		captureRaw: cfg.CaptureRaw,
+		timeout:    cfg.Timeout,
+		baseURL:    cfg.BaseURL,
+	}
+	if t, ok := transport.(*http.Transport); ok {
+		client.proxy = t.Proxy
+	}
+	return client
+}
+
+// Proxy returns the proxy that requests to the base URL go through,
+// or nil if they connect directly. It applies the configured proxy
+// settings and the environment ones exactly as the transport does.
+func (c *Client) Proxy() (*url.URL, error) {
+	if c.proxy == nil {
+		return nil, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, c.baseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
+	return c.proxy(req)
 }
 
 // ChatRequest holds parameters for a chat completion request.
@@ -60,21 +202,46 @@ type ChatRequest struct {
 	UserMessage  string
 	Temperature  float64
 	MaxTokens    int
+	TopP         float64 // 0 = provider default
+	Seed         *int    // nil = not sent
 }
 
 // ChatResponse holds the response from a chat completion.
 type ChatResponse struct {
 	Content      string
-	Model        string        // Resolved model name from API response
-	ProviderURL  string        // Provider base URL (set by Router)
+	Model        string // Resolved model name from API response
+	Provider     string // Provider name (set by Router)
+	ProviderURL  string // Provider base URL (set by Router)
 	PromptTokens int
 	OutputTokens int
-	Duration     time.Duration // Request execution time (set by Router)
+	FinishReason string            // Why generation stopped, e.g. "stop" or "length"
+	Duration     time.Duration     // Request execution time (set by Router)
+	Moderation   *ModerationResult // Moderation pre-check result (set by Router, nil if disabled)
+
+	// RateLimitWait is time spent waiting for the rate limiter (set by Router).
+	RateLimitWait time.Duration
+	// Cost is computed from token usage and configured prices (set by Router).
+	Cost float64
+	// Raw is the provider's response body, if raw capture is enabled.
+	Raw json.RawMessage
+
+	// HTTPStatus is the status code of the final HTTP response.
+	HTTPStatus int
+	// TTFB is the time from sending the final HTTP request to receiving
+	// the first byte of the response body, the first chunk when streaming.
+	// Comparing it to Duration separates network latency from generation.
+	TTFB time.Duration
 }
 
-// Chat sends a chat completion request and returns the response.
-func (c *Client) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	resp, err := c.client.CreateChatCompletion(ctx, api.ChatCompletionRequest{
+// ModerationResult holds the outcome of a moderation check.
+type ModerationResult struct {
+	Flagged    bool
+	Categories []string // Names of flagged categories, sorted
+}
+
+// chatCompletionRequest converts a request to the API representation.
+func chatCompletionRequest(req ChatRequest) api.ChatCompletionRequest {
+	return api.ChatCompletionRequest{
 		Model: req.Model,
 		Messages: []api.ChatCompletionMessage{
 			{Role: api.ChatMessageRoleSystem, Content: req.SystemPrompt},
@@ -82,19 +249,132 @@ func (c *Client) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, erro
 		},
 		Temperature: float32(req.Temperature),
 		MaxTokens:   req.MaxTokens,
-	})
+		TopP:        float32(req.TopP),
+		Seed:        req.Seed,
+	}
+}
+
+// Chat sends a chat completion request and returns the response.
+// The request is bounded by the configured timeout and the deadline of ctx,
+// whichever comes first; the former fails with ErrRequestTimeout.
+func (c *Client) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	reqCtx := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var capture *rawCapture
+	if c.captureRaw {
+		reqCtx, capture = withRawCapture(reqCtx)
+	}
+	reqCtx, timing := withTiming(reqCtx)
+
+	resp, err := c.client.CreateChatCompletion(reqCtx, chatCompletionRequest(req))
 	if err != nil {
-		return nil, fmt.Errorf("chat completion failed: %w", err)
+		return nil, c.timeoutError(ctx, reqCtx, fmt.Errorf("chat completion failed: %w", err))
 	}
 
 	if len(resp.Choices) == 0 {
 		return nil, fmt.Errorf("no response choices returned")
 	}
 
-	return &ChatResponse{
+	result := &ChatResponse{
 		Content:      resp.Choices[0].Message.Content,
 		Model:        resp.Model,
 		PromptTokens: resp.Usage.PromptTokens,
 		OutputTokens: resp.Usage.CompletionTokens,
+		FinishReason: string(resp.Choices[0].FinishReason),
+		HTTPStatus:   timing.status,
+		TTFB:         timing.ttfb,
+	}
+	if capture != nil {
+		result.Raw = capture.body
+	}
+
+	return result, nil
+}
+
+// timeoutError returns ErrRequestTimeout in place of err if the request
+// context reqCtx, but not the caller's ctx, exceeded the request timeout.
+func (c *Client) timeoutError(ctx, reqCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrRequestTimeout, c.timeout)
+	}
+	return err
+}
+
+// Catalog is the live model list of a provider.
+type Catalog struct {
+	Models []string // Sorted model IDs
+	// RequestsLimit is the x-ratelimit-limit-requests header of the
+	// response, usually requests per minute (0 if not reported).
+	RequestsLimit int
+}
+
+// ListModels returns the IDs of models served by the provider, sorted.
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	catalog, err := c.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Models, nil
+}
+
+// Catalog fetches the provider's models along with its reported rate limit.
+func (c *Client) Catalog(ctx context.Context) (*Catalog, error) {
+	resp, err := c.client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list models failed: %w", err)
+	}
+
+	ids := make([]string, len(resp.Models))
+	for i, m := range resp.Models {
+		ids[i] = m.ID
+	}
+	sort.Strings(ids)
+
+	return &Catalog{
+		Models:        ids,
+		RequestsLimit: resp.GetRateLimitHeaders().LimitRequests,
 	}, nil
 }
+
+// Moderate runs the provider's moderation endpoint on the given text.
+func (c *Client) Moderate(ctx context.Context, text string) (*ModerationResult, error) {
+	resp, err := c.client.Moderations(ctx, api.ModerationRequest{
+		Input: text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("moderation failed: %w", err)
+	}
+
+	result := &ModerationResult{}
+	for _, r := range resp.Results {
+		if !r.Flagged {
+			continue
+		}
+		result.Flagged = true
+
+		// Categories are a struct of booleans; collect the flagged ones by JSON name
+		data, err := json.Marshal(r.Categories)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read moderation categories: %w", err)
+		}
+		var categories map[string]bool
+		if err := json.Unmarshal(data, &categories); err != nil {
+			return nil, fmt.Errorf("failed to read moderation categories: %w", err)
+		}
+		for name, flagged := range categories {
+			if flagged {
+				result.Categories = append(result.Categories, name)
+			}
+		}
+	}
+	// Several results may flag the same category
+	sort.Strings(result.Categories)
+	result.Categories = slices.Compact(result.Categories)
+
+	return result, nil
+}
@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Chat_SamplingParameters(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(&Config{APIToken: "token", BaseURL: server.URL})
+
+	seed := 0
+	_, err := client.Chat(context.Background(), ChatRequest{Model: "m", UserMessage: "Hi", TopP: 0.9, Seed: &seed})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.9, body["top_p"], 1e-6)
+	assert.Equal(t, float64(0), body["seed"], "a zero seed is still sent")
+
+	_, err = client.Chat(context.Background(), ChatRequest{Model: "m", UserMessage: "Hi"})
+	require.NoError(t, err)
+	assert.NotContains(t, body, "top_p")
+	assert.NotContains(t, body, "seed")
+}
+
+func TestClient_Chat_Timing(t *testing.T) {
+	client := NewClient(&Config{APIToken: "token", BaseURL: chatServer(t, "ok").URL})
+
+	resp, err := client.Chat(context.Background(), ChatRequest{Model: "m", UserMessage: "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.HTTPStatus)
+	assert.Positive(t, resp.TTFB)
+}
+
+// stalledListener accepts TCP connections and never answers on them,
+// like a host whose TLS endpoint hangs during the handshake.
+func stalledListener(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				for _, c := range conns {
+					_ = c.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestClient_Chat_ConnectTimeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond) // Slow generation, connected quickly
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(slow.Close)
+
+	tests := map[string]struct {
+		baseURL string
+		wantErr string
+	}{
+		"stalled handshake": {
+			baseURL: "https://" + stalledListener(t) + "/v1",
+			wantErr: "TLS handshake timeout",
+		},
+		"slow generation": {
+			baseURL: slow.URL,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(&Config{APIToken: "token", BaseURL: tc.baseURL, ConnectTimeout: 100 * time.Millisecond})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			start := time.Now()
+			_, err := client.Chat(ctx, ChatRequest{Model: "m", UserMessage: "Hi"})
+			if tc.wantErr == "" {
+				require.NoError(t, err, "the connect timeout does not bound generation")
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+			assert.Less(t, time.Since(start), time.Second, "fails at the connect timeout, not the request deadline")
+		})
+	}
+}
+
+func TestClient_Chat_Proxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"via proxy"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(proxy.Close)
+	t.Setenv("HTTP_PROXY", "")
+
+	// llm.test does not resolve, so only a proxied request can succeed
+	client := NewClient(&Config{APIToken: "token", BaseURL: "http://llm.test/v1", HTTPProxy: proxy.URL})
+	resp, err := client.Chat(context.Background(), ChatRequest{Model: "m", UserMessage: "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "via proxy", resp.Content)
+	assert.Equal(t, []string{"http://llm.test/v1/chat/completions"}, proxied)
+}
+
+func TestClient_Proxy(t *testing.T) {
+	tests := map[string]struct {
+		cfg  Config
+		env  map[string]string
+		want string
+	}{
+		"direct": {
+			cfg: Config{BaseURL: "https://api.example.com/v1"},
+		},
+		"https proxy": {
+			cfg:  Config{BaseURL: "https://api.example.com/v1", HTTPSProxy: "http://proxy.corp:3128"},
+			want: "http://proxy.corp:3128",
+		},
+		"http proxy is not used for https": {
+			cfg: Config{BaseURL: "https://api.example.com/v1", HTTPProxy: "http://proxy.corp:3128"},
+		},
+		"configured overrides environment": {
+			cfg:  Config{BaseURL: "https://api.example.com/v1", HTTPSProxy: "http://proxy.corp:3128"},
+			env:  map[string]string{"HTTPS_PROXY": "http://env.proxy:8080"},
+			want: "http://proxy.corp:3128",
+		},
+		"environment": {
+			cfg:  Config{BaseURL: "https://api.example.com/v1", NoProxy: "internal.corp"},
+			env:  map[string]string{"HTTPS_PROXY": "http://env.proxy:8080"},
+			want: "http://env.proxy:8080",
+		},
+		"no_proxy overrides environment": {
+			cfg: Config{BaseURL: "https://api.example.com/v1", NoProxy: "example.com"},
+			env: map[string]string{"HTTPS_PROXY": "http://env.proxy:8080"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+				t.Setenv(key, tc.env[key])
+			}
+
+			proxy, err := NewClient(&tc.cfg).Proxy()
+			require.NoError(t, err)
+			if tc.want == "" {
+				assert.Nil(t, proxy)
+				return
+			}
+			require.NotNil(t, proxy)
+			assert.Equal(t, tc.want, proxy.String())
+		})
+	}
+}
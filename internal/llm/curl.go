@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+// CurlCommand returns a curl command reproducing a chat completion request
+// sent to provider p. The API token is never included: api_token_env, if
+// set, is referenced as a shell variable, otherwise a placeholder is used.
+// Provider headers are included, with "$VAR" values kept as references.
+func CurlCommand(p *config.Provider, req ChatRequest) (string, error) {
+	body, err := json.Marshal(chatCompletionRequest(req))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	auth := shellQuote("Authorization: Bearer <redacted>")
+	if p.APITokenEnv != "" {
+		auth = fmt.Sprintf(`"Authorization: Bearer $%s"`, p.APITokenEnv)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("curl -sS ")
+	sb.WriteString(shellQuote(strings.TrimSuffix(p.BaseURL, "/") + "/chat/completions"))
+	sb.WriteString(" \\\n  -H ")
+	sb.WriteString(shellQuote("Content-Type: application/json"))
+	sb.WriteString(" \\\n  -H ")
+	sb.WriteString(auth)
+	for _, name := range slices.Sorted(maps.Keys(p.Headers)) {
+		sb.WriteString(" \\\n  -H ")
+		sb.WriteString(curlHeader(name, p.Headers[name]))
+	}
+	sb.WriteString(" \\\n  -d ")
+	sb.WriteString(shellQuote(string(body)))
+
+	return sb.String(), nil
+}
+
+// curlHeader returns a quoted curl header argument. A value referring
+// to an environment variable is expanded by the shell, so that secrets
+// are not printed.
+func curlHeader(name, value string) string {
+	if env, ok := config.EnvReference(value); ok {
+		return shellQuote(name+": ") + `"$` + env + `"`
+	}
+	return shellQuote(name + ": " + value)
+}
+
+// shellQuote quotes s for POSIX shells using single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
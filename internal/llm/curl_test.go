@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+func TestCurlCommand(t *testing.T) {
+	req := ChatRequest{Model: "gpt-4o", UserMessage: "It's fine", MaxTokens: 10}
+
+	t.Run("token variable", func(t *testing.T) {
+		p := &config.Provider{BaseURL: "https://api.openai.com/v1/", APITokenEnv: "OPENAI_API_KEY"}
+
+		curl, err := CurlCommand(p, req)
+		require.NoError(t, err)
+		assert.Contains(t, curl, "curl -sS 'https://api.openai.com/v1/chat/completions'")
+		assert.Contains(t, curl, `-H "Authorization: Bearer $OPENAI_API_KEY"`)
+		assert.Contains(t, curl, `"content":"It'\''s fine"`)
+	})
+
+	t.Run("direct token", func(t *testing.T) {
+		p := &config.Provider{BaseURL: "http://localhost:11434/v1", APIToken: "secret"}
+
+		curl, err := CurlCommand(p, req)
+		require.NoError(t, err)
+		assert.Contains(t, curl, "-H 'Authorization: Bearer <redacted>'")
+		assert.NotContains(t, curl, "secret")
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		p := &config.Provider{
+			BaseURL: "https://gateway.internal/v1",
+			Headers: map[string]string{"X-Team": "ml", "X-Gateway-Key": "$GATEWAY_KEY"},
+		}
+		t.Setenv("GATEWAY_KEY", "secret")
+
+		curl, err := CurlCommand(p, req)
+		require.NoError(t, err)
+		assert.Contains(t, curl, `-H 'X-Gateway-Key: '"$GATEWAY_KEY" \`+"\n  -H 'X-Team: ml'")
+		assert.NotContains(t, curl, "secret")
+	})
+}
@@ -8,5 +8,28 @@ type ChatClient interface {
 	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
 }
 
+// ChatStreamer is implemented by chat clients that can stream responses.
+type ChatStreamer interface {
+	// ChatStream sends a chat completion request and streams the response.
+	ChatStream(ctx context.Context, req ChatRequest) (*ChatStream, error)
+}
+
+// ModelResolver resolves a model name or alias to its full name and provider.
+type ModelResolver interface {
+	// ResolveModel returns full model name and provider name for a given model or alias.
+	ResolveModel(model string) (fullName, provider string)
+}
+
+// RoutingClient is a chat client that routes models to providers.
+type RoutingClient interface {
+	ChatClient
+	ModelResolver
+}
+
 // Compile-time interface implementation checks.
-var _ ChatClient = (*Client)(nil)
+var (
+	_ ChatClient    = (*Client)(nil)
+	_ ChatStreamer  = (*Client)(nil)
+	_ ChatStreamer  = (*Router)(nil)
+	_ ModelResolver = (*Router)(nil)
+)
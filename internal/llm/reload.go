@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+// ReloadingRouter is a Router that can be rebuilt from its config file
+// while requests are in flight, for long-running modes. Requests already
+// routed keep using the router they started with.
+type ReloadingRouter struct {
+	path    string
+	opts    []RouterOption
+	current atomic.Pointer[Router]
+}
+
+// Compile-time interface implementation check.
+var (
+	_ ChatClient    = (*ReloadingRouter)(nil)
+	_ ChatStreamer  = (*ReloadingRouter)(nil)
+	_ ModelResolver = (*ReloadingRouter)(nil)
+)
+
+// NewReloadingRouter creates a router from the config file at path.
+// The options are applied on every reload.
+func NewReloadingRouter(path string, opts ...RouterOption) (*ReloadingRouter, error) {
+	r := &ReloadingRouter{path: path, opts: opts}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Router returns the active router.
+func (r *ReloadingRouter) Router() *Router {
+	return r.current.Load()
+}
+
+// Reload loads and validates the config file and swaps in a new router.
+// The new router takes over the rate limiters of the active one, so a
+// reload does not reset them. On failure the active router is kept.
+func (r *ReloadingRouter) Reload() error {
+	cfg, err := config.LoadFromFile(r.path)
+	if err != nil {
+		return err
+	}
+	router, err := NewRouter(cfg, r.opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create router from %s: %w", r.path, err)
+	}
+	if active := r.current.Load(); active != nil {
+		router.inheritLimiters(active)
+	}
+	r.current.Store(router)
+	return nil
+}
+
+// inheritLimiters replaces the global and provider limiters of r with
+// those of prev, which keep their pending reservations and only take the
+// new limit if it changed. Limiters that prev lacks stay fresh.
+func (r *Router) inheritLimiters(prev *Router) {
+	if r.globalLimiter != nil && prev.globalLimiter != nil {
+		r.globalLimiter = inheritLimiter(prev.globalLimiter, r.globalLimiter)
+	}
+	for name, limiter := range r.rateLimiters {
+		if old, ok := prev.rateLimiters[name]; ok {
+			r.rateLimiters[name] = inheritLimiter(old, limiter)
+		}
+	}
+}
+
+// inheritLimiter returns old with the limit of fresh.
+func inheritLimiter(old, fresh *rate.Limiter) *rate.Limiter {
+	if old.Limit() != fresh.Limit() {
+		old.SetLimit(fresh.Limit())
+	}
+	return old
+}
+
+// Watch reloads the router whenever the config file changes, until ctx
+// is done, reporting every reload attempt via logf.
+func (r *ReloadingRouter) Watch(ctx context.Context, logf func(format string, args ...any)) error {
+	return config.Watch(ctx, r.path,
+		func() {
+			if err := r.Reload(); err != nil {
+				logf("config reload failed, keeping previous configuration: %v", err)
+				return
+			}
+			logf("config reloaded from %s", r.path)
+		},
+		func(err error) {
+			logf("config watcher: %v", err)
+		},
+	)
+}
+
+// Chat sends the request through the active router.
+func (r *ReloadingRouter) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return r.Router().Chat(ctx, req)
+}
+
+// ChatStream streams the request through the active router.
+func (r *ReloadingRouter) ChatStream(ctx context.Context, req ChatRequest) (*ChatStream, error) {
+	return r.Router().ChatStream(ctx, req)
+}
+
+// ResolveModel resolves a model or alias with the active router.
+func (r *ReloadingRouter) ResolveModel(model string) (fullName, provider string) {
+	return r.Router().ResolveModel(model)
+}
+
+// Lists reports whether a provider of the active router lists a model or alias.
+func (r *ReloadingRouter) Lists(model string) bool {
+	return r.Router().Lists(model)
+}
+
+// Pricing returns the token prices of a model or alias with the active router.
+func (r *ReloadingRouter) Pricing(model string) config.Pricing {
+	return r.Router().Pricing(model)
+}
@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// testConfig returns a config file routing model m to provider.
+func testConfig(provider, rateLimit string) string {
+	return `default_provider = "` + provider + `"
+
+[[providers]]
+name = "` + provider + `"
+base_url = "http://localhost:1/v1"
+api_token = "token"
+rate_limit = "` + rateLimit + `"
+models = ["m"]
+`
+}
+
+func TestReloadingRouter_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".tuna.toml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig("old", "10rpm")), 0644))
+
+	router, err := NewReloadingRouter(path)
+	require.NoError(t, err)
+	_, provider := router.ResolveModel("m")
+	assert.Equal(t, "old", provider)
+	assert.True(t, router.Lists("m"))
+	assert.False(t, router.Lists("other"))
+
+	require.NoError(t, os.WriteFile(path, []byte(testConfig("new", "10rpm")), 0644))
+	require.NoError(t, router.Reload())
+	_, provider = router.ResolveModel("m")
+	assert.Equal(t, "new", provider)
+
+	require.NoError(t, os.WriteFile(path, []byte(testConfig("broken", "fast")), 0644))
+	assert.Error(t, router.Reload())
+	_, provider = router.ResolveModel("m")
+	assert.Equal(t, "new", provider, "an invalid change keeps the previous router")
+}
+
+func TestReloadingRouter_Reload_Limiters(t *testing.T) {
+	withGlobal := func(global, provider string) string {
+		return `global_rate_limit = "` + global + `"
+` + testConfig("p", provider)
+	}
+
+	tests := map[string]struct {
+		global, provider string
+		wantGlobal       rate.Limit
+		wantProvider     rate.Limit
+	}{
+		"unchanged": {global: "60rpm", provider: "10rpm", wantGlobal: rate.Every(time.Second), wantProvider: rate.Every(6 * time.Second)},
+		"changed":   {global: "120rpm", provider: "20rpm", wantGlobal: rate.Every(500 * time.Millisecond), wantProvider: rate.Every(3 * time.Second)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".tuna.toml")
+			require.NoError(t, os.WriteFile(path, []byte(withGlobal("60rpm", "10rpm")), 0644))
+
+			router, err := NewReloadingRouter(path)
+			require.NoError(t, err)
+			global, provider := router.Router().globalLimiter, router.Router().rateLimiters["p"]
+			require.NotNil(t, global)
+			require.NotNil(t, provider)
+			require.True(t, provider.Allow(), "the first request takes the only token")
+
+			require.NoError(t, os.WriteFile(path, []byte(withGlobal(tc.global, tc.provider)), 0644))
+			require.NoError(t, router.Reload())
+
+			assert.Same(t, global, router.Router().globalLimiter)
+			assert.Same(t, provider, router.Router().rateLimiters["p"])
+			assert.Equal(t, tc.wantGlobal, global.Limit())
+			assert.Equal(t, tc.wantProvider, provider.Limit())
+			assert.False(t, provider.Allow(), "the reload does not hand out a fresh token")
+		})
+	}
+}
+
+func TestReloadingRouter_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".tuna.toml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig("old", "10rpm")), 0644))
+
+	router, err := NewReloadingRouter(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- router.Watch(ctx, t.Logf) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	// The watcher may not be registered yet, so keep touching the file
+	assert.Eventually(t, func() bool {
+		_ = os.WriteFile(path, []byte(testConfig("new", "10rpm")), 0644)
+		_, provider := router.ResolveModel("m")
+		return provider == "new"
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -15,29 +16,92 @@ type Router struct {
 	providers       map[string]*Client       // name -> client
 	providerURLs    map[string]string        // name -> base URL
 	rateLimiters    map[string]*rate.Limiter // name -> rate limiter
+	moderated       map[string]bool          // name -> moderation pre-check enabled
 	aliases         map[string]string        // alias -> full model name
-	modelMapping    map[string]string        // model -> provider name
+	modelMapping    map[string]string        // model -> first provider listing it
 	defaultProvider string
+
+	// pricing holds provider configs by name, for token prices.
+	pricing map[string]*config.Provider
+
+	// globalLimiter is shared by all providers; nil if not configured.
+	globalLimiter *rate.Limiter
+
+	// balancers spread models listed by several providers across them.
+	balancers map[string]*balancer // model -> balancer
 }
 
 // Compile-time interface implementation check.
 var _ ChatClient = (*Router)(nil)
 
+// RouterOption configures router construction.
+type RouterOption func(*routerOptions)
+
+// routerOptions holds settings applied by RouterOption.
+type routerOptions struct {
+	ignoreRateLimits bool
+	captureRaw       bool
+	logf             func(format string, args ...any)
+}
+
+// WithoutRateLimits skips creating rate limiters, e.g. for local testing
+// against a mock server.
+func WithoutRateLimits() RouterOption {
+	return func(o *routerOptions) {
+		o.ignoreRateLimits = true
+	}
+}
+
+// WithRawResponses records the raw JSON body of every chat response
+// in ChatResponse.Raw, for debugging.
+func WithRawResponses() RouterOption {
+	return func(o *routerOptions) {
+		o.captureRaw = true
+	}
+}
+
+// WithLogger logs every HTTP request made to providers via logf.
+func WithLogger(logf func(format string, args ...any)) RouterOption {
+	return func(o *routerOptions) {
+		o.logf = logf
+	}
+}
+
 // NewRouter creates a router from configuration.
-func NewRouter(cfg *config.Config) (*Router, error) {
+func NewRouter(cfg *config.Config, opts ...RouterOption) (*Router, error) {
+	var options routerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	r := &Router{
 		providers:       make(map[string]*Client),
 		providerURLs:    make(map[string]string),
 		rateLimiters:    make(map[string]*rate.Limiter),
+		moderated:       make(map[string]bool),
 		aliases:         cfg.Aliases,
 		modelMapping:    make(map[string]string),
 		defaultProvider: cfg.DefaultProvider,
+		pricing:         make(map[string]*config.Provider),
+		balancers:       make(map[string]*balancer),
 	}
 
 	if r.aliases == nil {
 		r.aliases = make(map[string]string)
 	}
 
+	if cfg.GlobalRateLimit != "" && !options.ignoreRateLimits {
+		rl, err := config.ParseRateLimit(cfg.GlobalRateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("global_rate_limit: %w", err)
+		}
+		r.globalLimiter = newLimiter(rl)
+	}
+
+	// Providers and their weights for every listed model
+	candidates := make(map[string][]string)
+	weights := make(map[string][]int)
+
 	// Create clients and rate limiters for each provider
 	for _, p := range cfg.Providers {
 		// Resolve API token (direct value or from environment)
@@ -46,31 +110,70 @@ func NewRouter(cfg *config.Config) (*Router, error) {
 			return nil, fmt.Errorf("provider %q: %w", p.Name, err)
 		}
 
-		// Create client
+		headers, err := p.ResolveHeaders()
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: %w", p.Name, err)
+		}
+
+		connectTimeout, err := config.ParseTimeout(p.ConnectTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: connect_timeout: %w", p.Name, err)
+		}
+		requestTimeout, err := p.Timeout()
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: request_timeout: %w", p.Name, err)
+		}
+		retryMaxElapsed, err := config.ParseTimeout(p.RetryMaxElapsed)
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: retry_max_elapsed: %w", p.Name, err)
+		}
+
+		// Create client. Rate limiting stays in Router.Chat rather than
+		// the client transport, so that response durations exclude waiting.
 		client := NewClient(&Config{
-			APIToken: token,
-			BaseURL:  p.BaseURL,
+			APIToken:        token,
+			BaseURL:         p.BaseURL,
+			ConnectTimeout:  connectTimeout,
+			Timeout:         requestTimeout,
+			HTTPProxy:       p.HTTPProxy,
+			HTTPSProxy:      p.HTTPSProxy,
+			NoProxy:         p.NoProxy,
+			MaxRetries:      p.MaxRetries,
+			RetryJitter:     Jitter(p.RetryJitter),
+			RetryMaxElapsed: retryMaxElapsed,
+			Logf:            options.logf,
+			Headers:         headers,
+			CaptureRaw:      options.captureRaw,
 		})
 		r.providers[p.Name] = client
 		r.providerURLs[p.Name] = p.BaseURL
+		r.moderated[p.Name] = p.Moderate
+		r.pricing[p.Name] = &p
 
 		// Create rate limiter if configured
-		if p.RateLimit != "" {
+		if p.RateLimit != "" && !options.ignoreRateLimits {
 			rl, err := config.ParseRateLimit(p.RateLimit)
 			if err != nil {
 				return nil, fmt.Errorf("provider %q: %w", p.Name, err)
 			}
 			if rl != nil {
-				// rate.Every returns the duration between events
-				// For "10rpm", we want 1 request every 6 seconds
-				limiter := rate.NewLimiter(rate.Every(rl.Unit/time.Duration(rl.Value)), 1)
-				r.rateLimiters[p.Name] = limiter
+				r.rateLimiters[p.Name] = newLimiter(rl)
 			}
 		}
 
 		// Build model to provider mapping
 		for _, model := range p.Models {
-			r.modelMapping[model] = p.Name
+			if _, ok := r.modelMapping[model]; !ok {
+				r.modelMapping[model] = p.Name
+			}
+			candidates[model] = append(candidates[model], p.Name)
+			weights[model] = append(weights[model], p.Weight)
+		}
+	}
+
+	for model, providers := range candidates {
+		if len(providers) > 1 {
+			r.balancers[model] = newBalancer(providers, weights[model])
 		}
 	}
 
@@ -79,6 +182,63 @@ func NewRouter(cfg *config.Config) (*Router, error) {
 
 // Chat sends a request to the appropriate provider.
 func (r *Router) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	routed, err := r.route(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if routed.flagged() {
+		return routed.flaggedResponse(), nil
+	}
+
+	// Time the actual API request (excluding rate limit wait)
+	start := time.Now()
+	resp, err := routed.client.Chat(ctx, routed.req)
+	duration := time.Since(start)
+
+	if err != nil {
+		return nil, err
+	}
+
+	routed.complete(resp, duration)
+	return resp, nil
+}
+
+// ChatStream sends a request to the appropriate provider and streams
+// the response. Rate limiting and moderation apply as for Chat; the
+// final response carries the provider URL and the duration of the
+// whole stream.
+func (r *Router) ChatStream(ctx context.Context, req ChatRequest) (*ChatStream, error) {
+	routed, err := r.route(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if routed.flagged() {
+		return completedStream(routed.flaggedResponse()), nil
+	}
+
+	start := time.Now()
+	return routed.client.chatStream(ctx, routed.req, func(resp *ChatResponse) {
+		routed.complete(resp, time.Since(start))
+	})
+}
+
+// routedRequest is a request resolved to its provider that passed
+// the moderation pre-check and waited for the rate limiter.
+type routedRequest struct {
+	client      *Client
+	req         ChatRequest // With the resolved model name
+	provider    string
+	providerURL string
+	moderation  *ModerationResult
+	wait        time.Duration
+	pricing     config.Pricing
+}
+
+// route resolves the provider of a request, runs the moderation
+// pre-check and waits for the rate limiter, before the pre-check as
+// well as before the request. Flagged requests are returned without
+// waiting again.
+func (r *Router) route(ctx context.Context, req ChatRequest) (*routedRequest, error) {
 	// Resolve alias to full model name
 	resolvedModel := r.resolveAlias(req.Model)
 
@@ -90,32 +250,211 @@ func (r *Router) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, erro
 		return nil, fmt.Errorf("provider %q not found for model %q", providerName, req.Model)
 	}
 
-	providerURL := r.providerURLs[providerName]
+	// Update request with resolved model name
+	req.Model = resolvedModel
+	routed := &routedRequest{
+		client:      client,
+		req:         req,
+		provider:    providerName,
+		providerURL: r.providerURLs[providerName],
+	}
+	if provider, ok := r.pricing[providerName]; ok {
+		routed.pricing = provider.Pricing(resolvedModel)
+	}
 
-	// Wait for rate limiter if configured
-	if limiter, ok := r.rateLimiters[providerName]; ok {
-		if err := limiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limit wait cancelled: %w", err)
+	// Run moderation pre-check if enabled; flagged messages are not sent.
+	// The check is a request of its own, so it waits for the limiters too.
+	if r.moderated[providerName] {
+		if err := r.waitRouted(ctx, routed); err != nil {
+			return nil, err
+		}
+		result, err := client.Moderate(ctx, req.UserMessage)
+		if err != nil {
+			return nil, err
+		}
+		routed.moderation = result
+		if result.Flagged {
+			return routed, nil
 		}
 	}
 
-	// Update request with resolved model name
-	req.Model = resolvedModel
+	if err := r.waitRouted(ctx, routed); err != nil {
+		return nil, err
+	}
+	return routed, nil
+}
 
-	// Time the actual API request (excluding rate limit wait)
-	start := time.Now()
-	resp, err := client.Chat(ctx, req)
-	duration := time.Since(start)
+// waitRouted waits for the global and the provider rate limiter if
+// configured, adding the time waited to the request's wait.
+func (r *Router) waitRouted(ctx context.Context, routed *routedRequest) error {
+	waitStart := time.Now()
+	if err := r.wait(ctx, routed.provider); err != nil {
+		return err
+	}
+	routed.wait += time.Since(waitStart)
+	return nil
+}
+
+// flagged reports whether the moderation pre-check flagged the request.
+func (r *routedRequest) flagged() bool {
+	return r.moderation != nil && r.moderation.Flagged
+}
+
+// flaggedResponse returns the response reported for a flagged request.
+func (r *routedRequest) flaggedResponse() *ChatResponse {
+	return &ChatResponse{
+		Model:       r.req.Model,
+		Provider:    r.provider,
+		ProviderURL: r.providerURL,
+		Moderation:  r.moderation,
+	}
+}
+
+// complete adds provider URL, timing, cost and moderation to a response.
+func (r *routedRequest) complete(resp *ChatResponse, duration time.Duration) {
+	resp.Provider = r.provider
+	resp.ProviderURL = r.providerURL
+	resp.Duration = duration
+	resp.Cost = r.pricing.Cost(resp.PromptTokens, resp.OutputTokens)
+	resp.RateLimitWait = r.wait
+	resp.Moderation = r.moderation
+}
 
+// Pricing returns the configured token prices for a model or alias.
+func (r *Router) Pricing(model string) config.Pricing {
+	fullName, provider := r.ResolveModel(model)
+	if p, ok := r.pricing[provider]; ok {
+		return p.Pricing(fullName)
+	}
+	return config.Pricing{}
+}
+
+// ListModels fetches the live model catalog of the named provider,
+// or of the default provider if name is empty.
+func (r *Router) ListModels(ctx context.Context, name string) ([]string, error) {
+	catalog, err := r.Catalog(ctx, name)
 	if err != nil {
 		return nil, err
 	}
+	return catalog.Models, nil
+}
 
-	// Add provider URL and timing to response
-	resp.ProviderURL = providerURL
-	resp.Duration = duration
+// Catalog fetches the live models and reported rate limit of the named
+// provider, or of the default provider if name is empty.
+func (r *Router) Catalog(ctx context.Context, name string) (*Catalog, error) {
+	if name == "" {
+		name = r.defaultProvider
+	}
 
-	return resp, nil
+	client, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("provider %q not found", name)
+	}
+
+	if err := r.wait(ctx, name); err != nil {
+		return nil, err
+	}
+
+	return client.Catalog(ctx)
+}
+
+// Ping checks that the named provider is reachable and accepts its API
+// token. With a model, it sends a one-token chat request for the model
+// (aliases are resolved); otherwise it lists the provider's models.
+func (r *Router) Ping(ctx context.Context, name, model string) error {
+	client, ok := r.providers[name]
+	if !ok {
+		return fmt.Errorf("provider %q not found", name)
+	}
+
+	if err := r.wait(ctx, name); err != nil {
+		return err
+	}
+
+	if model == "" {
+		_, err := client.ListModels(ctx)
+		return err
+	}
+	_, err := client.Chat(ctx, ChatRequest{
+		Model:       r.resolveAlias(model),
+		UserMessage: "ping",
+		MaxTokens:   1,
+	})
+	return err
+}
+
+// wait waits for the global and the named provider's rate limiter,
+// whichever are configured.
+func (r *Router) wait(ctx context.Context, provider string) error {
+	var limits []limit
+	if r.globalLimiter != nil {
+		limits = append(limits, limit{r.globalLimiter, "global rate limit"})
+	}
+	if limiter, ok := r.rateLimiters[provider]; ok {
+		limits = append(limits, limit{limiter, "rate limit"})
+	}
+	return waitLimiters(ctx, limits...)
+}
+
+// newLimiter creates a limiter allowing one request per interval of rl.
+// For "10rpm", that is one request every 6 seconds.
+func newLimiter(rl *config.RateLimit) *rate.Limiter {
+	return rate.NewLimiter(rate.Every(rl.Unit/time.Duration(rl.Value)), 1)
+}
+
+// limit is a rate limiter with the scope it is named by in errors.
+type limit struct {
+	limiter *rate.Limiter
+	scope   string
+}
+
+// waitLimiters waits for a token of every limiter. Tokens are reserved
+// from all limiters at once, so the wait is the longest of their delays.
+// If a token would only be released after the context deadline, it fails
+// right away with ErrRateLimitDeadline instead of waiting in vain. On
+// failure every reservation is cancelled as of the time it was made, so
+// a request that is not sent gives back even tokens that were available
+// right away and does not hold up later requests of the other limiters.
+func waitLimiters(ctx context.Context, limits ...limit) error {
+	now := time.Now()
+	reservations := make([]*rate.Reservation, 0, len(limits))
+	cancel := func() {
+		for _, reservation := range reservations {
+			reservation.CancelAt(now)
+		}
+	}
+
+	var delay time.Duration
+	var scope string
+	for _, l := range limits {
+		reservation := l.limiter.ReserveN(now, 1)
+		if !reservation.OK() {
+			cancel()
+			return fmt.Errorf("%s: request exceeds limiter burst", l.scope)
+		}
+		reservations = append(reservations, reservation)
+		if d := reservation.DelayFrom(now); d > delay {
+			delay, scope = d, l.scope
+		}
+	}
+
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < delay {
+		cancel()
+		return fmt.Errorf("%s: %w (next slot in %s)", scope, ErrRateLimitDeadline, delay.Round(time.Millisecond))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return fmt.Errorf("%s wait cancelled: %w", scope, ctx.Err())
+	}
 }
 
 // resolveAlias resolves an alias to the full model name.
@@ -126,8 +465,18 @@ func (r *Router) resolveAlias(model string) string {
 	return model
 }
 
-// resolveProvider determines the provider for a model.
+// resolveProvider determines the provider to send a request for a model to.
+// Models listed by several providers are balanced by provider weight.
 func (r *Router) resolveProvider(model string) string {
+	if b, ok := r.balancers[model]; ok {
+		return b.next()
+	}
+	return r.primaryProvider(model)
+}
+
+// primaryProvider returns the first provider listing a model,
+// or the default provider.
+func (r *Router) primaryProvider(model string) string {
 	if provider, ok := r.modelMapping[model]; ok {
 		return provider
 	}
@@ -136,12 +485,92 @@ func (r *Router) resolveProvider(model string) string {
 
 // ResolveModel returns full model name and provider name for a given model or alias.
 // This is useful for CLI commands like "tuna config resolve <model>".
+// For a model listed by several providers, the first one is returned.
 func (r *Router) ResolveModel(model string) (fullName, provider string) {
 	fullName = r.resolveAlias(model)
-	provider = r.resolveProvider(fullName)
+	provider = r.primaryProvider(fullName)
 	return fullName, provider
 }
 
+// Route describes how the Router sends requests for a model.
+type Route struct {
+	Model    string        // Full model name sent to the provider
+	Alias    string        // Alias the model was requested by, empty if none
+	Fallback bool          // No provider lists the model, so the default provider is used
+	Targets  []RouteTarget // Providers requests are spread across, in balancing order
+
+	// GlobalRateLimited reports whether the global rate limit applies.
+	GlobalRateLimited bool
+}
+
+// RouteTarget is a provider a Route sends requests to.
+type RouteTarget struct {
+	Provider    string
+	BaseURL     string
+	Weight      int      // Share of requests relative to the other targets
+	RateLimited bool     // Whether the provider rate limit applies
+	Moderated   bool     // Whether the moderation pre-check runs first
+	Proxy       *url.URL // Proxy for the base URL, nil for a direct connection
+	Pricing     config.Pricing
+}
+
+// Route reports the decisions the Router makes for requests for a model
+// or alias, without sending anything or advancing the balancing.
+func (r *Router) Route(model string) (*Route, error) {
+	route := &Route{
+		Model:             r.resolveAlias(model),
+		GlobalRateLimited: r.globalLimiter != nil,
+	}
+	if route.Model != model {
+		route.Alias = model
+	}
+
+	var providers []string
+	weights := []int{1}
+	if b, ok := r.balancers[route.Model]; ok {
+		providers, weights = b.providers, b.weights
+	} else {
+		provider := r.primaryProvider(route.Model)
+		_, listed := r.modelMapping[route.Model]
+		route.Fallback = !listed
+		if _, ok := r.providers[provider]; !ok {
+			if provider == "" {
+				return nil, fmt.Errorf("model %q is not listed by any provider and no default_provider is configured", route.Model)
+			}
+			return nil, fmt.Errorf("model %q is not listed by any provider and default_provider %q is not configured", route.Model, provider)
+		}
+		providers = []string{provider}
+	}
+
+	for i, name := range providers {
+		proxy, err := r.providers[name].Proxy()
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: %w", name, err)
+		}
+		_, rateLimited := r.rateLimiters[name]
+		target := RouteTarget{
+			Provider:    name,
+			BaseURL:     r.providerURLs[name],
+			Weight:      weights[i],
+			RateLimited: rateLimited,
+			Moderated:   r.moderated[name],
+			Proxy:       proxy,
+		}
+		if p, ok := r.pricing[name]; ok {
+			target.Pricing = p.Pricing(route.Model)
+		}
+		route.Targets = append(route.Targets, target)
+	}
+	return route, nil
+}
+
+// Lists reports whether a provider explicitly lists a model or alias,
+// rather than the request falling back to the default provider.
+func (r *Router) Lists(model string) bool {
+	_, ok := r.modelMapping[r.resolveAlias(model)]
+	return ok
+}
+
 // Providers returns the list of provider names.
 func (r *Router) Providers() []string {
 	names := make([]string, 0, len(r.providers))
@@ -0,0 +1,373 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"go.octolab.org/toolset/tuna/internal/config"
+)
+
+// chatServer answers every chat completion request with content.
+func chatServer(t *testing.T, content string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`, content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRouter_Chat_Weighted(t *testing.T) {
+	cfg := &config.Config{
+		Providers: []config.Provider{
+			{Name: "heavy", BaseURL: chatServer(t, "heavy").URL, APIToken: "token", Models: []string{"m"}, Weight: 3},
+			{Name: "light", BaseURL: chatServer(t, "light").URL, APIToken: "token", Models: []string{"m"}},
+		},
+	}
+	router, err := NewRouter(cfg)
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	var sequence []string
+	for range 8 {
+		resp, err := router.Chat(context.Background(), ChatRequest{Model: "m", UserMessage: "Hello"})
+		require.NoError(t, err)
+		assert.Equal(t, resp.Provider, resp.Content, "response comes from the reported provider")
+		counts[resp.Provider]++
+		sequence = append(sequence, resp.Provider)
+	}
+
+	assert.Equal(t, map[string]int{"heavy": 6, "light": 2}, counts)
+	assert.Equal(t, []string{"heavy", "heavy", "light", "heavy"}, sequence[:4], "smooth, not bursty")
+}
+
+func TestRouter_Chat_Cost(t *testing.T) {
+	server := chatServer(t, "ok") // Reports 1 prompt and 1 output token
+	cfg := &config.Config{
+		Providers: []config.Provider{{
+			Name: "priced", BaseURL: server.URL, APIToken: "token", Models: []string{"cheap", "premium"},
+			InputCostPer1K: 1, OutputCostPer1K: 2,
+			ModelCosts: map[string]config.Pricing{"premium": {InputPer1K: 10, OutputPer1K: 30}},
+		}},
+	}
+	router, err := NewRouter(cfg)
+	require.NoError(t, err)
+
+	tests := map[string]float64{
+		"cheap":   0.003,
+		"premium": 0.04,
+	}
+	for model, want := range tests {
+		t.Run(model, func(t *testing.T) {
+			resp, err := router.Chat(context.Background(), ChatRequest{Model: model, UserMessage: "Hello"})
+			require.NoError(t, err)
+			assert.InDelta(t, want, resp.Cost, 1e-9)
+		})
+	}
+}
+
+func TestRouter_Chat_Headers(t *testing.T) {
+	// headerServer answers with the gateway headers it received
+	headerServer := func(t *testing.T) *httptest.Server {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			echo, err := json.Marshal(map[string]string{
+				"HTTP-Referer": r.Header.Get("HTTP-Referer"),
+				"X-Title":      r.Header.Get("X-Title"),
+			})
+			require.NoError(t, err)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}]}`, echo)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	t.Setenv("TUNA_TEST_TITLE", "tuna")
+	router, err := NewRouter(&config.Config{
+		Providers: []config.Provider{
+			{
+				Name: "gateway", BaseURL: headerServer(t).URL, APIToken: "token", Models: []string{"routed"},
+				Headers: map[string]string{"HTTP-Referer": "https://example.com", "X-Title": "$TUNA_TEST_TITLE"},
+			},
+			{Name: "direct", BaseURL: headerServer(t).URL, APIToken: "token", Models: []string{"plain"}},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]map[string]string{
+		"routed": {"HTTP-Referer": "https://example.com", "X-Title": "tuna"},
+		"plain":  {"HTTP-Referer": "", "X-Title": ""},
+	}
+	for model, want := range tests {
+		t.Run(model, func(t *testing.T) {
+			for range 2 {
+				resp, err := router.Chat(context.Background(), ChatRequest{Model: model, UserMessage: "Hello"})
+				require.NoError(t, err)
+				var got map[string]string
+				require.NoError(t, json.Unmarshal([]byte(resp.Content), &got))
+				assert.Equal(t, want, got, "headers are sent on every request of their provider only")
+			}
+		})
+	}
+}
+
+func TestRouter_Chat_Moderation(t *testing.T) {
+	var chats int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/moderations") {
+			var body struct {
+				Input string `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			// Two results, e.g. one per chunk of the input, flag the same category
+			flagged := strings.Contains(body.Input, "violent")
+			result := fmt.Sprintf(`{"flagged":%t,"categories":{"violence":%t,"hate":false}}`, flagged, flagged)
+			_, _ = fmt.Fprintf(w, `{"id":"modr","model":"m","results":[%s,%s]}`, result, result)
+			return
+		}
+		chats++
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(server.Close)
+	router, err := NewRouter(&config.Config{
+		Providers: []config.Provider{
+			{Name: "safe", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}, Moderate: true},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		message   string
+		flagged   bool
+		wantChats int
+	}{
+		"passed":  {message: "a calm question", wantChats: 1},
+		"flagged": {message: "a violent question", flagged: true, wantChats: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			chats = 0
+			resp, err := router.Chat(context.Background(), ChatRequest{Model: "m", UserMessage: tc.message})
+			require.NoError(t, err)
+			require.NotNil(t, resp.Moderation)
+			assert.Equal(t, tc.flagged, resp.Moderation.Flagged)
+			assert.Equal(t, tc.wantChats, chats)
+			if tc.flagged {
+				assert.Equal(t, []string{"violence"}, resp.Moderation.Categories, "categories are deduplicated")
+				assert.Empty(t, resp.Content)
+				assert.Equal(t, "safe", resp.Provider)
+			}
+		})
+	}
+}
+
+func TestRouter_Chat_ModerationRateLimited(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/moderations") {
+			_, _ = fmt.Fprint(w, `{"id":"modr","model":"m","results":[{"flagged":false,"categories":{}}]}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := map[string]config.Config{
+		"provider limit": {
+			Providers: []config.Provider{{Name: "safe", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}, Moderate: true, RateLimit: "5rps"}},
+		},
+		"global limit": {
+			GlobalRateLimit: "5rps",
+			Providers:       []config.Provider{{Name: "safe", BaseURL: server.URL, APIToken: "token", Models: []string{"m"}, Moderate: true}},
+		},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests = nil
+			router, err := NewRouter(&cfg)
+			require.NoError(t, err)
+
+			resp, err := router.Chat(context.Background(), ChatRequest{Model: "m", UserMessage: "a calm question"})
+			require.NoError(t, err)
+			require.Len(t, requests, 2, "moderation and chat")
+			// One request every 200ms: the chat waits for the moderation's token
+			assert.GreaterOrEqual(t, requests[1].Sub(requests[0]), 150*time.Millisecond)
+			assert.GreaterOrEqual(t, resp.RateLimitWait, 150*time.Millisecond)
+		})
+	}
+}
+
+func TestRouter_GlobalRateLimit(t *testing.T) {
+	cfg := &config.Config{
+		GlobalRateLimit: "2rps",
+		Providers: []config.Provider{
+			{Name: "first", BaseURL: chatServer(t, "first").URL, APIToken: "token", Models: []string{"a"}},
+			{Name: "second", BaseURL: chatServer(t, "second").URL, APIToken: "token", Models: []string{"b"}},
+		},
+	}
+	router, err := NewRouter(cfg)
+	require.NoError(t, err)
+
+	t.Run("chat", func(t *testing.T) {
+		start := time.Now()
+		for _, model := range []string{"a", "b", "a", "b"} {
+			_, err := router.Chat(context.Background(), ChatRequest{Model: model, UserMessage: "Hello"})
+			require.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 1400*time.Millisecond, "one request every 500ms across both providers")
+	})
+
+	t.Run("ping and catalog", func(t *testing.T) {
+		_, err := router.Chat(context.Background(), ChatRequest{Model: "a", UserMessage: "Hello"})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, router.Ping(ctx, "second", ""), ErrRateLimitDeadline)
+		_, err = router.Catalog(ctx, "second")
+		assert.ErrorIs(t, err, ErrRateLimitDeadline)
+	})
+}
+
+func TestNewRouter_WithoutRateLimits(t *testing.T) {
+	cfg := &config.Config{
+		GlobalRateLimit: "1rpm",
+		Providers: []config.Provider{
+			{Name: "local", BaseURL: chatServer(t, "ok").URL, APIToken: "token", Models: []string{"m"}, RateLimit: "1rpm"},
+		},
+	}
+
+	tests := map[string]struct {
+		opts        []RouterOption
+		wantLimited bool
+	}{
+		"configured limits": {wantLimited: true},
+		"ignored limits":    {opts: []RouterOption{WithoutRateLimits()}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			router, err := NewRouter(cfg, tc.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantLimited, router.globalLimiter != nil)
+			assert.Equal(t, tc.wantLimited, router.rateLimiters["local"] != nil)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			var errs []error
+			for range 5 {
+				_, err := router.Chat(ctx, ChatRequest{Model: "m", UserMessage: "Hello"})
+				errs = append(errs, err)
+			}
+			if !tc.wantLimited {
+				assert.Equal(t, make([]error, 5), errs, "no request is throttled")
+				return
+			}
+			assert.NoError(t, errs[0])
+			assert.ErrorIs(t, errs[1], ErrRateLimitDeadline)
+		})
+	}
+}
+
+func TestRouter_ListModels(t *testing.T) {
+	catalog := func(token string, ids ...string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = fmt.Fprint(w, `{"error":{"message":"invalid token"}}`)
+				return
+			}
+			data := make([]string, len(ids))
+			for i, id := range ids {
+				data[i] = fmt.Sprintf(`{"id":%q,"object":"model"}`, id)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"object":"list","data":[%s]}`, strings.Join(data, ","))
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+
+	cfg := &config.Config{
+		DefaultProvider: "openai",
+		Providers: []config.Provider{
+			{Name: "openai", BaseURL: catalog("openai-token", "o1", "gpt-4o"), APIToken: "openai-token"},
+			{Name: "local", BaseURL: catalog("local-token", "llama3"), APIToken: "local-token"},
+			{Name: "wrong", BaseURL: catalog("other-token", "m"), APIToken: "wrong-token"},
+		},
+	}
+	router, err := NewRouter(cfg)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		provider string
+		want     []string
+		wantErr  string
+	}{
+		"default provider": {want: []string{"gpt-4o", "o1"}},
+		"named provider":   {provider: "local", want: []string{"llama3"}},
+		"rejected token":   {provider: "wrong", wantErr: "list models failed"},
+		"unknown provider": {provider: "missing", wantErr: `provider "missing" not found`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ids, err := router.ListModels(context.Background(), tc.provider)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, ids)
+		})
+	}
+}
+
+func TestWaitLimiters_CancelsReservations(t *testing.T) {
+	global := rate.NewLimiter(rate.Every(time.Minute), 1)
+	provider := rate.NewLimiter(rate.Every(time.Minute), 1)
+	require.True(t, provider.Allow(), "exhaust the provider limiter")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := waitLimiters(ctx, limit{global, "global rate limit"}, limit{provider, "rate limit"})
+	require.ErrorIs(t, err, ErrRateLimitDeadline)
+	assert.ErrorContains(t, err, "rate limit: ")
+	assert.True(t, global.Allow(), "the global token is given back")
+}
+
+func TestWaitLimiters_CancelsProviderReservation(t *testing.T) {
+	global := rate.NewLimiter(rate.Every(time.Minute), 1)
+	provider := rate.NewLimiter(rate.Every(time.Minute), 1)
+	require.True(t, global.Allow(), "exhaust the global limiter")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := waitLimiters(ctx, limit{global, "global rate limit"}, limit{provider, "rate limit"})
+	require.ErrorIs(t, err, ErrRateLimitDeadline)
+	assert.ErrorContains(t, err, "global rate limit")
+	assert.True(t, provider.Allow(), "the provider token is given back")
+}
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	api "github.com/sashabaranov/go-openai"
+)
+
+// ChatStream delivers a chat completion while it is being generated.
+// Content deltas are read from Chunks; Response returns the complete
+// response once the stream has ended. The stream ends early when the
+// context it was opened with is cancelled, or on Close.
+type ChatStream struct {
+	chunks chan string
+	done   chan struct{}
+	cancel context.CancelFunc
+	resp   *ChatResponse
+	err    error
+}
+
+// Chunks returns the content deltas in order.
+// The channel is closed when the stream ends.
+func (s *ChatStream) Chunks() <-chan string {
+	return s.chunks
+}
+
+// Response waits for the stream to end and returns the full response,
+// including token usage if the provider reports it. Unread chunks are
+// discarded, so it is safe to call without reading Chunks.
+func (s *ChatStream) Response() (*ChatResponse, error) {
+	for range s.chunks {
+	}
+	<-s.done
+	return s.resp, s.err
+}
+
+// Close stops the stream if it has not ended yet and waits until its
+// connection is released. Response then returns the error the stream
+// was stopped with. Close is safe to call more than once.
+func (s *ChatStream) Close() {
+	s.cancel()
+	for range s.chunks {
+	}
+	<-s.done
+}
+
+// completedStream returns a stream that has already ended with resp.
+func completedStream(resp *ChatResponse) *ChatStream {
+	s := &ChatStream{
+		chunks: make(chan string),
+		done:   make(chan struct{}),
+		cancel: func() {},
+		resp:   resp,
+	}
+	close(s.chunks)
+	close(s.done)
+	return s
+}
+
+// ChatStream sends a chat completion request and streams the response.
+// Raw response capture does not apply to streamed responses.
+func (c *Client) ChatStream(ctx context.Context, req ChatRequest) (*ChatStream, error) {
+	return c.chatStream(ctx, req, nil)
+}
+
+// chatStream opens the stream; complete, if set, is called with the
+// full response before Response returns it. The request timeout bounds
+// the whole stream, as it bounds a whole request in Chat.
+func (c *Client) chatStream(ctx context.Context, req ChatRequest, complete func(*ChatResponse)) (*ChatStream, error) {
+	request := chatCompletionRequest(req)
+	request.Stream = true
+	request.StreamOptions = &api.StreamOptions{IncludeUsage: true}
+
+	var (
+		reqCtx context.Context
+		cancel context.CancelFunc
+	)
+	if c.timeout > 0 {
+		reqCtx, cancel = context.WithTimeout(ctx, c.timeout)
+	} else {
+		reqCtx, cancel = context.WithCancel(ctx)
+	}
+	reqCtx, timing := withTiming(reqCtx)
+	stream, err := c.client.CreateChatCompletionStream(reqCtx, request)
+	if err != nil {
+		cancel()
+		return nil, c.timeoutError(ctx, reqCtx, fmt.Errorf("chat completion failed: %w", err))
+	}
+
+	s := &ChatStream{
+		chunks: make(chan string),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	go func() {
+		defer close(s.done)
+		defer cancel()
+		defer stream.Close()
+
+		resp, err := receive(reqCtx, stream, s.chunks)
+		close(s.chunks)
+		if err != nil {
+			s.err = c.timeoutError(ctx, reqCtx, err)
+			return
+		}
+		resp.HTTPStatus = timing.status
+		resp.TTFB = timing.ttfb
+		if complete != nil {
+			complete(resp)
+		}
+		s.resp = resp
+	}()
+
+	return s, nil
+}
+
+// receive reads the stream until it ends, sending content deltas
+// to chunks, and assembles the full response.
+func receive(ctx context.Context, stream *api.ChatCompletionStream, chunks chan<- string) (*ChatResponse, error) {
+	var (
+		content strings.Builder
+		resp    ChatResponse
+		choices int
+	)
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("chat completion stream failed: %w", err)
+		}
+
+		if chunk.Model != "" {
+			resp.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			resp.PromptTokens = chunk.Usage.PromptTokens
+			resp.OutputTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choices++
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			resp.FinishReason = string(choice.FinishReason)
+		}
+		if delta := choice.Delta.Content; delta != "" {
+			content.WriteString(delta)
+			select {
+			case chunks <- delta:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if choices == 0 {
+		return nil, fmt.Errorf("no response choices returned")
+	}
+	resp.Content = content.String()
+
+	return &resp, nil
+}
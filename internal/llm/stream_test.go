@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stallingServer streams one content delta and then stalls until the
+// request is cancelled.
+func stallingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, `data: {"model":"m","choices":[{"index":0,"delta":{"content":"Hi"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_ChatStream_Timeout(t *testing.T) {
+	server := stallingServer(t)
+	client := NewClient(&Config{APIToken: "token", BaseURL: server.URL, Timeout: 100 * time.Millisecond})
+
+	stream, err := client.ChatStream(context.Background(), ChatRequest{Model: "m", UserMessage: "Hello"})
+	require.NoError(t, err)
+
+	var content string
+	for chunk := range stream.Chunks() {
+		content += chunk
+	}
+	_, err = stream.Response()
+	assert.Equal(t, "Hi", content)
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+}
+
+func TestClient_ChatStream_Cancel(t *testing.T) {
+	server := stallingServer(t)
+	client := NewClient(&Config{APIToken: "token", BaseURL: server.URL, Timeout: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.ChatStream(ctx, ChatRequest{Model: "m", UserMessage: "Hello"})
+	require.NoError(t, err)
+	<-stream.Chunks()
+	cancel()
+
+	_, err = stream.Response()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrRequestTimeout)
+}
+
+func TestClient_ChatStream_Close(t *testing.T) {
+	server := stallingServer(t)
+	client := NewClient(&Config{APIToken: "token", BaseURL: server.URL})
+
+	stream, err := client.ChatStream(context.Background(), ChatRequest{Model: "m", UserMessage: "Hello"})
+	require.NoError(t, err)
+
+	closed := make(chan struct{})
+	go func() {
+		stream.Close() // Unread chunks must not block it
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not stop the stream")
+	}
+
+	_, err = stream.Response()
+	assert.ErrorIs(t, err, context.Canceled)
+	stream.Close()
+}
+
+func TestClient_ChatStream_Timing(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"Hello", ", world"} {
+			_, _ = fmt.Fprintf(w, `data: {"model":"m","choices":[{"index":0,"delta":{"content":%q}}]}`+"\n\n", content)
+			w.(http.Flusher).Flush()
+		}
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(&Config{APIToken: "token", BaseURL: server.URL})
+
+	stream, err := client.ChatStream(context.Background(), ChatRequest{Model: "m", UserMessage: "Hello"})
+	require.NoError(t, err)
+	for range stream.Chunks() {
+	}
+	resp, err := stream.Response()
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, world", resp.Content)
+	assert.Equal(t, http.StatusOK, resp.HTTPStatus)
+	assert.GreaterOrEqual(t, resp.TTFB, delay)
+}
@@ -0,0 +1,313 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Middleware wraps an http.RoundTripper with additional behavior.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps base with the given middlewares. The first middleware
+// is the outermost one, i.e. it sees the request first.
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
+
+// RateLimit waits for the limiter before each request. Like the Router,
+// it fails right away with ErrRateLimitDeadline if the next slot opens
+// only after the request's context deadline.
+func RateLimit(limiter *rate.Limiter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := waitLimiters(req.Context(), limit{limiter, "rate limit"}); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// DefaultRetryBackoff is the initial delay between retries; it doubles on each attempt.
+const DefaultRetryBackoff = 500 * time.Millisecond
+
+// Jitter selects how retry delays are randomized.
+type Jitter string
+
+const (
+	JitterNone  Jitter = "none"  // Exact exponential delay
+	JitterFull  Jitter = "full"  // Random delay in [0, delay)
+	JitterEqual Jitter = "equal" // Half the delay plus a random part in [0, delay/2)
+)
+
+// RetryPolicy describes how failed requests are retried.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration // Initial delay; it doubles on each attempt
+	Jitter     Jitter        // Empty means JitterNone
+	MaxElapsed time.Duration // Total time budget for all attempts (0 = unlimited)
+
+	// now and sleep replace the clock in tests; nil means the real one.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// jitter randomizes delay according to the policy.
+func (p RetryPolicy) jitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return delay
+	}
+	switch p.Jitter {
+	case JitterFull:
+		return time.Duration(rand.Int64N(int64(delay)))
+	case JitterEqual:
+		half := delay / 2
+		return half + time.Duration(rand.Int64N(int64(delay-half)))
+	default:
+		return delay
+	}
+}
+
+// Retry re-sends requests that failed with a network error or a retryable
+// status (429, 500, 502, 503, 504) up to policy.MaxRetries times, with
+// jittered exponential backoff. A Retry-After header in seconds takes
+// precedence. No retry is made if it would start more than policy.MaxElapsed
+// after the first attempt; the duration of an attempt itself is bounded by
+// the request timeout instead.
+func Retry(policy RetryPolicy) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		now, sleep := policy.now, policy.sleep
+		if now == nil {
+			now = time.Now
+		}
+		if sleep == nil {
+			sleep = sleepContext
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := now()
+			delay := policy.Backoff
+			for attempt := 0; ; attempt++ {
+				resp, err := next.RoundTrip(req)
+				if attempt >= policy.MaxRetries || !shouldRetry(resp, err) || req.Context().Err() != nil {
+					return resp, err
+				}
+
+				wait := policy.jitter(delay)
+				if resp != nil {
+					if after := retryAfter(resp); after > 0 {
+						wait = after
+					}
+				}
+				if policy.MaxElapsed > 0 && now().Sub(start)+wait > policy.MaxElapsed {
+					return resp, err
+				}
+				if resp != nil {
+					resp.Body.Close()
+				}
+
+				// Rewind the body for the next attempt
+				if req.Body != nil {
+					if req.GetBody == nil {
+						return nil, fmt.Errorf("cannot retry request: body is not rewindable")
+					}
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, fmt.Errorf("cannot retry request: %w", err)
+					}
+					req = req.Clone(req.Context())
+					req.Body = body
+				}
+
+				if err := sleep(req.Context(), wait); err != nil {
+					return nil, err
+				}
+				delay *= 2
+			}
+		})
+	}
+}
+
+// shouldRetry reports whether a response or error is worth retrying.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header given in seconds.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Logging reports each request's method, URL, status, and duration via logf.
+func Logging(logf func(format string, args ...any)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			duration := time.Since(start).Round(time.Millisecond)
+			if err != nil {
+				logf("%s %s: %v (%s)", req.Method, req.URL, err, duration)
+			} else {
+				logf("%s %s: %d (%s)", req.Method, req.URL, resp.StatusCode, duration)
+			}
+			return resp, err
+		})
+	}
+}
+
+// Headers sets the given headers on every request, overriding existing values.
+func Headers(headers map[string]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// rawCaptureKey is the context key holding a *rawCapture.
+type rawCaptureKey struct{}
+
+// rawCapture receives the body of the last response to a request.
+type rawCapture struct {
+	body []byte
+}
+
+// withRawCapture returns a context whose requests are recorded by Tap.
+func withRawCapture(ctx context.Context) (context.Context, *rawCapture) {
+	capture := &rawCapture{}
+	return context.WithValue(ctx, rawCaptureKey{}, capture), capture
+}
+
+// Tap copies response bodies into the capture stored in the request
+// context by withRawCapture. Requests without a capture pass through.
+func Tap() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			capture, ok := req.Context().Value(rawCaptureKey{}).(*rawCapture)
+			if err != nil || !ok {
+				return resp, err
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			capture.body = body
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		})
+	}
+}
+
+// timingKey is the context key holding a *timing.
+type timingKey struct{}
+
+// timing receives the HTTP status and time to first byte of the last
+// response to a request.
+type timing struct {
+	status int
+	ttfb   time.Duration
+}
+
+// withTiming returns a context whose requests are measured by Timing.
+func withTiming(ctx context.Context) (context.Context, *timing) {
+	t := &timing{}
+	return context.WithValue(ctx, timingKey{}, t), t
+}
+
+// Timing records the status and the time from sending a request to
+// reading the first byte of its response body into the timing stored in
+// the request context by withTiming. For streamed responses, that is
+// when the first chunk arrives. Requests without a timing pass through.
+func Timing() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			t, ok := req.Context().Value(timingKey{}).(*timing)
+			if !ok {
+				return next.RoundTrip(req)
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return resp, err
+			}
+			*t = timing{status: resp.StatusCode}
+			resp.Body = &firstByteReader{ReadCloser: resp.Body, start: start, ttfb: &t.ttfb}
+			return resp, nil
+		})
+	}
+}
+
+// firstByteReader sets ttfb to the time since start on the first read
+// that returns data.
+type firstByteReader struct {
+	io.ReadCloser
+	start time.Time
+	ttfb  *time.Duration
+	done  bool
+}
+
+// Read implements io.Reader.
+func (r *firstByteReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && !r.done {
+		r.done = true
+		*r.ttfb = time.Since(r.start)
+	}
+	return n, err
+}
@@ -0,0 +1,290 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// reply is a canned response of a scripted transport.
+type reply struct {
+	status     int
+	retryAfter string
+}
+
+// scripted returns a transport answering with replies in turn and
+// recording the body of every request it receives.
+func scripted(replies []reply, bodies *[]string) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body := ""
+		if req.Body != nil {
+			data, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			body = string(data)
+		}
+		*bodies = append(*bodies, body)
+
+		r := replies[len(*bodies)-1]
+		resp := &http.Response{
+			StatusCode: r.status,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}
+		if r.retryAfter != "" {
+			resp.Header.Set("Retry-After", r.retryAfter)
+		}
+		return resp, nil
+	})
+}
+
+func TestRetry(t *testing.T) {
+	tests := map[string]struct {
+		policy     RetryPolicy
+		replies    []reply
+		wantStatus int
+		wantCalls  int
+	}{
+		"503 then 200": {
+			policy:     RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+			replies:    []reply{{status: 503}, {status: 200}},
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+		"retries exhausted": {
+			policy:     RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+			replies:    []reply{{status: 503}, {status: 502}, {status: 429}},
+			wantStatus: 429,
+			wantCalls:  3,
+		},
+		"non-retryable 4xx": {
+			policy:     RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+			replies:    []reply{{status: 400}},
+			wantStatus: 400,
+			wantCalls:  1,
+		},
+		"Retry-After over the budget": {
+			policy:     RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond, MaxElapsed: time.Second},
+			replies:    []reply{{status: 503, retryAfter: "60"}},
+			wantStatus: 503,
+			wantCalls:  1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var bodies []string
+			transport := Chain(scripted(tc.replies, &bodies), Retry(tc.policy))
+
+			req, err := http.NewRequest(http.MethodPost, "http://llm.test/chat", strings.NewReader("payload"))
+			require.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			require.Len(t, bodies, tc.wantCalls)
+			for _, body := range bodies {
+				assert.Equal(t, "payload", body, "the body is rewound for every attempt")
+			}
+		})
+	}
+}
+
+// fakeClock is a clock that only moves when asked to.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func TestRetry_RetryAfter(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	var bodies []string
+	transport := Chain(
+		scripted([]reply{{status: 429, retryAfter: "3"}, {status: 200}}, &bodies),
+		Retry(RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond, now: clock.Now, sleep: clock.Sleep}),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, "http://llm.test/models", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{3 * time.Second}, clock.sleeps, "Retry-After takes precedence over the backoff")
+}
+
+func TestRetry_MaxElapsed(t *testing.T) {
+	const budget = 5 * time.Second
+
+	for _, jitter := range []Jitter{JitterNone, JitterFull, JitterEqual} {
+		t.Run(string(jitter), func(t *testing.T) {
+			for range 50 {
+				clock := &fakeClock{now: time.Now()}
+				start := clock.now
+				var calls []time.Time
+				// A slow-failing provider: every attempt takes 500ms
+				base := RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+					calls = append(calls, clock.now)
+					clock.now = clock.now.Add(500 * time.Millisecond)
+					return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+				})
+				transport := Chain(base, Retry(RetryPolicy{
+					MaxRetries: 20,
+					Backoff:    time.Second,
+					Jitter:     jitter,
+					MaxElapsed: budget,
+					now:        clock.Now,
+					sleep:      clock.Sleep,
+				}))
+
+				req, err := http.NewRequest(http.MethodGet, "http://llm.test/models", nil)
+				require.NoError(t, err)
+				resp, err := transport.RoundTrip(req)
+				require.NoError(t, err)
+
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				// The last attempt itself is bounded by the request timeout
+				assert.LessOrEqual(t, calls[len(calls)-1].Sub(start), budget, "no attempt starts after the budget")
+				assert.Less(t, len(calls), 21, "the budget ends retries before the attempt limit")
+			}
+		})
+	}
+
+	t.Run("exact delays", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		base := RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+			clock.now = clock.now.Add(500 * time.Millisecond)
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		})
+		transport := Chain(base, Retry(RetryPolicy{
+			MaxRetries: 20,
+			Backoff:    time.Second,
+			MaxElapsed: budget,
+			now:        clock.Now,
+			sleep:      clock.Sleep,
+		}))
+
+		req, err := http.NewRequest(http.MethodGet, "http://llm.test/models", nil)
+		require.NoError(t, err)
+		_, err = transport.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.sleeps, "a 4s wait after 4.5s would exceed 5s")
+	})
+}
+
+func TestRetryPolicy_jitter(t *testing.T) {
+	const delay = time.Second
+
+	tests := map[Jitter]struct {
+		min, max time.Duration // Inclusive min, exclusive max
+		varies   bool
+	}{
+		"":          {min: delay, max: delay + 1},
+		JitterNone:  {min: delay, max: delay + 1},
+		JitterFull:  {min: 0, max: delay, varies: true},
+		JitterEqual: {min: delay / 2, max: delay, varies: true},
+	}
+
+	for jitter, tc := range tests {
+		t.Run(string(jitter), func(t *testing.T) {
+			policy := RetryPolicy{Jitter: jitter}
+			seen := make(map[time.Duration]bool)
+			for range 100 {
+				d := policy.jitter(delay)
+				assert.GreaterOrEqual(t, d, tc.min)
+				assert.Less(t, d, tc.max)
+				seen[d] = true
+			}
+			assert.Equal(t, tc.varies, len(seen) > 1)
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var got http.Header
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	transport := Chain(base, Headers(map[string]string{
+		"X-Title":    "tuna",
+		"User-Agent": "tuna/dev",
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://llm.test/models", nil)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", "Go-http-client")
+	req.Header.Set("Accept", "application/json")
+	_, err = transport.RoundTrip(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tuna", got.Get("X-Title"))
+	assert.Equal(t, "tuna/dev", got.Get("User-Agent"), "configured headers override existing ones")
+	assert.Equal(t, "application/json", got.Get("Accept"), "other headers are kept")
+	assert.Equal(t, "Go-http-client", req.Header.Get("User-Agent"), "the caller's request is not modified")
+}
+
+func TestChain_Order(t *testing.T) {
+	var trace []string
+	layer := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				trace = append(trace, name+" in")
+				resp, err := next.RoundTrip(req)
+				trace = append(trace, name+" out")
+				return resp, err
+			})
+		}
+	}
+	base := RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		trace = append(trace, "base")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://llm.test/models", nil)
+	require.NoError(t, err)
+	_, err = Chain(base, layer("outer"), layer("inner")).RoundTrip(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"outer in", "inner in", "base", "inner out", "outer out"}, trace)
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter := rate.NewLimiter(rate.Every(time.Minute), 1)
+	calls := 0
+	base := RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	transport := Chain(base, RateLimit(limiter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://llm.test/models", nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req)
+	require.NoError(t, err, "the first request takes the only token")
+	_, err = transport.RoundTrip(req)
+	require.ErrorIs(t, err, ErrRateLimitDeadline)
+	assert.Equal(t, 1, calls, "a limited request is not sent")
+}
@@ -0,0 +1,51 @@
+package plan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SnapshotDir is the directory under a plan's output directory holding
+// the input files captured by a frozen plan.
+const SnapshotDir = "inputs"
+
+// InputDir returns the directory queries of the plan are read from:
+// the snapshot for frozen plans, the assistant's Input/ otherwise.
+func (p *Plan) InputDir(assistantDir string) string {
+	if p.Frozen {
+		return filepath.Join(p.OutputDir(assistantDir), SnapshotDir)
+	}
+	return filepath.Join(assistantDir, "Input")
+}
+
+// freezeInputs copies the files backing the queries from inputDir
+// into snapshotDir. Sections of a multi-document file share one copy.
+func freezeInputs(inputDir, snapshotDir string, queries []Query) error {
+	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	copied := make(map[string]bool)
+	for _, query := range queries {
+		file, _ := ParseQueryID(query.ID)
+		if copied[file] {
+			continue
+		}
+		copied[file] = true
+
+		data, err := os.ReadFile(filepath.Join(inputDir, file))
+		if err != nil {
+			return fmt.Errorf("failed to read query file: %w", err)
+		}
+		dst := filepath.Join(snapshotDir, file)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return fmt.Errorf("failed to create snapshot directory: %w", err)
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			return fmt.Errorf("failed to write snapshot of %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
@@ -8,41 +8,57 @@ import (
 	"github.com/pelletier/go-toml/v2"
 )
 
-// Load finds and parses a plan by its ID.
-// Searches for plan.toml using glob pattern: */Output/<planID>/plan.toml
-func Load(baseDir, planID string) (*Plan, string, error) {
-	pattern := filepath.Join(baseDir, "*", "Output", planID, "plan.toml")
+// Load finds and parses a plan by its ID, name or name slug.
+// The output directory is tried first, using glob pattern
+// */Output/<ref>/plan.toml; other plans are then matched by name.
+func Load(baseDir, ref string) (*Plan, string, error) {
+	pattern := filepath.Join(baseDir, "*", "Output", ref, "plan.toml")
 
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to search for plan: %w", err)
 	}
 
-	if len(matches) == 0 {
-		return nil, "", fmt.Errorf("plan not found: %s\nRun 'tuna plan <AssistantID>' to create a plan first", planID)
-	}
-
 	if len(matches) > 1 {
-		return nil, "", fmt.Errorf("multiple plans found with ID %s: %v", planID, matches)
+		return nil, "", fmt.Errorf("multiple plans found with ID %s: %v", ref, matches)
 	}
 
-	planPath := matches[0]
+	if len(matches) == 1 {
+		planPath := matches[0]
+		plan, err := LoadFromPath(planPath)
+		if err != nil {
+			return nil, "", err
+		}
+		if !plan.Matches(ref) {
+			return nil, "", fmt.Errorf("plan_id mismatch: expected %s, got %s", ref, plan.PlanID)
+		}
+		return plan, planPath, nil
+	}
 
-	data, err := os.ReadFile(planPath)
+	// Plans with a name live in a slug directory; find them by ID or name
+	entries, err := List(baseDir)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read plan file: %w", err)
+		return nil, "", err
 	}
-
-	var plan Plan
-	if err := toml.Unmarshal(data, &plan); err != nil {
-		return nil, "", fmt.Errorf("failed to parse plan.toml: %w", err)
+	var found []Entry
+	for _, entry := range entries {
+		if entry.Plan.Matches(ref) {
+			found = append(found, entry)
+		}
 	}
 
-	if plan.PlanID != planID {
-		return nil, "", fmt.Errorf("plan_id mismatch: expected %s, got %s", planID, plan.PlanID)
+	switch len(found) {
+	case 0:
+		return nil, "", fmt.Errorf("plan not found: %s\nRun 'tuna plan <AssistantID>' to create a plan first", ref)
+	case 1:
+		return found[0].Plan, found[0].Path, nil
+	default:
+		paths := make([]string, len(found))
+		for i, entry := range found {
+			paths[i] = entry.Path
+		}
+		return nil, "", fmt.Errorf("multiple plans found with ID %s: %v", ref, paths)
 	}
-
-	return &plan, planPath, nil
 }
 
 // LoadFromPath loads a plan directly from a plan.toml file path.
@@ -62,7 +78,35 @@ func LoadFromPath(planPath string) (*Plan, error) {
 
 // AssistantDir returns the assistant directory path from plan.toml path.
 func AssistantDir(planPath string) string {
-	// planPath: <base>/<AssistantID>/Output/<planID>/plan.toml
+	// planPath: <base>/<AssistantID>/Output/<planID or slug>/plan.toml
 	// Go up 3 levels to get AssistantID directory
 	return filepath.Dir(filepath.Dir(filepath.Dir(planPath)))
 }
+
+// Entry describes a plan found on disk.
+type Entry struct {
+	Plan *Plan
+	Path string // Path to plan.toml
+}
+
+// List finds all plans under baseDir using glob pattern: */Output/*/plan.toml
+// Plans that cannot be parsed are skipped.
+func List(baseDir string) ([]Entry, error) {
+	pattern := filepath.Join(baseDir, "*", "Output", "*", "plan.toml")
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for plans: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(matches))
+	for _, path := range matches {
+		p, err := LoadFromPath(path)
+		if err != nil || p.PlanID == "" {
+			continue
+		}
+		entries = append(entries, Entry{Plan: p, Path: path})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package plan
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Slug converts a plan name into a directory name: lowercase letters,
+// digits and single dashes, e.g. "My Experiment #2" -> "my-experiment-2".
+// It returns an empty string if the name has no letters or digits.
+func Slug(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return sb.String()
+}
+
+// DirName returns the name of the plan's output directory: the slug of
+// its name for named plans, the plan ID otherwise.
+func (p *Plan) DirName() string {
+	if slug := Slug(p.Name); slug != "" {
+		return slug
+	}
+	return p.PlanID
+}
+
+// OutputDir returns the plan's output directory in the assistant directory.
+func (p *Plan) OutputDir(assistantDir string) string {
+	return filepath.Join(assistantDir, "Output", p.DirName())
+}
+
+// Matches reports whether ref identifies the plan by ID, name or slug.
+func (p *Plan) Matches(ref string) bool {
+	return ref != "" && (ref == p.PlanID || ref == p.Name || ref == p.DirName())
+}
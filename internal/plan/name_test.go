@@ -0,0 +1,47 @@
+package plan
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"My Experiment #2":    "my-experiment-2",
+		"  leading/trailing ": "leading-trailing",
+		"Ünïcode Tëst":        "ünïcode-tëst",
+		"#!?":                 "",
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, Slug(name), name)
+	}
+}
+
+func TestGenerate_Name(t *testing.T) {
+	baseDir := t.TempDir()
+	testAssistant(t, baseDir, "bot", map[string]string{"q.md": "q"})
+
+	result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, Name: "My Experiment"})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(baseDir, "bot", "Output", "my-experiment", "plan.toml"), result.PlanPath)
+
+	created, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Equal(t, "My Experiment", created.Name)
+	assert.NotEmpty(t, created.PlanID)
+
+	for _, ref := range []string{created.PlanID, "My Experiment", "my-experiment"} {
+		p, path, err := Load(baseDir, ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, created.PlanID, p.PlanID, ref)
+		assert.Equal(t, result.PlanPath, path, ref)
+	}
+
+	_, err = Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, Name: "my experiment"})
+	assert.ErrorContains(t, err, "already exists")
+	_, err = Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, Name: "#!?"})
+	assert.EqualError(t, err, `plan name "#!?" must contain letters or digits`)
+}
@@ -0,0 +1,115 @@
+package plan
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ModelOverride holds sampling parameters of a single model that
+// replace the plan defaults.
+type ModelOverride struct {
+	Temperature *float64 `toml:"temperature,omitempty" json:"temperature,omitempty"` // nil = plan temperature
+	MaxTokens   int      `toml:"max_tokens,omitempty" json:"max_tokens,omitempty"`   // 0 = plan max_tokens
+}
+
+// TemperatureFor returns the effective temperature of a model.
+func (l LLM) TemperatureFor(model string) float64 {
+	if o, ok := l.Overrides[model]; ok && o.Temperature != nil {
+		return *o.Temperature
+	}
+	return l.Temperature
+}
+
+// MaxTokensFor returns the effective max tokens of a model.
+func (l LLM) MaxTokensFor(model string) int {
+	if o, ok := l.Overrides[model]; ok && o.MaxTokens > 0 {
+		return o.MaxTokens
+	}
+	return l.MaxTokens
+}
+
+// ParseModelOverrides parses "model:temperature:max_tokens" overrides.
+// Either value may be empty to keep the plan default, e.g. "gpt-4o::8000".
+// Model names may contain colons, as the values are split off the end.
+func ParseModelOverrides(specs []string) (map[string]ModelOverride, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[string]ModelOverride, len(specs))
+	for _, spec := range specs {
+		parts := strings.Split(spec, ":")
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid override %q: expected model:temperature:max_tokens", spec)
+		}
+		n := len(parts)
+		model := strings.TrimSpace(strings.Join(parts[:n-2], ":"))
+		temperature, maxTokens := strings.TrimSpace(parts[n-2]), strings.TrimSpace(parts[n-1])
+		if model == "" {
+			return nil, fmt.Errorf("invalid override %q: model is empty", spec)
+		}
+
+		var o ModelOverride
+		if temperature != "" {
+			t, err := strconv.ParseFloat(temperature, 64)
+			if err != nil || t < 0 {
+				return nil, fmt.Errorf("invalid override %q: temperature must be a non-negative number", spec)
+			}
+			o.Temperature = &t
+		}
+		if maxTokens != "" {
+			m, err := strconv.Atoi(maxTokens)
+			if err != nil || m <= 0 {
+				return nil, fmt.Errorf("invalid override %q: max_tokens must be a positive integer", spec)
+			}
+			o.MaxTokens = m
+		}
+		if o.Temperature == nil && o.MaxTokens == 0 {
+			return nil, fmt.Errorf("invalid override %q: set a temperature, max_tokens or both", spec)
+		}
+		overrides[model] = o
+	}
+	return overrides, nil
+}
+
+// matchOverrides keys overrides by the plan models they apply to. An
+// override applies to a model if both are the same or resolve to the same
+// model through aliases (alias -> full name); each must apply to one.
+func matchOverrides(models []string, overrides map[string]ModelOverride, aliases map[string]string) (map[string]ModelOverride, error) {
+	if len(overrides) == 0 {
+		return overrides, nil
+	}
+	resolve := func(name string) string {
+		if fullName, ok := aliases[name]; ok {
+			return fullName
+		}
+		return name
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	matched := make(map[string]ModelOverride, len(overrides))
+	for _, key := range keys {
+		found := false
+		for _, model := range models {
+			if key != model && resolve(key) != resolve(model) {
+				continue
+			}
+			if _, ok := matched[model]; ok {
+				return nil, fmt.Errorf("several overrides for %q", model)
+			}
+			matched[model] = overrides[key]
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("override for %q, which is not one of the plan models", key)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,46 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModelOverrides(t *testing.T) {
+	overrides, err := ParseModelOverrides([]string{"gpt-4o:0.2:8000", "o1::4000", "ollama:llama3:0.7:"})
+	require.NoError(t, err)
+	require.Len(t, overrides, 3)
+	require.NotNil(t, overrides["gpt-4o"].Temperature)
+	assert.Equal(t, 0.2, *overrides["gpt-4o"].Temperature)
+	assert.Equal(t, 8000, overrides["gpt-4o"].MaxTokens)
+	assert.Nil(t, overrides["o1"].Temperature)
+	assert.Equal(t, 4000, overrides["o1"].MaxTokens)
+	require.NotNil(t, overrides["ollama:llama3"].Temperature, "model names may contain colons")
+	assert.Equal(t, 0.7, *overrides["ollama:llama3"].Temperature)
+
+	for _, spec := range []string{"gpt-4o:0.2", ":0.2:100", "gpt-4o:hot:100", "gpt-4o:0.2:-1", "gpt-4o::"} {
+		_, err := ParseModelOverrides([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestLLM_For(t *testing.T) {
+	temperature := 0.2
+	llm := LLM{
+		Models:      []string{"gpt-4o", "o1", "sonnet"},
+		Temperature: 0.7,
+		MaxTokens:   1000,
+		Overrides: map[string]ModelOverride{
+			"gpt-4o": {Temperature: &temperature, MaxTokens: 8000},
+			"o1":     {MaxTokens: 4000},
+		},
+	}
+
+	assert.Equal(t, 0.2, llm.TemperatureFor("gpt-4o"))
+	assert.Equal(t, 8000, llm.MaxTokensFor("gpt-4o"))
+	assert.Equal(t, 0.7, llm.TemperatureFor("o1"))
+	assert.Equal(t, 4000, llm.MaxTokensFor("o1"))
+	assert.Equal(t, 0.7, llm.TemperatureFor("sonnet"))
+	assert.Equal(t, 1000, llm.MaxTokensFor("sonnet"))
+}
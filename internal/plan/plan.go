@@ -4,8 +4,10 @@ import (
 	"crypto/rand"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,20 +22,69 @@ type Config struct {
 	Models      []string
 	Temperature float64
 	MaxTokens   int
+	QueryPrefix string
+	QuerySuffix string
+	NoQueries   bool   // Create a prompt-only plan without collecting queries
+	QueryFile   string // Multi-document file in Input/ to split into queries
+	Freeze      bool   // Snapshot query files so exec ignores later edits
+
+	// SystemPromptPrefix is prepended to the compiled system prompt.
+	SystemPromptPrefix string
+	// Variables are substituted into {{.Var}} placeholders of queries.
+	Variables map[string]string
+	// InputGlobs restrict queries to Input/ files matching any pattern.
+	InputGlobs []string
+	// PromptVariant names a subdirectory of System prompt/ to compile.
+	PromptVariant string
+	// Name is a human-readable plan name; its Slug names the output directory.
+	Name string
+	// Sampling parameters; zero TopP and nil Seed are not sent.
+	TopP float64
+	Seed *int
+	// ModelOverrides replace the temperature or max tokens of listed models.
+	ModelOverrides map[string]ModelOverride
+	// Aliases map configured model aliases to full names, so overrides
+	// may name models either way.
+	Aliases map[string]string
 }
 
 // Plan represents the generated plan structure.
 type Plan struct {
 	PlanID      string    `toml:"plan_id"`
+	Name        string    `toml:"name,omitempty"` // Output directory is its Slug
 	AssistantID string    `toml:"assistant_id"`
+	Frozen      bool      `toml:"frozen,omitempty"` // Queries are read from SnapshotDir
 	Assistant   Assistant `toml:"assistant"`
 	Queries     []Query   `toml:"query"`
+
+	// ImportedModels have responses imported with 'tuna view --import'.
+	// They are shown and rated like plan models but never executed.
+	ImportedModels []string `toml:"imported_models,omitempty"`
+}
+
+// ViewModels returns the plan models followed by the imported ones,
+// i.e. every model with a column in the view.
+func (p *Plan) ViewModels() []string {
+	models := slices.Clone(p.Assistant.LLM.Models)
+	for _, model := range p.ImportedModels {
+		if !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	return models
 }
 
 // Assistant holds assistant configuration.
 type Assistant struct {
 	SystemPrompt string `toml:"system_prompt,multiline"`
+	QueryPrefix  string `toml:"query_prefix,multiline,omitempty"` // Prepended to every query
+	QuerySuffix  string `toml:"query_suffix,multiline,omitempty"` // Appended to every query
 	LLM          LLM    `toml:"llm"`
+
+	// Variables are substituted into {{.Var}} placeholders of queries.
+	Variables map[string]string `toml:"variables,omitempty"`
+	// PromptVariant is the system prompt variant compiled into the plan.
+	PromptVariant string `toml:"prompt_variant,omitempty"`
 }
 
 // LLM holds LLM configuration.
@@ -41,6 +92,11 @@ type LLM struct {
 	Models      []string `toml:"models"`
 	MaxTokens   int      `toml:"max_tokens"`
 	Temperature float64  `toml:"temperature"`
+	TopP        float64  `toml:"top_p,omitempty"` // 0 = provider default
+	Seed        *int     `toml:"seed,omitempty"`  // nil = not sent
+
+	// Overrides replace the temperature or max tokens of listed models.
+	Overrides map[string]ModelOverride `toml:"overrides,omitempty"`
 }
 
 // Query represents an input query entry.
@@ -69,59 +125,115 @@ func Generate(baseDir, assistantID string, cfg Config) (*Result, error) {
 		return nil, fmt.Errorf("assistant directory not found: %s", assistantDir)
 	}
 
+	overrides, err := matchOverrides(cfg.Models, cfg.ModelOverrides, cfg.Aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Name != "" && Slug(cfg.Name) == "" {
+		return nil, fmt.Errorf("plan name %q must contain letters or digits", cfg.Name)
+	}
+
 	// Generate plan ID
 	planID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
 
 	// Compile system prompt
-	systemPrompt, err := assistant.CompileSystemPrompt(assistantDir)
+	systemPrompt, err := assistant.CompileSystemPromptVariant(assistantDir, cfg.PromptVariant)
 	if err != nil {
 		return nil, err
 	}
+	systemPrompt = assistant.PrependPrefix(systemPrompt, cfg.SystemPromptPrefix)
 
 	// Collect queries
+	queries := []Query{}
 	inputDir := filepath.Join(assistantDir, "Input")
-	queryFiles, err := assistant.ListFiles(inputDir, assistant.DefaultFilter())
-	if err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("failed to read input directory: %w", err)
+	ignore, err := assistant.LoadIgnore(assistantDir)
+	if err != nil {
+		return nil, err
 	}
+	switch {
+	case cfg.NoQueries:
+	case cfg.QueryFile != "":
+		if ignore.Match(path.Join("Input", filepath.ToSlash(cfg.QueryFile))) {
+			return nil, fmt.Errorf("query file %s is excluded by %s", cfg.QueryFile, assistant.IgnoreFileName)
+		}
+		data, err := os.ReadFile(filepath.Join(inputDir, cfg.QueryFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query file: %w", err)
+		}
+		for i := range SplitSections(string(data)) {
+			queries = append(queries, Query{ID: SectionID(cfg.QueryFile, i+1)})
+		}
+	default:
+		filter := assistant.DefaultFilter()
+		filter.Ignore = ignore
+		queryFiles, err := assistant.ListFiles(inputDir, filter)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read input directory: %w", err)
+		}
 
-	queries := make([]Query, len(queryFiles))
-	for i, filename := range queryFiles {
-		queries[i] = Query{ID: filename}
+		queryFiles, err = matchInputGlobs(queryFiles, cfg.InputGlobs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, filename := range queryFiles {
+			if _, section := ParseQueryID(filename); section > 0 {
+				return nil, fmt.Errorf("query file %s is named like a section of a multi-document file; rename it", filename)
+			}
+			queries = append(queries, Query{ID: filename})
+		}
+	}
+	if err := CheckResponseNames(queries); err != nil {
+		return nil, err
 	}
 
 	// Build plan
 	plan := Plan{
 		PlanID:      planID,
+		Name:        cfg.Name,
 		AssistantID: normalizedID,
+		Frozen:      cfg.Freeze,
 		Assistant: Assistant{
 			SystemPrompt: systemPrompt,
+			QueryPrefix:  cfg.QueryPrefix,
+			QuerySuffix:  cfg.QuerySuffix,
+			Variables:    cfg.Variables,
+
+			PromptVariant: cfg.PromptVariant,
 			LLM: LLM{
 				Models:      cfg.Models,
 				MaxTokens:   cfg.MaxTokens,
 				Temperature: cfg.Temperature,
+				TopP:        cfg.TopP,
+				Seed:        cfg.Seed,
+				Overrides:   overrides,
 			},
 		},
 		Queries: queries,
 	}
 
 	// Create output directory
-	outputDir := filepath.Join(assistantDir, "Output", planID)
+	outputDir := plan.OutputDir(assistantDir)
+	if cfg.Name != "" {
+		if _, err := os.Stat(outputDir); err == nil {
+			return nil, fmt.Errorf("a plan named %q already exists: %s", cfg.Name, outputDir)
+		}
+	}
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Write plan.toml
-	planPath := filepath.Join(outputDir, "plan.toml")
-	data, err := toml.Marshal(plan)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	if cfg.Freeze {
+		if err := freezeInputs(inputDir, filepath.Join(outputDir, SnapshotDir), queries); err != nil {
+			return nil, err
+		}
 	}
 
-	formatted := formatTOML(data)
-
-	if err := os.WriteFile(planPath, formatted, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write plan.toml: %w", err)
+	// Write plan.toml
+	planPath := filepath.Join(outputDir, "plan.toml")
+	if err := Save(planPath, &plan); err != nil {
+		return nil, err
 	}
 
 	return &Result{
@@ -132,6 +244,74 @@ func Generate(baseDir, assistantID string, cfg Config) (*Result, error) {
 	}, nil
 }
 
+// Save writes the plan to the given plan.toml path.
+func Save(planPath string, p *Plan) error {
+	data, err := toml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	if err := os.WriteFile(planPath, formatTOML(data), 0644); err != nil {
+		return fmt.Errorf("failed to write plan.toml: %w", err)
+	}
+
+	return nil
+}
+
+// matchInputGlobs returns the files matching any of the patterns,
+// or all files if there are no patterns. Every pattern must match
+// at least one file.
+func matchInputGlobs(files, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return files, nil
+	}
+
+	matched := make(map[string]bool)
+	for _, pattern := range patterns {
+		found := false
+		for _, file := range files {
+			ok, err := filepath.Match(pattern, file)
+			if err != nil {
+				return nil, fmt.Errorf("invalid input glob %q: %w", pattern, err)
+			}
+			if ok {
+				matched[file] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("input glob %q matches no files in Input/", pattern)
+		}
+	}
+
+	var selected []string
+	for _, file := range files {
+		if matched[file] {
+			selected = append(selected, file)
+		}
+	}
+	return selected, nil
+}
+
+// ParseVariables parses key=value pairs into a variables map.
+// Values may contain "="; later pairs override earlier ones.
+func ParseVariables(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
 // ParseModels splits comma-separated models string into a slice.
 func ParseModels(modelsStr string) []string {
 	if modelsStr == "" {
@@ -148,6 +328,43 @@ func ParseModels(modelsStr string) []string {
 	return models
 }
 
+// ReadModelsFile reads models from a file with one model per line.
+// Blank lines and lines starting with # are ignored.
+func ReadModelsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models file: %w", err)
+	}
+
+	var models []string
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		models = append(models, line)
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("models file %s lists no models", path)
+	}
+	return models, nil
+}
+
+// MergeModels concatenates model lists, keeping the first occurrence of each model.
+func MergeModels(lists ...[]string) []string {
+	var models []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, model := range list {
+			if !seen[model] {
+				seen[model] = true
+				models = append(models, model)
+			}
+		}
+	}
+	return models
+}
+
 // formatTOML adds consistent spacing between TOML sections.
 // It ensures exactly one blank line before each section header
 // and exactly one newline at the end of the file.
@@ -0,0 +1,324 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
+)
+
+// testAssistant creates an assistant with a system prompt and the given
+// Input files under baseDir.
+func testAssistant(t *testing.T, baseDir, assistantID string, inputs map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(baseDir, assistantID)
+	promptDir := filepath.Join(dir, assistant.SystemPromptDir)
+	require.NoError(t, os.MkdirAll(promptDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(promptDir, "role.md"), []byte("Be brief."), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Input"), 0755))
+	for name, content := range inputs {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Input", name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestGenerate_Ignore(t *testing.T) {
+	baseDir := t.TempDir()
+	dir := testAssistant(t, baseDir, "bot", map[string]string{
+		"query.md":       "q",
+		"draft_1.md":     "d",
+		"draft_final.md": "f",
+		"multi.md":       "one\n---\ntwo",
+	})
+	ignore := "draft_*.md\n!draft_final.md\n**/multi.md\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, assistant.IgnoreFileName), []byte(ignore), 0644))
+
+	result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}})
+	require.NoError(t, err)
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Equal(t, []Query{{ID: "draft_final.md"}, {ID: "query.md"}}, p.Queries)
+
+	_, err = Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, QueryFile: "multi.md"})
+	assert.ErrorContains(t, err, "excluded by .tunaignore")
+}
+
+func TestGenerate_QueryFile(t *testing.T) {
+	baseDir := t.TempDir()
+	testAssistant(t, baseDir, "bot", map[string]string{"multi.md": "one\n---\ntwo\n"})
+
+	result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, QueryFile: "multi.md"})
+	require.NoError(t, err)
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Equal(t, []Query{{ID: "multi.md#1"}, {ID: "multi.md#2"}}, p.Queries)
+
+	dir := filepath.Join(baseDir, "bot")
+	content, err := ReadQuery(filepath.Join(dir, "Input"), "multi.md#2")
+	require.NoError(t, err)
+	assert.Equal(t, "two\n", content)
+}
+
+func TestGenerate_InputGlobs(t *testing.T) {
+	tests := map[string]struct {
+		globs    []string
+		expected []Query
+		err      string
+	}{
+		"no globs": {
+			expected: []Query{{ID: "notes.md"}, {ID: "topic_a.md"}, {ID: "topic_b.md"}},
+		},
+		"single glob": {
+			globs:    []string{"topic_*.md"},
+			expected: []Query{{ID: "topic_a.md"}, {ID: "topic_b.md"}},
+		},
+		"overlapping globs": {
+			globs:    []string{"topic_a.md", "*_a.md", "notes.*"},
+			expected: []Query{{ID: "notes.md"}, {ID: "topic_a.md"}},
+		},
+		"no match": {
+			globs: []string{"topic_*.md", "faq_*.md"},
+			err:   `input glob "faq_*.md" matches no files in Input/`,
+		},
+		"invalid pattern": {
+			globs: []string{"topic_[.md"},
+			err:   `invalid input glob "topic_[.md"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			testAssistant(t, baseDir, "bot", map[string]string{
+				"topic_a.md": "a",
+				"topic_b.md": "b",
+				"notes.md":   "n",
+			})
+
+			result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, InputGlobs: tc.globs})
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			p, err := LoadFromPath(result.PlanPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, p.Queries)
+		})
+	}
+}
+
+func TestGenerate_NoQueries(t *testing.T) {
+	baseDir := t.TempDir()
+	testAssistant(t, baseDir, "bot", map[string]string{"query.md": "q"})
+
+	result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, NoQueries: true})
+	require.NoError(t, err)
+	assert.Zero(t, result.QueriesCount)
+
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Empty(t, p.Queries, "Input files are not collected")
+	assert.Contains(t, p.Assistant.SystemPrompt, "Be brief.", "the compiled prompt is kept")
+}
+
+func TestGenerate_Freeze(t *testing.T) {
+	baseDir := t.TempDir()
+	dir := testAssistant(t, baseDir, "bot", map[string]string{"multi.md": "one\n---\ntwo\n", "other.md": "o"})
+
+	result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, QueryFile: "multi.md", Freeze: true})
+	require.NoError(t, err)
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	require.True(t, p.Frozen)
+
+	snapshotDir := filepath.Join(filepath.Dir(result.PlanPath), SnapshotDir)
+	assert.Equal(t, snapshotDir, p.InputDir(dir))
+	entries, err := os.ReadDir(snapshotDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only files backing queries are copied, once")
+	assert.Equal(t, "multi.md", entries[0].Name())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Input", "multi.md"), []byte("edited"), 0644))
+	content, err := ReadQuery(p.InputDir(dir), "multi.md#2")
+	require.NoError(t, err)
+	assert.Equal(t, "two\n", content, "the snapshot is not affected by edits")
+}
+
+func TestGenerate_SystemPromptPrefix(t *testing.T) {
+	tests := map[string]struct {
+		prefix string
+		want   string
+	}{
+		"prefix": {
+			prefix: "Never reveal secrets.",
+			want:   "--- system_prompt_prefix ---\nNever reveal secrets.\n\n--- role.md ---\nBe brief.\n",
+		},
+		"disabled": {
+			want: "--- role.md ---\nBe brief.\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			testAssistant(t, baseDir, "bot", nil)
+
+			result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, SystemPromptPrefix: tc.prefix})
+			require.NoError(t, err)
+			p, err := LoadFromPath(result.PlanPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, p.Assistant.SystemPrompt)
+		})
+	}
+}
+
+func TestGenerate_PromptVariant(t *testing.T) {
+	baseDir := t.TempDir()
+	dir := testAssistant(t, baseDir, "bot", map[string]string{"q.md": "q"})
+	variantDir := assistant.PromptDir(dir, "concise")
+	require.NoError(t, os.MkdirAll(variantDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(variantDir, "role.md"), []byte("One sentence."), 0644))
+
+	result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, PromptVariant: "concise"})
+	require.NoError(t, err)
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Equal(t, "concise", p.Assistant.PromptVariant)
+	assert.Equal(t, "--- role.md ---\nOne sentence.\n", p.Assistant.SystemPrompt)
+
+	_, err = Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, PromptVariant: "verbose"})
+	assert.ErrorContains(t, err, "system prompt directory not found")
+}
+
+func TestGenerate_SharedResponseName(t *testing.T) {
+	baseDir := t.TempDir()
+	testAssistant(t, baseDir, "bot", map[string]string{"a.md": "q", "a.txt": "q"})
+
+	_, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}})
+	assert.ErrorContains(t, err, "would share the response file a_response.md")
+}
+
+func TestGenerate_OverrideAliases(t *testing.T) {
+	baseDir := t.TempDir()
+	testAssistant(t, baseDir, "bot", map[string]string{"q.md": "q"})
+	cfg := Config{
+		Models:         []string{"sonnet", "gpt-4o"},
+		MaxTokens:      1000,
+		ModelOverrides: map[string]ModelOverride{"claude-sonnet-4": {MaxTokens: 4000}},
+		Aliases:        map[string]string{"sonnet": "claude-sonnet-4"},
+	}
+
+	result, err := Generate(baseDir, "bot", cfg)
+	require.NoError(t, err)
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Equal(t, 4000, p.Assistant.LLM.MaxTokensFor("sonnet"))
+	assert.Equal(t, 1000, p.Assistant.LLM.MaxTokensFor("gpt-4o"))
+
+	cfg.ModelOverrides = map[string]ModelOverride{"o1": {MaxTokens: 10}}
+	_, err = Generate(baseDir, "bot", cfg)
+	assert.EqualError(t, err, `override for "o1", which is not one of the plan models`)
+}
+
+func TestGenerate_Sampling(t *testing.T) {
+	baseDir := t.TempDir()
+	testAssistant(t, baseDir, "bot", map[string]string{"q.md": "q"})
+	seed := 42
+
+	result, err := Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}, TopP: 0.9, Seed: &seed})
+	require.NoError(t, err)
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, p.Assistant.LLM.TopP)
+	require.NotNil(t, p.Assistant.LLM.Seed)
+	assert.Equal(t, 42, *p.Assistant.LLM.Seed)
+
+	result, err = Generate(baseDir, "bot", Config{Models: []string{"gpt-4o"}})
+	require.NoError(t, err)
+	data, err := os.ReadFile(result.PlanPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "top_p")
+	assert.NotContains(t, string(data), "seed")
+}
+
+func TestReadModelsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "models.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# Frontier\ngpt-4o\n\n  sonnet  \n# Local\nollama:llama3\n"), 0644))
+
+	models, err := ReadModelsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o", "sonnet", "ollama:llama3"}, models)
+
+	empty := filepath.Join(dir, "empty.txt")
+	require.NoError(t, os.WriteFile(empty, []byte("# nothing\n\n"), 0644))
+	_, err = ReadModelsFile(empty)
+	assert.EqualError(t, err, "models file "+empty+" lists no models")
+
+	_, err = ReadModelsFile(filepath.Join(dir, "missing.txt"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestGenerate_MergedModels(t *testing.T) {
+	baseDir := t.TempDir()
+	testAssistant(t, baseDir, "bot", map[string]string{"q.md": "q"})
+	path := filepath.Join(baseDir, "models.txt")
+	require.NoError(t, os.WriteFile(path, []byte("sonnet\ngpt-4o\no1\n"), 0644))
+	fileModels, err := ReadModelsFile(path)
+	require.NoError(t, err)
+
+	models := MergeModels(ParseModels("gpt-4o,mini"), fileModels)
+	assert.Equal(t, []string{"gpt-4o", "mini", "sonnet", "o1"}, models)
+
+	result, err := Generate(baseDir, "bot", Config{Models: models})
+	require.NoError(t, err)
+	p, err := LoadFromPath(result.PlanPath)
+	require.NoError(t, err)
+	assert.Equal(t, models, p.Assistant.LLM.Models)
+}
+
+func TestParseVariables(t *testing.T) {
+	tests := map[string]struct {
+		pairs    []string
+		expected map[string]string
+		err      string
+	}{
+		"none": {},
+		"pairs": {
+			pairs:    []string{"Lang=Go", " Topic =channels"},
+			expected: map[string]string{"Lang": "Go", "Topic": "channels"},
+		},
+		"value with equals sign": {
+			pairs:    []string{"Expr=a=b"},
+			expected: map[string]string{"Expr": "a=b"},
+		},
+		"later pair wins": {
+			pairs:    []string{"Lang=Go", "Lang=Rust"},
+			expected: map[string]string{"Lang": "Rust"},
+		},
+		"missing value": {
+			pairs: []string{"Lang"},
+			err:   `invalid variable "Lang": expected key=value`,
+		},
+		"empty key": {
+			pairs: []string{"=Go"},
+			err:   `invalid variable "=Go": expected key=value`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			vars, err := ParseVariables(test.pairs)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, vars)
+		})
+	}
+}
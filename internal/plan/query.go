@@ -0,0 +1,161 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// binaryThreshold is the share of non-printable characters above which
+// a query file is considered binary rather than text.
+const binaryThreshold = 0.1
+
+// sniffLength is how many leading bytes of a file LooksBinary inspects.
+const sniffLength = 8192
+
+// sectionSeparator joins a multi-document file name and a section number
+// into a synthetic query ID, e.g. "queries.md#2".
+const sectionSeparator = "#"
+
+// SectionID returns the synthetic query ID of the n-th (1-based)
+// section of a multi-document query file.
+func SectionID(file string, n int) string {
+	return file + sectionSeparator + strconv.Itoa(n)
+}
+
+// ParseQueryID splits a query ID into the file name and the 1-based
+// section number. Section is 0 for regular, single-query files. Only IDs
+// exactly as built by SectionID are sections, so "queries.md#02" or
+// "queries.md#+2" are regular file names.
+func ParseQueryID(id string) (file string, section int) {
+	i := strings.LastIndex(id, sectionSeparator)
+	if i <= 0 {
+		return id, 0
+	}
+	n, err := strconv.Atoi(id[i+1:])
+	if err != nil || n < 1 || SectionID(id[:i], n) != id {
+		return id, 0
+	}
+	return id[:i], n
+}
+
+// ResponseBaseName returns the response file name prefix for a query:
+// "query_001.md" -> "query_001", "queries.md#2" -> "queries#2".
+// Different queries may share a name, e.g. "a.md" and "a.txt";
+// CheckResponseNames reports them.
+func ResponseBaseName(queryID string) string {
+	file, section := ParseQueryID(queryID)
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	if section > 0 {
+		base += sectionSeparator + strconv.Itoa(section)
+	}
+	return base
+}
+
+// CheckResponseNames returns an error if queries would share a response file.
+func CheckResponseNames(queries []Query) error {
+	var errs []error
+	names := make(map[string]string, len(queries))
+	for _, q := range queries {
+		base := ResponseBaseName(q.ID)
+		if other, ok := names[base]; ok {
+			errs = append(errs, fmt.Errorf("queries %s and %s would share the response file %s_response.md; rename one of them", other, q.ID, base))
+			continue
+		}
+		names[base] = q.ID
+	}
+	return errors.Join(errs...)
+}
+
+// SplitSections splits a multi-document query file into sections.
+// Sections are separated by "---" lines; if there are none, each "## "
+// heading starts a new section and stays part of it. Blank sections
+// are dropped.
+func SplitSections(content string) []string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	bySeparator := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "---" {
+			bySeparator = true
+			break
+		}
+	}
+
+	var (
+		sections []string
+		current  []string
+	)
+	flush := func() {
+		if section := strings.TrimSpace(strings.Join(current, "\n")); section != "" {
+			sections = append(sections, section+"\n")
+		}
+		current = nil
+	}
+	for _, line := range lines {
+		switch {
+		case bySeparator && strings.TrimSpace(line) == "---":
+			flush()
+			continue
+		case !bySeparator && strings.HasPrefix(line, "## "):
+			flush()
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return sections
+}
+
+// ReadQuery returns the content of a query from the input directory,
+// resolving synthetic IDs to a section of a multi-document file.
+func ReadQuery(inputDir, queryID string) (string, error) {
+	file, section := ParseQueryID(queryID)
+	path := filepath.Join(inputDir, file)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read query file %s: %w", path, err)
+	}
+	if LooksBinary(data) {
+		return "", fmt.Errorf("query file %s looks binary, not text; check input_extensions", path)
+	}
+	if section == 0 {
+		return string(data), nil
+	}
+
+	sections := SplitSections(string(data))
+	if section > len(sections) {
+		return "", fmt.Errorf("query %s: %s has only %d sections", queryID, file, len(sections))
+	}
+	return sections[section-1], nil
+}
+
+// LooksBinary reports whether data looks like binary content: it contains
+// a NUL byte, or more than binaryThreshold of its leading characters are
+// invalid UTF-8 or non-printable control characters.
+func LooksBinary(data []byte) bool {
+	if len(data) > sniffLength {
+		data = data[:sniffLength]
+	}
+
+	var total, bad int
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		total++
+		switch {
+		case r == 0:
+			return true
+		case r == utf8.RuneError && size == 1,
+			unicode.IsControl(r) && !unicode.IsSpace(r):
+			bad++
+		}
+	}
+	return total > 0 && float64(bad)/float64(total) > binaryThreshold
+}
@@ -0,0 +1,81 @@
+package plan
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryID(t *testing.T) {
+	tests := []struct {
+		id      string
+		file    string
+		section int
+	}{
+		{"query.md", "query.md", 0},
+		{"queries.md#2", "queries.md", 2},
+		{"queries.md#12", "queries.md", 12},
+		{"queries.md#02", "queries.md#02", 0},
+		{"queries.md#+2", "queries.md#+2", 0},
+		{"queries.md#0", "queries.md#0", 0},
+		{"#2", "#2", 0},
+		{"issue#2.md", "issue#2.md", 0},
+	}
+	for _, tt := range tests {
+		file, section := ParseQueryID(tt.id)
+		assert.Equal(t, tt.file, file, tt.id)
+		assert.Equal(t, tt.section, section, tt.id)
+	}
+}
+
+func TestResponseBaseName(t *testing.T) {
+	assert.Equal(t, "query_001", ResponseBaseName("query_001.md"))
+	assert.Equal(t, "queries#2", ResponseBaseName("queries.md#2"))
+	assert.NotEqual(t, ResponseBaseName("queries_2.md"), ResponseBaseName("queries.md#2"))
+}
+
+func TestCheckResponseNames(t *testing.T) {
+	assert.NoError(t, CheckResponseNames([]Query{{ID: "queries_2.md"}, {ID: "queries.md#2"}}))
+
+	err := CheckResponseNames([]Query{{ID: "a.md"}, {ID: "b.md"}, {ID: "a.txt"}})
+	assert.EqualError(t, err, "queries a.md and a.txt would share the response file a_response.md; rename one of them")
+}
+
+func TestLooksBinary(t *testing.T) {
+	tests := map[string]struct {
+		data   []byte
+		binary bool
+	}{
+		"empty":             {data: nil},
+		"markdown":          {data: []byte("# Title\n\nWhat is Go?\tExplain.\r\n")},
+		"unicode":           {data: []byte("Что такое Go? 日本語 🐹\n")},
+		"nul byte":          {data: []byte("text\x00more text"), binary: true},
+		"png header":        {data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), binary: true},
+		"invalid utf-8":     {data: bytes.Repeat([]byte{0xff, 0xfe, 'a'}, 10), binary: true},
+		"few control":       {data: append(bytes.Repeat([]byte("a"), 95), bytes.Repeat([]byte{0x1b}, 5)...)},
+		"many control":      {data: append(bytes.Repeat([]byte("a"), 80), bytes.Repeat([]byte{0x1b}, 20)...), binary: true},
+		"binary past sniff": {data: append(bytes.Repeat([]byte("a"), 8192), 0)},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.binary, LooksBinary(tc.data))
+		})
+	}
+}
+
+func TestReadQuery_Binary(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "text.md"), []byte("What is Go?\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.md"), []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0644))
+
+	content, err := ReadQuery(dir, "text.md")
+	require.NoError(t, err)
+	assert.Equal(t, "What is Go?\n", content)
+
+	_, err = ReadQuery(dir, "image.md")
+	assert.EqualError(t, err, "query file "+filepath.Join(dir, "image.md")+" looks binary, not text; check input_extensions")
+}
@@ -0,0 +1,102 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
+)
+
+// RenameAssistant moves the assistant directory oldID under baseDir to
+// newID and rewrites assistant_id in every plan of its Output tree, so
+// the plans keep loading under the new name. It returns the paths of
+// the updated plan.toml files.
+//
+// Only newID must be a valid assistant ID, so that directories created
+// before IDs were validated can be renamed to valid ones. Every plan is
+// parsed before anything is moved; if a plan cannot be rewritten, the
+// rewritten plans are restored and the directory is moved back.
+func RenameAssistant(baseDir, oldID, newID string) ([]string, error) {
+	if err := assistant.ValidateID(newID); err != nil {
+		return nil, fmt.Errorf("invalid assistant ID %q: %w", newID, err)
+	}
+	if oldID == "" || oldID == "." || oldID == ".." || filepath.Base(oldID) != oldID {
+		return nil, fmt.Errorf("invalid assistant directory name %q", oldID)
+	}
+	if oldID == newID {
+		return nil, fmt.Errorf("assistant is already named %s", newID)
+	}
+
+	oldDir := filepath.Join(baseDir, oldID)
+	newDir := filepath.Join(baseDir, newID)
+	if info, err := os.Stat(oldDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("assistant directory not found: %s", oldDir)
+	}
+	if _, err := os.Stat(newDir); err == nil {
+		return nil, fmt.Errorf("assistant %s already exists: %s", newID, newDir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to check %s: %w", newDir, err)
+	}
+
+	// Parse every plan up front, keeping the original files for rollback
+	matches, err := filepath.Glob(filepath.Join(oldDir, "Output", "*", "plan.toml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for plans: %w", err)
+	}
+	var plans []renamedPlan
+	for _, path := range matches {
+		p, err := LoadFromPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if p.AssistantID == newID {
+			continue
+		}
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		rel, _ := filepath.Rel(oldDir, path)
+		plans = append(plans, renamedPlan{rel: rel, plan: p, original: original})
+	}
+
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return nil, fmt.Errorf("failed to rename assistant directory: %w", err)
+	}
+
+	var updated []string
+	for i, rp := range plans {
+		path := filepath.Join(newDir, rp.rel)
+		rp.plan.AssistantID = newID
+		if err := Save(path, rp.plan); err != nil {
+			return nil, errors.Join(fmt.Errorf("%s: %w", path, err),
+				rollbackRename(oldDir, newDir, plans[:i]))
+		}
+		updated = append(updated, path)
+	}
+	return updated, nil
+}
+
+// renamedPlan is a plan whose assistant_id RenameAssistant rewrites.
+type renamedPlan struct {
+	rel      string // plan.toml path relative to the assistant directory
+	plan     *Plan
+	original []byte // File content before the rename
+}
+
+// rollbackRename restores the plans already rewritten in newDir and
+// moves the assistant directory back to oldDir.
+func rollbackRename(oldDir, newDir string, rewritten []renamedPlan) error {
+	var errs []error
+	for _, rp := range rewritten {
+		if err := os.WriteFile(filepath.Join(newDir, rp.rel), rp.original, 0644); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", rp.rel, err))
+		}
+	}
+	if err := os.Rename(newDir, oldDir); err != nil {
+		errs = append(errs, fmt.Errorf("failed to move assistant directory back: %w", err))
+	}
+	return errors.Join(errs...)
+}
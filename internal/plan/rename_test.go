@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePlan saves a plan of assistantID under baseDir/dir/Output/planID.
+func writePlan(t *testing.T, baseDir, dir, assistantID, planID string) string {
+	t.Helper()
+	path := filepath.Join(baseDir, dir, "Output", planID, "plan.toml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, Save(path, &Plan{PlanID: planID, AssistantID: assistantID}))
+	return path
+}
+
+func TestRenameAssistant(t *testing.T) {
+	baseDir := t.TempDir()
+	writePlan(t, baseDir, "old", "old", "p1")
+	writePlan(t, baseDir, "old", "old", "p2")
+
+	updated, err := RenameAssistant(baseDir, "old", "new")
+	require.NoError(t, err)
+	assert.Len(t, updated, 2)
+	assert.NoDirExists(t, filepath.Join(baseDir, "old"))
+	for _, path := range updated {
+		p, err := LoadFromPath(path)
+		require.NoError(t, err)
+		assert.Equal(t, "new", p.AssistantID)
+	}
+}
+
+func TestRenameAssistant_LegacyID(t *testing.T) {
+	baseDir := t.TempDir()
+	writePlan(t, baseDir, "Support: Bot", "Support: Bot", "p1")
+
+	_, err := RenameAssistant(baseDir, "Support: Bot", "support-bot")
+	require.NoError(t, err, "directories with IDs that are no longer valid can be renamed")
+	assert.DirExists(t, filepath.Join(baseDir, "support-bot"))
+
+	_, err = RenameAssistant(baseDir, "support-bot", "Support: Bot")
+	assert.ErrorContains(t, err, "invalid assistant ID")
+	_, err = RenameAssistant(baseDir, "../support-bot", "other")
+	assert.ErrorContains(t, err, "invalid assistant directory name")
+}
+
+func TestRenameAssistant_InvalidPlan(t *testing.T) {
+	baseDir := t.TempDir()
+	good := writePlan(t, baseDir, "old", "old", "p1")
+	broken := filepath.Join(baseDir, "old", "Output", "p2", "plan.toml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(broken), 0755))
+	require.NoError(t, os.WriteFile(broken, []byte("plan_id = "), 0644))
+
+	_, err := RenameAssistant(baseDir, "old", "new")
+	require.Error(t, err)
+	assert.NoDirExists(t, filepath.Join(baseDir, "new"), "nothing is moved")
+	p, err := LoadFromPath(good)
+	require.NoError(t, err)
+	assert.Equal(t, "old", p.AssistantID, "nothing is rewritten")
+}
+
+func TestRollbackRename(t *testing.T) {
+	baseDir := t.TempDir()
+	path := writePlan(t, baseDir, "old", "old", "p1")
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	oldDir, newDir := filepath.Join(baseDir, "old"), filepath.Join(baseDir, "new")
+	require.NoError(t, os.Rename(oldDir, newDir))
+	rel := filepath.Join("Output", "p1", "plan.toml")
+	require.NoError(t, Save(filepath.Join(newDir, rel), &Plan{PlanID: "p1", AssistantID: "new"}))
+
+	require.NoError(t, rollbackRename(oldDir, newDir, []renamedPlan{{rel: rel, original: original}}))
+	assert.NoDirExists(t, newDir)
+	restored, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, restored)
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 )
@@ -21,12 +22,97 @@ type Metadata struct {
 	Input      int           `yaml:"-"`
 	Output     int           `yaml:"-"`
 	ExecutedAt time.Time     `yaml:"executed_at,omitempty"`
+	Chars      int           `yaml:"chars,omitempty"` // Response length in characters
+	Words      int           `yaml:"words,omitempty"` // Response length in words
+	// Cost of the request from configured token prices (0 if unpriced)
+	Cost float64 `yaml:"cost,omitempty"`
+	// Temperature is the sampling temperature of the request (nil if unknown)
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	// HTTPStatus is the status code of the provider's response (0 if unknown)
+	HTTPStatus int `yaml:"http_status,omitempty"`
+	// TTFB is the time to the first byte of the response body; the rest
+	// of Duration is spent generating and transferring the response
+	TTFB time.Duration `yaml:"ttfb,omitempty"`
+
+	// SystemPromptHash identifies the system prompt sent with the request
+	SystemPromptHash string `yaml:"system_prompt_hash,omitempty"`
+	// RequestHash identifies the full request (model, prompts, parameters)
+	RequestHash string `yaml:"request_hash,omitempty"`
+	// QueryWrapped is set when the plan's query prefix/suffix was applied
+	QueryWrapped bool `yaml:"query_wrapped,omitempty"`
+
+	// Moderation metadata (set by tuna exec when moderation is enabled)
+	Moderation           string   `yaml:"moderation,omitempty"` // ModerationPassed or ModerationFlagged
+	ModerationCategories []string `yaml:"moderation_categories,omitempty"`
 
 	// Rating metadata (set by tuna view)
 	Rating  string    `yaml:"rating,omitempty"`
 	RatedAt time.Time `yaml:"rated_at,omitempty"`
+	// Pinned marks the canonical answer; re-execution keeps it unless forced
+	Pinned bool `yaml:"pinned,omitempty"`
+
+	// History records prior executions of the response, oldest first
+	History []Run `yaml:"history,omitempty"`
 }
 
+// MaxHistory is the number of prior executions kept in Metadata.History.
+const MaxHistory = 10
+
+// Run describes a prior execution of a response.
+type Run struct {
+	ExecutedAt time.Time     `yaml:"executed_at"`
+	Model      string        `yaml:"model,omitempty"`
+	Input      int           `yaml:"input,omitempty"`  // Prompt tokens
+	Output     int           `yaml:"output,omitempty"` // Output tokens
+	Duration   time.Duration `yaml:"duration,omitempty"`
+}
+
+// Supersede carries the history of prev, the metadata of the response
+// being overwritten, over to m and appends the execution of prev to it.
+// Only the last MaxHistory executions are kept.
+func (m *Metadata) Supersede(prev *Metadata) {
+	if prev == nil {
+		return
+	}
+
+	history := append([]Run(nil), prev.History...)
+	if prev.HasExecutionMetadata() {
+		history = append(history, Run{
+			ExecutedAt: prev.ExecutedAt,
+			Model:      prev.Model,
+			Input:      prev.Input,
+			Output:     prev.Output,
+			Duration:   prev.Duration,
+		})
+	}
+	if len(history) > MaxHistory {
+		history = history[len(history)-MaxHistory:]
+	}
+	if len(history) > 0 {
+		m.History = history
+	}
+}
+
+// DefaultTimestampPrecision is the precision of recorded timestamps
+// when none is configured.
+const DefaultTimestampPrecision = time.Second
+
+// Now returns the current time truncated to the given precision,
+// or to DefaultTimestampPrecision if precision is not positive, so
+// that timestamps in front matter do not churn diffs.
+func Now(precision time.Duration) time.Time {
+	if precision <= 0 {
+		precision = DefaultTimestampPrecision
+	}
+	return time.Now().Truncate(precision)
+}
+
+// Moderation status values.
+const (
+	ModerationPassed  = "passed"
+	ModerationFlagged = "flagged"
+)
+
 // metadataYAML is used for custom YAML marshaling/unmarshaling.
 type metadataYAML struct {
 	Provider   string        `yaml:"provider,omitempty"`
@@ -35,8 +121,27 @@ type metadataYAML struct {
 	Input      string        `yaml:"input,omitempty"`
 	Output     string        `yaml:"output,omitempty"`
 	ExecutedAt time.Time     `yaml:"executed_at,omitempty"`
-	Rating     string        `yaml:"rating,omitempty"`
-	RatedAt    time.Time     `yaml:"rated_at,omitempty"`
+	Chars      int           `yaml:"chars,omitempty"`
+	Words      int           `yaml:"words,omitempty"`
+
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	Cost        float64  `yaml:"cost,omitempty"`
+
+	HTTPStatus int           `yaml:"http_status,omitempty"`
+	TTFB       time.Duration `yaml:"ttfb,omitempty"`
+
+	SystemPromptHash string `yaml:"system_prompt_hash,omitempty"`
+	RequestHash      string `yaml:"request_hash,omitempty"`
+	QueryWrapped     bool   `yaml:"query_wrapped,omitempty"`
+
+	Moderation           string   `yaml:"moderation,omitempty"`
+	ModerationCategories []string `yaml:"moderation_categories,omitempty"`
+
+	Rating  string    `yaml:"rating,omitempty"`
+	RatedAt time.Time `yaml:"rated_at,omitempty"`
+	Pinned  bool      `yaml:"pinned,omitempty"`
+
+	History []Run `yaml:"history,omitempty"`
 }
 
 // MarshalYAML implements custom YAML marshaling for human-readable format.
@@ -46,8 +151,27 @@ func (m Metadata) MarshalYAML() (any, error) {
 		Model:      m.Model,
 		Duration:   m.Duration,
 		ExecutedAt: m.ExecutedAt,
-		Rating:     m.Rating,
-		RatedAt:    m.RatedAt,
+		Chars:      m.Chars,
+		Words:      m.Words,
+
+		Temperature: m.Temperature,
+		Cost:        m.Cost,
+
+		HTTPStatus: m.HTTPStatus,
+		TTFB:       m.TTFB,
+
+		SystemPromptHash: m.SystemPromptHash,
+		RequestHash:      m.RequestHash,
+		QueryWrapped:     m.QueryWrapped,
+
+		Moderation:           m.Moderation,
+		ModerationCategories: m.ModerationCategories,
+
+		Rating:  m.Rating,
+		RatedAt: m.RatedAt,
+		Pinned:  m.Pinned,
+
+		History: m.History,
 	}
 
 	if m.Input > 0 {
@@ -71,8 +195,21 @@ func (m *Metadata) UnmarshalYAML(value *yaml.Node) error {
 	m.Model = aux.Model
 	m.Duration = aux.Duration
 	m.ExecutedAt = aux.ExecutedAt
+	m.Chars = aux.Chars
+	m.Words = aux.Words
+	m.Temperature = aux.Temperature
+	m.Cost = aux.Cost
+	m.HTTPStatus = aux.HTTPStatus
+	m.TTFB = aux.TTFB
+	m.SystemPromptHash = aux.SystemPromptHash
+	m.RequestHash = aux.RequestHash
+	m.QueryWrapped = aux.QueryWrapped
+	m.Moderation = aux.Moderation
+	m.ModerationCategories = aux.ModerationCategories
 	m.Rating = aux.Rating
 	m.RatedAt = aux.RatedAt
+	m.Pinned = aux.Pinned
+	m.History = aux.History
 
 	// Parse tokens: "1250t" -> int
 	m.Input = parseTokens(aux.Input)
@@ -91,6 +228,16 @@ func parseTokens(s string) int {
 // frontMatterRegex matches YAML front matter at the start of a file.
 var frontMatterRegex = regexp.MustCompile(`(?s)^---\n(.+?)\n---\n`)
 
+// SplitFrontMatter splits raw file content into YAML front matter and body.
+// Returns empty front matter if the content has none.
+func SplitFrontMatter(data string) (frontMatter, content string) {
+	matches := frontMatterRegex.FindStringSubmatch(data)
+	if len(matches) != 2 {
+		return "", data
+	}
+	return matches[1], strings.TrimLeft(data[len(matches[0]):], "\n")
+}
+
 // Parse reads a response file and returns metadata and content separately.
 func Parse(filePath string) (*Metadata, string, error) {
 	data, err := os.ReadFile(filePath)
@@ -101,14 +248,34 @@ func Parse(filePath string) (*Metadata, string, error) {
 }
 
 // ParseContent parses metadata and content from a string.
+// Invalid front matter yields empty metadata with content preserved.
 func ParseContent(data string) (*Metadata, string, error) {
+	meta, content, err := ParseContentStrict(data)
+	if err != nil {
+		// Invalid YAML - return empty metadata but preserve content
+		return &Metadata{}, data, nil
+	}
+	return meta, content, nil
+}
+
+// ParseStrict reads a response file like Parse, but fails on invalid front matter.
+func ParseStrict(filePath string) (*Metadata, string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	return ParseContentStrict(string(data))
+}
+
+// ParseContentStrict parses metadata and content from a string,
+// returning an error if the front matter is not valid YAML.
+func ParseContentStrict(data string) (*Metadata, string, error) {
 	meta := &Metadata{}
 	content := data
 
 	if matches := frontMatterRegex.FindStringSubmatch(content); len(matches) == 2 {
 		if err := yaml.Unmarshal([]byte(matches[1]), meta); err != nil {
-			// Invalid YAML - return empty metadata but preserve content
-			return &Metadata{}, content, nil
+			return nil, "", fmt.Errorf("invalid front matter: %w", err)
 		}
 		content = frontMatterRegex.ReplaceAllString(content, "")
 	}
@@ -130,6 +297,12 @@ func Format(meta *Metadata, content string) (string, error) {
 	return "---\n" + string(yamlData) + "---\n\n" + strings.TrimLeft(content, "\n"), nil
 }
 
+// CountText returns the number of characters (runes) and
+// whitespace-separated words in content.
+func CountText(content string) (chars, words int) {
+	return utf8.RuneCountInString(content), len(strings.Fields(content))
+}
+
 // IsEmpty returns true if metadata has no meaningful values.
 func (m *Metadata) IsEmpty() bool {
 	return m.Provider == "" &&
@@ -138,7 +311,10 @@ func (m *Metadata) IsEmpty() bool {
 		m.Input == 0 &&
 		m.Output == 0 &&
 		m.ExecutedAt.IsZero() &&
-		m.Rating == ""
+		m.Moderation == "" &&
+		m.Rating == "" &&
+		!m.Pinned &&
+		len(m.History) == 0
 }
 
 // HasExecutionMetadata returns true if execution metadata is present.
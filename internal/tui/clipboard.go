@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoClipboard is returned when no clipboard tool is available,
+// e.g. in a headless session.
+var ErrNoClipboard = errors.New("no clipboard available")
+
+// clipboardCommands lists the clipboard tools tried on each platform,
+// in order of preference.
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip.exe"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	},
+}
+
+// CopyToClipboard copies text to the system clipboard using the first
+// available platform tool. On Linux, wl-copy needs a Wayland session and
+// xclip or xsel an X11 display.
+//
+// The tool's output is discarded rather than captured: xclip and wl-copy
+// fork a child that keeps serving the clipboard and would hold an output
+// pipe open, so waiting for the output would block until the clipboard
+// changes. It blocks until the tool exits, so run it off the UI loop.
+func CopyToClipboard(text string) error {
+	for _, args := range clipboardCommands[runtime.GOOS] {
+		if !clipboardUsable(args[0]) {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		return nil
+	}
+	return ErrNoClipboard
+}
+
+// clipboardUsable reports whether a clipboard tool is installed and,
+// on Linux, whether the display server it talks to is available.
+func clipboardUsable(name string) bool {
+	if _, err := exec.LookPath(name); err != nil {
+		return false
+	}
+	switch name {
+	case "wl-copy":
+		return os.Getenv("WAYLAND_DISPLAY") != ""
+	case "xclip", "xsel":
+		return os.Getenv("DISPLAY") != ""
+	}
+	return true
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	tunaexec "go.octolab.org/toolset/tuna/internal/exec"
 	"go.octolab.org/toolset/tuna/internal/tui"
 )
 
@@ -22,18 +24,24 @@ const (
 	TaskRunning
 	TaskComplete
 	TaskFailed
+	TaskSkipped // Completed in an earlier run
 )
 
 // Task represents a single execution task (model + query combination).
 type Task struct {
 	Model    string
+	Provider string
 	QueryID  string
 	Status   TaskStatus
 	Error    error
 	Tokens   TokenUsage
 	Duration time.Duration
+	Output   string // End of the response received so far, while running
 }
 
+// outputTail is the number of characters of a running task's response kept for display.
+const outputTail = 512
+
 // TokenUsage holds token counts.
 type TokenUsage struct {
 	Prompt int
@@ -42,27 +50,29 @@ type TokenUsage struct {
 
 // Model is the bubbletea model for execution progress.
 type Model struct {
-	tasks       []Task
-	current     int
-	totalTokens TokenUsage
-	startTime   time.Time
-	spinner     spinner.Model
-	progress    progress.Model
-	done        bool
-	width       int
-	err         error
+	tasks      []Task
+	aggregator *tunaexec.ProgressAggregator // Source of counts and token totals
+	startTime  time.Time
+	spinner    spinner.Model
+	progress   progress.Model
+	done       bool
+	width      int
+	err        error
 }
 
 // New creates a new execution TUI model.
-func New(models []string, queries []string) Model {
+// Providers maps each model to its resolved provider; it may be nil.
+// The aggregator must receive the same progress events as the model.
+func New(models []string, queries []string, providers map[string]string, aggregator *tunaexec.ProgressAggregator) Model {
 	// Create tasks for all model/query combinations
 	var tasks []Task
 	for _, model := range models {
 		for _, query := range queries {
 			tasks = append(tasks, Task{
-				Model:   model,
-				QueryID: query,
-				Status:  TaskPending,
+				Model:    model,
+				Provider: providers[model],
+				QueryID:  query,
+				Status:   TaskPending,
 			})
 		}
 	}
@@ -77,11 +87,12 @@ func New(models []string, queries []string) Model {
 	)
 
 	return Model{
-		tasks:     tasks,
-		startTime: time.Now(),
-		spinner:   s,
-		progress:  p,
-		width:     80,
+		tasks:      tasks,
+		aggregator: aggregator,
+		startTime:  time.Now(),
+		spinner:    s,
+		progress:   p,
+		width:      80,
 	}
 }
 
@@ -113,6 +124,19 @@ type TaskErrorMsg struct {
 	Err     error
 }
 
+// TaskSkippedMsg signals that a task was completed in an earlier run.
+type TaskSkippedMsg struct {
+	Model   string
+	QueryID string
+}
+
+// TaskOutputMsg carries response content of a running task as it arrives.
+type TaskOutputMsg struct {
+	Model   string
+	QueryID string
+	Output  string
+}
+
 // ExecutionDoneMsg signals that all tasks are complete.
 type ExecutionDoneMsg struct {
 	Err error
@@ -142,7 +166,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		for i := range m.tasks {
 			if m.tasks[i].Model == msg.Model && m.tasks[i].QueryID == msg.QueryID {
 				m.tasks[i].Status = TaskRunning
-				m.current = i
+				m.tasks[i].Output = ""
+				break
+			}
+		}
+
+	case TaskOutputMsg:
+		for i := range m.tasks {
+			if m.tasks[i].Model == msg.Model && m.tasks[i].QueryID == msg.QueryID {
+				output := m.tasks[i].Output + msg.Output
+				if cut := len(output) - outputTail; cut > 0 {
+					for cut < len(output) && !utf8.RuneStart(output[cut]) {
+						cut++ // Keep whole characters
+					}
+					output = output[cut:]
+				}
+				m.tasks[i].Output = output
 				break
 			}
 		}
@@ -151,10 +190,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		for i := range m.tasks {
 			if m.tasks[i].Model == msg.Model && m.tasks[i].QueryID == msg.QueryID {
 				m.tasks[i].Status = TaskComplete
+				m.tasks[i].Output = ""
 				m.tasks[i].Tokens = msg.Tokens
 				m.tasks[i].Duration = msg.Duration
-				m.totalTokens.Prompt += msg.Tokens.Prompt
-				m.totalTokens.Output += msg.Tokens.Output
 				break
 			}
 		}
@@ -168,6 +206,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case TaskSkippedMsg:
+		for i := range m.tasks {
+			if m.tasks[i].Model == msg.Model && m.tasks[i].QueryID == msg.QueryID {
+				m.tasks[i].Status = TaskSkipped
+				break
+			}
+		}
+
 	case ExecutionDoneMsg:
 		m.done = true
 		m.err = msg.Err
@@ -184,21 +230,37 @@ func (m Model) View() string {
 	}
 
 	var sb strings.Builder
+	snapshot := m.aggregator.Snapshot()
 
 	// Title
 	sb.WriteString(tui.Title.Render("Executing plan"))
 	sb.WriteString("\n\n")
 
 	// Progress bar
-	completed := m.completedCount()
-	percent := float64(completed) / float64(len(m.tasks))
+	completed := snapshot.Finished()
+	percent := float64(completed) / float64(snapshot.Total)
 	sb.WriteString(m.progress.ViewAs(percent))
-	sb.WriteString(tui.Muted.Render(fmt.Sprintf(" %d/%d", completed, len(m.tasks))))
+	sb.WriteString(tui.Muted.Render(fmt.Sprintf(" %d/%d", completed, snapshot.Total)))
 	sb.WriteString("\n\n")
 
-	// Current task
-	if m.current < len(m.tasks) && m.tasks[m.current].Status == TaskRunning {
-		task := m.tasks[m.current]
+	// Per-provider progress
+	if stats := snapshot.Providers; len(stats) > 1 {
+		for _, stat := range stats {
+			line := fmt.Sprintf("  %-16s %s", stat.Provider, tui.ProgressStyle(stat.Completed+stat.Failed, stat.Total))
+			if stat.Failed > 0 {
+				line += fmt.Sprintf(" %s %s", tui.SymbolError, tui.Error.Render(fmt.Sprint(stat.Failed)))
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	// Running tasks, several with --parallel
+	for _, task := range m.tasks {
+		if task.Status != TaskRunning {
+			continue
+		}
 		sb.WriteString(m.spinner.View())
 		sb.WriteString(" ")
 		sb.WriteString(tui.Info.Render(task.Model))
@@ -207,6 +269,11 @@ func (m Model) View() string {
 		sb.WriteString(" ")
 		sb.WriteString(task.QueryID)
 		sb.WriteString("\n")
+		if line := lastLine(task.Output, m.width-4); line != "" {
+			sb.WriteString("    ")
+			sb.WriteString(tui.Muted.Render(line))
+			sb.WriteString("\n")
+		}
 	}
 
 	// Stats
@@ -215,7 +282,11 @@ func (m Model) View() string {
 	sb.WriteString(tui.Muted.Render(fmt.Sprintf("Elapsed: %s", elapsed)))
 	sb.WriteString("  ")
 	sb.WriteString(tui.Muted.Render(fmt.Sprintf("Tokens: %d prompt + %d output",
-		m.totalTokens.Prompt, m.totalTokens.Output)))
+		snapshot.Tokens.Prompt, snapshot.Tokens.Output)))
+	if snapshot.Cost > 0 {
+		sb.WriteString("  ")
+		sb.WriteString(tui.Muted.Render(fmt.Sprintf("Cost: %.4f", snapshot.Cost)))
+	}
 	sb.WriteString("\n")
 
 	// Recent completed tasks (show last 3)
@@ -257,9 +328,10 @@ func (m Model) View() string {
 
 func (m Model) viewDone() string {
 	var sb strings.Builder
+	snapshot := m.aggregator.Snapshot()
 
-	completed := m.completedCount()
-	failed := len(m.failedTasks())
+	completed := snapshot.Finished()
+	failed := snapshot.Failed
 	elapsed := time.Since(m.startTime).Round(time.Second)
 
 	if failed == 0 {
@@ -270,25 +342,48 @@ func (m Model) viewDone() string {
 	sb.WriteString("\n\n")
 
 	// Stats
-	sb.WriteString(tui.RenderKeyValue("Tasks", fmt.Sprintf("%d/%d completed", completed, len(m.tasks))))
+	tasks := fmt.Sprintf("%d/%d completed", completed, snapshot.Total)
+	if snapshot.Skipped > 0 {
+		tasks += fmt.Sprintf(" (%d in an earlier run)", snapshot.Skipped)
+	}
+	sb.WriteString(tui.RenderKeyValue("Tasks", tasks))
 	sb.WriteString("\n")
 	sb.WriteString(tui.RenderKeyValue("Tokens", fmt.Sprintf("%d prompt + %d output = %d total",
-		m.totalTokens.Prompt, m.totalTokens.Output, m.totalTokens.Prompt+m.totalTokens.Output)))
+		snapshot.Tokens.Prompt, snapshot.Tokens.Output, snapshot.Tokens.Prompt+snapshot.Tokens.Output)))
 	sb.WriteString("\n")
+	if snapshot.Cost > 0 {
+		sb.WriteString(tui.RenderKeyValue("Cost", fmt.Sprintf("%.4f", snapshot.Cost)))
+		sb.WriteString("\n")
+	}
 	sb.WriteString(tui.RenderKeyValue("Elapsed", elapsed.String()))
 	sb.WriteString("\n")
 
+	// Per-provider subtotals
+	if stats := snapshot.Providers; len(stats) > 0 && stats[0].Provider != "" {
+		sb.WriteString("\n")
+		sb.WriteString(tui.Bold.Render("Providers:"))
+		sb.WriteString("\n")
+		for _, stat := range stats {
+			sb.WriteString(fmt.Sprintf("  %s %s",
+				tui.Info.Render(stat.Provider),
+				tui.Muted.Render(fmt.Sprintf("%d/%d tasks, %d tokens, %d errors",
+					stat.Completed, stat.Total, stat.Tokens.Prompt+stat.Tokens.Output, stat.Failed))))
+			sb.WriteString("\n")
+		}
+	}
+
 	return sb.String()
 }
 
-func (m Model) completedCount() int {
-	count := 0
-	for _, task := range m.tasks {
-		if task.Status == TaskComplete || task.Status == TaskFailed {
-			count++
-		}
+// lastLine returns the last non-blank line of output, keeping at most
+// width characters from its end.
+func lastLine(output string, width int) string {
+	lines := strings.Split(strings.TrimRight(output, " \t\r\n"), "\n")
+	line := []rune(strings.TrimSpace(lines[len(lines)-1]))
+	if width > 0 && len(line) > width {
+		line = append([]rune("…"), line[len(line)-width+1:]...)
 	}
-	return count
+	return string(line)
 }
 
 func (m Model) recentCompleted(n int) []Task {
@@ -318,5 +413,6 @@ func (m Model) Tasks() []Task {
 
 // TotalTokens returns the total token usage.
 func (m Model) TotalTokens() TokenUsage {
-	return m.totalTokens
+	tokens := m.aggregator.Snapshot().Tokens
+	return TokenUsage{Prompt: tokens.Prompt, Output: tokens.Output}
 }
@@ -0,0 +1,41 @@
+package exec
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+
+	tunaexec "go.octolab.org/toolset/tuna/internal/exec"
+)
+
+func TestModel_Update_TaskOutput(t *testing.T) {
+	aggregator := tunaexec.NewProgressAggregator([]string{"m"}, 1, nil)
+	model := New([]string{"m"}, []string{"q1.md"}, nil, aggregator)
+
+	model = update(model, TaskStartMsg{Model: "m", QueryID: "q1.md"})
+	model = update(model, TaskOutputMsg{Model: "m", QueryID: "q1.md", Output: "First line\nSecond "})
+	model = update(model, TaskOutputMsg{Model: "m", QueryID: "q1.md", Output: "line"})
+	assert.Equal(t, "First line\nSecond line", model.Tasks()[0].Output)
+	assert.Contains(t, model.View(), "Second line")
+	assert.NotContains(t, model.View(), "First line", "only the last line is shown")
+
+	model = update(model, TaskOutputMsg{Model: "m", QueryID: "q1.md", Output: strings.Repeat("é", outputTail)})
+	assert.Len(t, []rune(model.Tasks()[0].Output), outputTail/2, "the tail keeps whole characters")
+
+	model = update(model, TaskDoneMsg{Model: "m", QueryID: "q1.md"})
+	assert.Empty(t, model.Tasks()[0].Output)
+}
+
+func TestLastLine(t *testing.T) {
+	assert.Equal(t, "two", lastLine("one\ntwo\n\n", 80))
+	assert.Equal(t, "…789", lastLine("123456789", 4))
+	assert.Empty(t, lastLine("", 80))
+}
+
+// update applies msg to model.
+func update(model Model, msg tea.Msg) Model {
+	updated, _ := model.Update(msg)
+	return updated.(Model)
+}
@@ -3,6 +3,8 @@ package tui
 import (
 	"fmt"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 // RenderCreated renders a list of created items with success styling.
@@ -125,3 +127,40 @@ func RenderError(message string) string {
 func RenderInfo(message string) string {
 	return fmt.Sprintf("%s %s", Info.Render("ℹ"), Info.Render(message))
 }
+
+// RenderTable renders rows as columns padded to the widest cell,
+// with a bold header row. Cells may contain styled text.
+func RenderTable(header []string, rows [][]string) string {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], lipgloss.Width(cell))
+			}
+		}
+	}
+
+	var sb strings.Builder
+	writeRow := func(row []string, style func(string) string) {
+		for i, cell := range row {
+			if i >= len(widths) {
+				break
+			}
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			sb.WriteString(style(cell))
+			if i < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[i]-lipgloss.Width(cell)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(header, func(s string) string { return Bold.Render(s) })
+	for _, row := range rows {
+		writeRow(row, func(s string) string { return s })
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,205 @@
+// Package pick provides the TUI model for selecting plan models.
+package pick
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"go.octolab.org/toolset/tuna/internal/tui"
+)
+
+var (
+	cursorStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(tui.ColorCyan)
+
+	checkedStyle = lipgloss.NewStyle().
+			Foreground(tui.ColorGreen)
+)
+
+// Item is a model that can be selected.
+type Item struct {
+	Model       string // Model name or alias written to the plan
+	Description string // Provider, or the model an alias resolves to
+}
+
+// FilterValue implements list.Item.
+func (i Item) FilterValue() string { return i.Model + " " + i.Description }
+
+// Model is the bubbletea model for the model picker.
+type Model struct {
+	list      list.Model
+	items     []Item
+	selected  map[string]bool // Shared with the list delegate
+	confirmed bool
+}
+
+// New creates a picker for items with the preselected models checked.
+func New(items []Item, preselected []string) Model {
+	selected := make(map[string]bool, len(preselected))
+	for _, model := range preselected {
+		selected[model] = true
+	}
+
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+
+	l := list.New(listItems, delegate{selected: selected}, 0, 0)
+	l.Title = "Select models"
+	l.SetShowStatusBar(false)
+	toggle := key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle"))
+	all := key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "toggle all"))
+	done := key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm"))
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{toggle, all, done}
+	}
+
+	return Model{list: l, items: items, selected: selected}
+}
+
+// Init implements tea.Model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.KeyMsg:
+		// Keys are typed into the filter while it is being edited
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+
+		case "esc":
+			if m.list.FilterState() == list.FilterApplied {
+				break // Clears the filter
+			}
+			return m, tea.Quit
+
+		case " ":
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				m.selected[item.Model] = !m.selected[item.Model]
+			}
+			return m, nil
+
+		case "a":
+			m.toggleAll()
+			return m, nil
+
+		case "enter":
+			m.confirmed = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model.
+func (m Model) View() string {
+	return m.list.View()
+}
+
+// Confirmed reports whether the selection was confirmed rather than cancelled.
+func (m Model) Confirmed() bool {
+	return m.confirmed
+}
+
+// Selected returns the checked models in item order.
+func (m Model) Selected() []string {
+	return SelectedModels(m.items, m.selected)
+}
+
+// toggleAll checks every item, or unchecks them all if all are checked.
+// A model listed several times, e.g. by two providers, counts once.
+func (m Model) toggleAll() {
+	unique := make(map[string]bool, len(m.items))
+	for _, item := range m.items {
+		unique[item.Model] = true
+	}
+	check := len(SelectedModels(m.items, m.selected)) < len(unique)
+	for _, item := range m.items {
+		m.selected[item.Model] = check
+	}
+}
+
+// Unknown returns the models that are not among items, without
+// duplicates; such preselected models cannot be picked.
+func Unknown(items []Item, models []string) []string {
+	known := make(map[string]bool, len(items))
+	for _, item := range items {
+		known[item.Model] = true
+	}
+	var unknown []string
+	for _, model := range models {
+		if !known[model] {
+			known[model] = true
+			unknown = append(unknown, model)
+		}
+	}
+	return unknown
+}
+
+// SelectedModels returns the models of items that are selected, in item
+// order and without duplicates.
+func SelectedModels(items []Item, selected map[string]bool) []string {
+	var models []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if selected[item.Model] && !seen[item.Model] {
+			seen[item.Model] = true
+			models = append(models, item.Model)
+		}
+	}
+	return models
+}
+
+// delegate renders items with a checkbox.
+type delegate struct {
+	selected map[string]bool
+}
+
+func (d delegate) Height() int                             { return 1 }
+func (d delegate) Spacing() int                            { return 0 }
+func (d delegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d delegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item, ok := listItem.(Item)
+	if !ok {
+		return
+	}
+
+	box := "[ ]"
+	if d.selected[item.Model] {
+		box = checkedStyle.Render("[x]")
+	}
+	name := item.Model
+	cursor := "  "
+	if index == m.Index() {
+		cursor = cursorStyle.Render("> ")
+		name = cursorStyle.Render(name)
+	}
+	line := fmt.Sprintf("%s%s %s", cursor, box, name)
+	if item.Description != "" {
+		line += " " + tui.Muted.Render(item.Description)
+	}
+	_, _ = fmt.Fprint(w, line)
+}
@@ -0,0 +1,46 @@
+package pick
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func testItems() []Item {
+	return []Item{
+		{Model: "gpt-4o", Description: "openai"},
+		{Model: "sonnet", Description: "anthropic"},
+		{Model: "gpt-4o", Description: "openrouter"}, // Listed by two providers
+	}
+}
+
+func TestModel_toggleAll(t *testing.T) {
+	m := New(testItems(), nil)
+
+	m.toggleAll()
+	assert.Equal(t, []string{"gpt-4o", "sonnet"}, m.Selected())
+
+	m.toggleAll()
+	assert.Empty(t, m.Selected(), "duplicates do not keep toggle all from unchecking")
+
+	m = New(testItems(), []string{"gpt-4o"})
+	m.toggleAll()
+	assert.Equal(t, []string{"gpt-4o", "sonnet"}, m.Selected(), "partial selection is completed")
+}
+
+func TestModel_Update_Toggle(t *testing.T) {
+	var model tea.Model = New(testItems(), []string{"sonnet"})
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	assert.Equal(t, []string{"gpt-4o", "sonnet"}, model.(Model).Selected())
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, model.(Model).Confirmed())
+}
+
+func TestUnknown(t *testing.T) {
+	assert.Empty(t, Unknown(testItems(), []string{"gpt-4o", "sonnet"}))
+	assert.Equal(t, []string{"claude-sonnet-4-20250514"},
+		Unknown(testItems(), []string{"claude-sonnet-4-20250514", "gpt-4o", "claude-sonnet-4-20250514"}))
+}
@@ -2,14 +2,18 @@
 package view
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 
+	"go.octolab.org/toolset/tuna/internal/exec"
 	"go.octolab.org/toolset/tuna/internal/tui"
 	"go.octolab.org/toolset/tuna/internal/view"
 )
@@ -35,6 +39,33 @@ var (
 			Foreground(tui.ColorRed)
 )
 
+// DefaultMarkdownStyle is the glamour style used when none is configured.
+// A builtin style avoids terminal background detection on startup.
+const DefaultMarkdownStyle = "dark"
+
+// Options holds viewer options.
+type Options struct {
+	MarkdownStyle    string // Glamour builtin style name or JSON style file path
+	TemperatureSweep bool   // Label columns with their sampling temperature
+
+	// TimestampPrecision truncates rated_at (0 = default precision).
+	TimestampPrecision time.Duration
+
+	// Notice is shown in the footer until the first key press.
+	Notice string
+
+	// Usage is the token usage of the plan across all runs, from its
+	// usage ledger (zero = not shown).
+	Usage exec.TokenUsage
+}
+
+// ValidateMarkdownStyle checks that style is a builtin glamour style name
+// or a readable glamour JSON style file.
+func ValidateMarkdownStyle(style string) error {
+	_, err := glamour.NewTermRenderer(glamour.WithStylePath(style))
+	return err
+}
+
 // Model is the bubbletea model for the response viewer.
 type Model struct {
 	planID        string
@@ -50,6 +81,11 @@ type Model struct {
 	showHelp      bool
 	inputExpanded bool // Whether input query section is expanded
 	mdRenderer    *glamour.TermRenderer
+	mdStyle       string
+	showTemp      bool // Label columns with their temperature
+	precision     time.Duration
+	status        string          // Footer message, cleared on the next key press
+	usage         exec.TokenUsage // Plan token usage across runs
 
 	// Cache for rendered markdown content (key: "queryIdx:respIdx:width")
 	renderCache     map[string]string
@@ -57,10 +93,15 @@ type Model struct {
 }
 
 // New creates a new view TUI model.
-func New(planID string, groups []view.ResponseGroup) Model {
-	// Create markdown renderer - use DarkStyle for faster init (no terminal detection)
+// An invalid markdown style falls back to DefaultMarkdownStyle.
+func New(planID string, groups []view.ResponseGroup, opts Options) Model {
+	style := opts.MarkdownStyle
+	if style == "" || ValidateMarkdownStyle(style) != nil {
+		style = DefaultMarkdownStyle
+	}
+
 	renderer, _ := glamour.NewTermRenderer(
-		glamour.WithStylePath("dark"),
+		glamour.WithStylePath(style),
 		glamour.WithWordWrap(0), // We'll handle wrapping ourselves
 	)
 
@@ -69,7 +110,12 @@ func New(planID string, groups []view.ResponseGroup) Model {
 		groups:      groups,
 		columnWidth: 40, // Default, recalculated on resize
 		mdRenderer:  renderer,
+		mdStyle:     style,
+		showTemp:    opts.TemperatureSweep,
+		precision:   opts.TimestampPrecision,
 		renderCache: make(map[string]string),
+		status:      opts.Notice,
+		usage:       opts.Usage,
 	}
 }
 
@@ -87,6 +133,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showHelp = false
 			return m, nil
 		}
+		m.status = ""
 
 		switch msg.String() {
 		case "q", "esc":
@@ -97,6 +144,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.queryIndex--
 				m.focusIndex = 0
 				m.scrollOffset = 0
+				m.calculateLayout() // Column count may differ, e.g. with --only-failed
 				m.updateViewports()
 			}
 
@@ -105,6 +153,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.queryIndex++
 				m.focusIndex = 0
 				m.scrollOffset = 0
+				m.calculateLayout() // Column count may differ, e.g. with --only-failed
 				m.updateViewports()
 			}
 
@@ -151,6 +200,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "u":
 			m.setRating(view.RatingNone)
 
+		case "p":
+			m.togglePinned()
+
+		case "c":
+			return m, m.copyFocused()
+
 		case "?":
 			m.showHelp = !m.showHelp
 
@@ -169,6 +224,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case copiedMsg:
+		if msg.err != nil {
+			m.status = tui.Error.Render(fmt.Sprintf("Copy failed: %v", msg.err))
+		} else {
+			m.status = tui.Success.Render("Copied response to clipboard")
+		}
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -318,7 +380,7 @@ func (m *Model) updateViewports() {
 
 		// Recreate renderer with proper word wrap width
 		m.mdRenderer, _ = glamour.NewTermRenderer(
-			glamour.WithStylePath("dark"),
+			glamour.WithStylePath(m.mdStyle),
 			glamour.WithWordWrap(contentWidth),
 		)
 	}
@@ -333,7 +395,13 @@ func (m *Model) updateViewports() {
 		if !cached {
 			// Render markdown content
 			if m.mdRenderer != nil && resp.Content != "" {
-				rendered, err := m.mdRenderer.Render(resp.Content)
+				// Close fences left open by partially written responses;
+				// JSON mode responses are rendered as highlighted code
+				source := closeOpenFences(resp.Content)
+				if block, ok := jsonBlock(resp.Content); ok {
+					source = block
+				}
+				rendered, err := m.mdRenderer.Render(source)
 				if err == nil {
 					content = strings.TrimSpace(rendered)
 				} else {
@@ -384,7 +452,54 @@ func (m *Model) setRating(rating view.Rating) {
 	resp := &m.groups[m.queryIndex].Responses[m.focusIndex]
 	resp.Rating = rating
 	// Save rating to YAML front matter in the response file
-	view.SaveRating(resp.FilePath, rating)
+	view.SaveRating(resp.FilePath, rating, m.precision)
+}
+
+func (m *Model) togglePinned() {
+	if len(m.groups) == 0 || m.queryIndex >= len(m.groups) {
+		return
+	}
+	responses := m.groups[m.queryIndex].Responses
+	if m.focusIndex >= len(responses) {
+		return
+	}
+
+	resp := &m.groups[m.queryIndex].Responses[m.focusIndex]
+	resp.Pinned = !resp.Pinned
+	// Save pinned flag to YAML front matter in the response file
+	view.SavePinned(resp.FilePath, resp.Pinned)
+}
+
+// focusedContent returns the markdown of the focused response, without
+// front matter, or false if there is none to copy.
+func (m Model) focusedContent() (string, bool) {
+	if len(m.groups) == 0 || m.queryIndex >= len(m.groups) {
+		return "", false
+	}
+	responses := m.groups[m.queryIndex].Responses
+	if m.focusIndex >= len(responses) || responses[m.focusIndex].Failed() {
+		return "", false
+	}
+	return responses[m.focusIndex].Content, true
+}
+
+// copiedMsg reports the result of copying a response to the clipboard.
+type copiedMsg struct {
+	err error
+}
+
+// copyFocused returns a command copying the focused response to the
+// clipboard in the background; the result arrives as copiedMsg.
+func (m *Model) copyFocused() tea.Cmd {
+	content, ok := m.focusedContent()
+	if !ok {
+		m.status = tui.Warning.Render("Nothing to copy")
+		return nil
+	}
+	m.status = tui.Muted.Render("Copying...")
+	return func() tea.Msg {
+		return copiedMsg{err: tui.CopyToClipboard(content)}
+	}
 }
 
 // View renders the model.
@@ -442,6 +557,9 @@ func (m Model) viewHeader() string {
 	}
 
 	parts := []string{planPart, queryPart, modelsPart}
+	if m.usage != (exec.TokenUsage{}) {
+		parts = append(parts, fmt.Sprintf("Tokens: %d in / %d out", m.usage.Prompt, m.usage.Output))
+	}
 	if scrollPart != "" {
 		parts = append(parts, scrollPart)
 	}
@@ -590,8 +708,8 @@ func (m Model) viewColumns() string {
 }
 
 func (m Model) renderColumn(resp view.ModelResponse, idx, total int, focused bool) string {
-	// Header: model name + rating + position
-	modelName := truncate(resp.Model, m.columnWidth-20)
+	// Header: model name (and temperature) + rating + position
+	modelName := truncate(resp.Label(m.showTemp), m.columnWidth-20)
 
 	ratingStr := ""
 	switch resp.Rating {
@@ -600,10 +718,18 @@ func (m Model) renderColumn(resp view.ModelResponse, idx, total int, focused boo
 	case view.RatingBad:
 		ratingStr = badRatingStyle.Render(" [Bad]")
 	}
+	if resp.Pinned {
+		ratingStr += tui.Info.Render(" [Pinned]")
+	}
 
 	posStr := tui.Muted.Render(fmt.Sprintf(" [%d/%d]", idx+1, total))
 
-	header := fmt.Sprintf("%s%s%s", modelName, ratingStr, posStr)
+	metaStr := ""
+	if resp.Words > 0 {
+		metaStr = tui.Muted.Render(fmt.Sprintf(" %dw/%dc", resp.Words, resp.Chars))
+	}
+
+	header := fmt.Sprintf("%s%s%s%s", modelName, ratingStr, posStr, metaStr)
 
 	// Content from viewport
 	content := ""
@@ -644,7 +770,10 @@ func (m Model) renderColumn(resp view.ModelResponse, idx, total int, focused boo
 }
 
 func (m Model) viewFooter() string {
-	return tui.Muted.Render("h/l: focus  j/k: query  ↑↓/scroll: content  Tab: input  g/b: rate  q: quit  ?: help")
+	if m.status != "" {
+		return m.status
+	}
+	return tui.Muted.Render("h/l: focus  j/k: query  ↑↓/scroll: content  Tab: input  g/b: rate  c: copy  q: quit  ?: help")
 }
 
 func (m Model) viewHelp() string {
@@ -675,6 +804,10 @@ Rating (applies to focused column):
   g            Mark as good
   b            Mark as bad
   u            Clear rating
+  p            Pin/unpin as canonical answer (kept by tuna exec)
+
+Clipboard:
+  c            Copy focused response markdown to clipboard
 
 Other:
   ?            Toggle this help
@@ -735,3 +868,60 @@ func wrapText(text string, width int) string {
 
 	return result.String()
 }
+
+// jsonBlock returns content as an indented, fenced json code block
+// if the whole content is a JSON object or array.
+func jsonBlock(content string) (string, bool) {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return "", false
+	}
+	return "```json\n" + buf.String() + "\n```\n", true
+}
+
+// closeOpenFences appends a closing fence if the content ends inside
+// an unterminated code block, e.g. while a response is still being written.
+// Without it glamour renders everything after the opening fence as code.
+func closeOpenFences(content string) string {
+	var open string // Opening fence marker, empty if not inside a code block
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if open == "" {
+			if marker := fenceMarker(trimmed); marker != "" {
+				open = marker
+			}
+			continue
+		}
+		// Closing fence: same character, at least as long, no info string
+		if strings.HasPrefix(trimmed, open) && strings.Trim(trimmed, open[:1]) == "" {
+			open = ""
+		}
+	}
+
+	if open == "" {
+		return content
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + open + "\n"
+}
+
+// fenceMarker returns the fence run (``` or ~~~, 3+ chars) that opens
+// a code block on the given line, or empty string if there is none.
+func fenceMarker(line string) string {
+	for _, ch := range []string{"`", "~"} {
+		if !strings.HasPrefix(line, ch+ch+ch) {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, ch))
+		return line[:n]
+	}
+	return ""
+}
@@ -0,0 +1,217 @@
+package view
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/view"
+)
+
+func testGroups() []view.ResponseGroup {
+	return []view.ResponseGroup{
+		{
+			QueryID: "first.md",
+			Responses: []view.ModelResponse{
+				{Model: "alpha", Content: "# Alpha\n\nanswer"},
+				{Model: "beta", Content: "  \n"},
+				{Model: "gamma", Content: "flagged", Flagged: true},
+			},
+		},
+		{
+			QueryID:   "second.md",
+			Responses: []view.ModelResponse{{Model: "alpha", Content: "second answer"}},
+		},
+	}
+}
+
+func TestModel_focusedContent(t *testing.T) {
+	tests := map[string]struct {
+		query, focus int
+		content      string
+		ok           bool
+	}{
+		"response":            {query: 0, focus: 0, content: "# Alpha\n\nanswer", ok: true},
+		"empty response":      {query: 0, focus: 1},
+		"flagged response":    {query: 0, focus: 2},
+		"column out of range": {query: 0, focus: 3},
+		"other query":         {query: 1, focus: 0, content: "second answer", ok: true},
+		"query out of range":  {query: 2, focus: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := New("plan", testGroups(), Options{})
+			m.queryIndex, m.focusIndex = test.query, test.focus
+
+			content, ok := m.focusedContent()
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.content, content)
+		})
+	}
+
+	t.Run("no groups", func(t *testing.T) {
+		_, ok := New("plan", nil, Options{}).focusedContent()
+		assert.False(t, ok)
+	})
+}
+
+func TestModel_copyFocused(t *testing.T) {
+	m := New("plan", testGroups(), Options{})
+	m.focusIndex = 1
+	assert.Nil(t, m.copyFocused(), "nothing to copy must not start a command")
+	assert.Contains(t, m.status, "Nothing to copy")
+
+	updated, _ := m.Update(copiedMsg{err: errors.New("boom")})
+	require.IsType(t, Model{}, updated)
+	assert.Contains(t, updated.(Model).status, "Copy failed: boom")
+
+	updated, _ = m.Update(copiedMsg{})
+	assert.Contains(t, updated.(Model).status, "Copied response")
+}
+
+func TestModel_viewHeader_Usage(t *testing.T) {
+	m := New("plan", testGroups(), Options{})
+	assert.NotContains(t, m.viewHeader(), "Tokens")
+
+	m = New("plan", testGroups(), Options{Usage: exec.TokenUsage{Prompt: 1200, Output: 300}})
+	assert.Contains(t, m.viewHeader(), "Tokens: 1200 in / 300 out")
+}
+
+func TestCloseOpenFences(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"no fences":       {content: "plain", want: "plain"},
+		"closed":          {content: "```go\nx := 1\n```\nafter", want: "```go\nx := 1\n```\nafter"},
+		"open":            {content: "intro\n```go\nx := 1", want: "intro\n```go\nx := 1\n```\n"},
+		"open with eol":   {content: "```\nx\n", want: "```\nx\n```\n"},
+		"longer marker":   {content: "````\n```\nnested", want: "````\n```\nnested\n````\n"},
+		"tilde":           {content: "~~~\ncode", want: "~~~\ncode\n~~~\n"},
+		"info not closer": {content: "```\n```go", want: "```\n```go\n```\n"},
+		"indented":        {content: "  ```\ncode", want: "  ```\ncode\n```\n"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, closeOpenFences(test.content))
+		})
+	}
+}
+
+// escapeSequence matches terminal color codes.
+var escapeSequence = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// plainText strips color codes from rendered output.
+func plainText(rendered string) string {
+	return escapeSequence.ReplaceAllString(rendered, "")
+}
+
+func TestModel_updateViewports_OpenFence(t *testing.T) {
+	groups := []view.ResponseGroup{{
+		QueryID: "q.md",
+		Responses: []view.ModelResponse{
+			{Model: "alpha", Content: "Intro\n\n```go\nfunc main() {"},
+			{Model: "beta", Content: "## Beta\n\nplain answer"},
+		},
+	}}
+
+	updated, _ := New("plan", groups, Options{}).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updated.(Model)
+	require.Len(t, m.viewports, 2)
+	alpha, beta := plainText(m.viewports[0].View()), plainText(m.viewports[1].View())
+	assert.Contains(t, alpha, "func main()")
+	assert.NotContains(t, alpha, "```", "the fence is rendered, not shown")
+	assert.Contains(t, beta, "plain answer", "the next column is rendered on its own")
+	assert.NotContains(t, beta, "func main()")
+}
+
+func TestModel_renderColumn_TemperatureSweep(t *testing.T) {
+	low, high := 0.2, 0.8
+	groups := []view.ResponseGroup{{
+		QueryID: "q.md",
+		Responses: []view.ModelResponse{
+			{Model: "gpt-4o", Content: "cautious", Temperature: &low},
+			{Model: "gpt-4o", Content: "creative", Temperature: &high},
+			{Model: "imported", Content: "unknown"},
+		},
+	}}
+
+	tests := map[string]struct {
+		sweep bool
+		want  []string
+	}{
+		"model names":  {want: []string{"gpt-4o", "gpt-4o", "imported"}},
+		"temperatures": {sweep: true, want: []string{"gpt-4o @ T=0.2", "gpt-4o @ T=0.8", "imported"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			updated, _ := New("plan", groups, Options{TemperatureSweep: tc.sweep}).Update(tea.WindowSizeMsg{Width: 150, Height: 40})
+			m := updated.(Model)
+
+			for i, resp := range groups[0].Responses {
+				column := plainText(m.renderColumn(resp, i, len(groups[0].Responses), i == 0))
+				lines := strings.Split(column, "\n")
+				require.Greater(t, len(lines), 1)
+				header := lines[1] // Below the border
+				assert.Contains(t, header, tc.want[i])
+				if !tc.sweep {
+					assert.NotContains(t, header, "@ T=")
+				}
+			}
+		})
+	}
+}
+
+func TestJSONBlock(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    string
+		ok      bool
+	}{
+		"object": {
+			content: `{"name":"tuna","tags":["a","b"]}`,
+			want:    "```json\n{\n  \"name\": \"tuna\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n```\n",
+			ok:      true,
+		},
+		"array with whitespace": {
+			content: "\n  [1, 2]\n",
+			want:    "```json\n[\n  1,\n  2\n]\n```\n",
+			ok:      true,
+		},
+		"prose":        {content: "The answer is {\"a\": 1}"},
+		"invalid json": {content: `{"name": tuna}`},
+		"scalar":       {content: `"just a string"`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := jsonBlock(tc.content)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestModel_updateViewports_JSON(t *testing.T) {
+	groups := []view.ResponseGroup{{
+		QueryID:   "q.md",
+		Responses: []view.ModelResponse{{Model: "alpha", Content: `{"answer":42,"sources":["a"]}`}},
+	}}
+
+	updated, _ := New("plan", groups, Options{}).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := updated.(Model)
+	require.Len(t, m.viewports, 1)
+	rendered := plainText(m.viewports[0].View())
+	assert.Regexp(t, `(?m)^\s*"answer": 42,\s*$`, rendered, "one key per line, indented")
+	assert.Regexp(t, `(?m)^\s*"sources": \[\s*$`, rendered)
+	assert.NotContains(t, rendered, "```", "rendered as a code block")
+}
@@ -0,0 +1,57 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DatasetPair is a preference pair for fine-tuning, e.g. with DPO.
+type DatasetPair struct {
+	System   string `json:"system"`
+	User     string `json:"user"`
+	Chosen   string `json:"chosen"`
+	Rejected string `json:"rejected"`
+}
+
+// DatasetPairs pairs every good-rated response of a query with every
+// bad-rated one. Queries lacking either rating produce no pairs.
+func DatasetPairs(systemPrompt string, groups []ResponseGroup) []DatasetPair {
+	var pairs []DatasetPair
+	for _, group := range groups {
+		var good, bad []string
+		for _, resp := range group.Responses {
+			switch resp.Rating {
+			case RatingGood:
+				good = append(good, resp.Content)
+			case RatingBad:
+				bad = append(bad, resp.Content)
+			}
+		}
+
+		for _, chosen := range good {
+			for _, rejected := range bad {
+				pairs = append(pairs, DatasetPair{
+					System:   systemPrompt,
+					User:     group.InputText,
+					Chosen:   chosen,
+					Rejected: rejected,
+				})
+			}
+		}
+	}
+	return pairs
+}
+
+// ExportDataset writes the preference pairs as JSON Lines and returns
+// the number of pairs written.
+func ExportDataset(w io.Writer, systemPrompt string, groups []ResponseGroup) (int, error) {
+	pairs := DatasetPairs(systemPrompt, groups)
+	encoder := json.NewEncoder(w)
+	for _, pair := range pairs {
+		if err := encoder.Encode(pair); err != nil {
+			return 0, fmt.Errorf("failed to write dataset: %w", err)
+		}
+	}
+	return len(pairs), nil
+}
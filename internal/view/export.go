@@ -0,0 +1,180 @@
+package view
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportedPlan is the JSON document written by ExportJSON.
+type ExportedPlan struct {
+	PlanID  string          `json:"plan_id"`
+	Queries []ExportedGroup `json:"queries"`
+}
+
+// ExportedGroup mirrors ResponseGroup for JSON export.
+type ExportedGroup struct {
+	QueryID   string             `json:"query_id"`
+	Input     string             `json:"input"`
+	Responses []ExportedResponse `json:"responses"`
+}
+
+// ExportedResponse mirrors ModelResponse for JSON export.
+type ExportedResponse struct {
+	Model    string            `json:"model"`
+	Content  string            `json:"content"`
+	Rating   Rating            `json:"rating,omitempty"`
+	RatedAt  *time.Time        `json:"rated_at,omitempty"`
+	Pinned   bool              `json:"pinned,omitempty"`
+	Metadata *ExportedMetadata `json:"metadata,omitempty"` // nil if the response is missing
+}
+
+// ExportedMetadata holds the execution metadata of an exported response.
+type ExportedMetadata struct {
+	Provider     string    `json:"provider,omitempty"`
+	DurationMS   int64     `json:"duration_ms,omitempty"`
+	InputTokens  int       `json:"input_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	Chars        int       `json:"chars,omitempty"`
+	Words        int       `json:"words,omitempty"`
+	Cost         float64   `json:"cost,omitempty"`
+	Temperature  *float64  `json:"temperature,omitempty"`
+	ExecutedAt   time.Time `json:"executed_at"`
+	Flagged      bool      `json:"flagged,omitempty"`
+}
+
+// Export converts response groups to their JSON export representation.
+func Export(planID string, groups []ResponseGroup) *ExportedPlan {
+	exported := &ExportedPlan{PlanID: planID, Queries: make([]ExportedGroup, 0, len(groups))}
+	for _, group := range groups {
+		eg := ExportedGroup{
+			QueryID:   group.QueryID,
+			Input:     group.InputText,
+			Responses: make([]ExportedResponse, 0, len(group.Responses)),
+		}
+		for _, resp := range group.Responses {
+			er := ExportedResponse{
+				Model:   resp.Model,
+				Content: resp.Content,
+				Rating:  resp.Rating,
+				Pinned:  resp.Pinned,
+			}
+			if !resp.RatedAt.IsZero() {
+				ratedAt := resp.RatedAt
+				er.RatedAt = &ratedAt
+			}
+			if !resp.ExecutedAt.IsZero() {
+				er.Metadata = &ExportedMetadata{
+					Provider:     resp.Provider,
+					DurationMS:   resp.Duration.Milliseconds(),
+					InputTokens:  resp.Input,
+					OutputTokens: resp.Output,
+					Chars:        resp.Chars,
+					Words:        resp.Words,
+					Cost:         resp.Cost,
+					Temperature:  resp.Temperature,
+					ExecutedAt:   resp.ExecutedAt,
+					Flagged:      resp.Flagged,
+				}
+			}
+			eg.Responses = append(eg.Responses, er)
+		}
+		exported.Queries = append(exported.Queries, eg)
+	}
+	return exported
+}
+
+// ExportJSON writes the responses as a single indented JSON document.
+func ExportJSON(w io.Writer, planID string, groups []ResponseGroup) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(Export(planID, groups)); err != nil {
+		return fmt.Errorf("failed to write JSON export: %w", err)
+	}
+	return nil
+}
+
+// csvHeader lists the columns written by ExportCSV.
+var csvHeader = []string{"query_id", "model", "rating", "input_tokens", "output_tokens", "duration"}
+
+// ExportCSV writes one row per query and model with the rating and
+// execution metadata. Duration is in seconds; missing responses have
+// empty metadata columns.
+func ExportCSV(w io.Writer, groups []ResponseGroup) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV export: %w", err)
+	}
+	for _, group := range groups {
+		for _, resp := range group.Responses {
+			row := []string{group.QueryID, resp.Model, string(resp.Rating), "", "", ""}
+			if !resp.ExecutedAt.IsZero() {
+				row[3] = strconv.Itoa(resp.Input)
+				row[4] = strconv.Itoa(resp.Output)
+				row[5] = strconv.FormatFloat(resp.Duration.Seconds(), 'f', 3, 64)
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV export: %w", err)
+			}
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV export: %w", err)
+	}
+	return nil
+}
+
+// ExportMarkdown writes the responses as a Markdown report,
+// with a section per query and a subsection per model.
+func ExportMarkdown(w io.Writer, planID string, groups []ResponseGroup) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# Plan %s\n", planID)
+	for _, group := range groups {
+		fmt.Fprintf(&sb, "\n## %s\n\n", group.QueryID)
+		sb.WriteString(quote(group.InputText))
+
+		for _, resp := range group.Responses {
+			fmt.Fprintf(&sb, "\n### %s", resp.Model)
+			switch resp.Rating {
+			case RatingGood:
+				sb.WriteString(" (good)")
+			case RatingBad:
+				sb.WriteString(" (bad)")
+			}
+			if resp.Pinned {
+				sb.WriteString(" (pinned)")
+			}
+			sb.WriteString("\n\n")
+
+			switch {
+			case resp.Flagged:
+				sb.WriteString("_Flagged by moderation, not sent._\n")
+			case strings.TrimSpace(resp.Content) == "":
+				sb.WriteString("_No response._\n")
+			default:
+				sb.WriteString(strings.TrimRight(resp.Content, "\n"))
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write Markdown export: %w", err)
+	}
+	return nil
+}
+
+// quote formats text as a Markdown blockquote.
+func quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
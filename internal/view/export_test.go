@@ -0,0 +1,134 @@
+package view
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGroups returns a query answered by one model and missing from another.
+func testGroups() []ResponseGroup {
+	executedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	ratedAt := executedAt.Add(time.Hour)
+	return []ResponseGroup{{
+		QueryID:   "q1.md",
+		InputText: "What is Go?",
+		Responses: []ModelResponse{
+			{
+				Model:      "gpt-4o",
+				Content:    "A language.",
+				Provider:   "openai",
+				Duration:   1500 * time.Millisecond,
+				Input:      10,
+				Output:     3,
+				ExecutedAt: executedAt,
+				Rating:     RatingGood,
+				RatedAt:    ratedAt,
+			},
+			{Model: "o1"},
+		},
+	}}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportCSV(&buf, testGroups()))
+	assert.Equal(t, "query_id,model,rating,input_tokens,output_tokens,duration\n"+
+		"q1.md,gpt-4o,good,10,3,1.500\n"+
+		"q1.md,o1,,,,\n", buf.String())
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportJSON(&buf, "plan", testGroups()))
+
+	var exported ExportedPlan
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	assert.Equal(t, "plan", exported.PlanID)
+	require.Len(t, exported.Queries, 1)
+	group := exported.Queries[0]
+	assert.Equal(t, "q1.md", group.QueryID)
+	assert.Equal(t, "What is Go?", group.Input)
+	require.Len(t, group.Responses, 2)
+
+	answered := group.Responses[0]
+	assert.Equal(t, "gpt-4o", answered.Model)
+	assert.Equal(t, RatingGood, answered.Rating)
+	require.NotNil(t, answered.RatedAt)
+	require.NotNil(t, answered.Metadata)
+	assert.Equal(t, "openai", answered.Metadata.Provider)
+	assert.Equal(t, int64(1500), answered.Metadata.DurationMS)
+	assert.Equal(t, 10, answered.Metadata.InputTokens)
+	assert.Equal(t, 3, answered.Metadata.OutputTokens)
+
+	missing := group.Responses[1]
+	assert.Equal(t, "o1", missing.Model)
+	assert.Nil(t, missing.Metadata)
+	assert.Nil(t, missing.RatedAt)
+}
+
+func TestExportDataset(t *testing.T) {
+	tests := map[string]struct {
+		responses []ModelResponse
+		expected  []DatasetPair
+	}{
+		"good and bad": {
+			responses: []ModelResponse{
+				{Model: "gpt-4o", Content: "Right.", Rating: RatingGood},
+				{Model: "o1", Content: "Wrong.", Rating: RatingBad},
+				{Model: "sonnet", Content: "Unrated."},
+			},
+			expected: []DatasetPair{{System: "Be brief.", User: "What is Go?", Chosen: "Right.", Rejected: "Wrong."}},
+		},
+		"every good paired with every bad": {
+			responses: []ModelResponse{
+				{Model: "gpt-4o", Content: "Right.", Rating: RatingGood},
+				{Model: "o1", Content: "Wrong.", Rating: RatingBad},
+				{Model: "sonnet", Content: "Also right.", Rating: RatingGood},
+			},
+			expected: []DatasetPair{
+				{System: "Be brief.", User: "What is Go?", Chosen: "Right.", Rejected: "Wrong."},
+				{System: "Be brief.", User: "What is Go?", Chosen: "Also right.", Rejected: "Wrong."},
+			},
+		},
+		"only good": {
+			responses: []ModelResponse{
+				{Model: "gpt-4o", Content: "Right.", Rating: RatingGood},
+				{Model: "o1", Content: "Unrated."},
+			},
+		},
+		"only bad": {
+			responses: []ModelResponse{{Model: "o1", Content: "Wrong.", Rating: RatingBad}},
+		},
+		"unrated": {
+			responses: []ModelResponse{{Model: "o1", Content: "Unrated."}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			groups := []ResponseGroup{{QueryID: "q1.md", InputText: "What is Go?", Responses: tc.responses}}
+
+			var buf bytes.Buffer
+			n, err := ExportDataset(&buf, "Be brief.", groups)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expected), n)
+
+			var pairs []DatasetPair
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+				if line == "" {
+					continue
+				}
+				var pair DatasetPair
+				require.NoError(t, json.Unmarshal([]byte(line), &pair), "one pair per line")
+				pairs = append(pairs, pair)
+			}
+			assert.Equal(t, tc.expected, pairs)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package view
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Filter selects responses for viewing. Zero-valued fields match
+// everything; set fields are combined, so a response must match all.
+type Filter struct {
+	Failed  bool      // Only missing, empty, or flagged responses
+	Ratings []Rating  // Only responses with one of these ratings (RatingNone = unrated)
+	Models  []string  // Only responses of these models
+	Since   time.Time // Only responses executed at or after this time
+	Until   time.Time // Only responses executed before this time
+}
+
+// IsZero reports whether the filter matches every response.
+func (f Filter) IsZero() bool {
+	return !f.Failed && len(f.Ratings) == 0 && len(f.Models) == 0 &&
+		f.Since.IsZero() && f.Until.IsZero()
+}
+
+// Match reports whether the response passes the filter.
+func (f Filter) Match(r ModelResponse) bool {
+	if f.Failed && !r.Failed() {
+		return false
+	}
+	if len(f.Ratings) > 0 && !slices.Contains(f.Ratings, r.Rating) {
+		return false
+	}
+	if len(f.Models) > 0 && !slices.Contains(f.Models, r.Model) {
+		return false
+	}
+	if !f.Since.IsZero() && r.ExecutedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !r.ExecutedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// FilterGroups returns the groups that have responses matching the
+// filter, keeping only the matching responses in each.
+func FilterGroups(groups []ResponseGroup, f Filter) []ResponseGroup {
+	if f.IsZero() {
+		return groups
+	}
+
+	var filtered []ResponseGroup
+	for _, group := range groups {
+		var matched []ModelResponse
+		for _, resp := range group.Responses {
+			if f.Match(resp) {
+				matched = append(matched, resp)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		group.Responses = matched
+		filtered = append(filtered, group)
+	}
+	return filtered
+}
+
+// ParseRating parses a rating filter value: "good", "bad" or "unrated".
+func ParseRating(s string) (Rating, error) {
+	switch s {
+	case string(RatingGood):
+		return RatingGood, nil
+	case string(RatingBad):
+		return RatingBad, nil
+	case "unrated":
+		return RatingNone, nil
+	}
+	return "", fmt.Errorf("unknown rating %q: expected good, bad or unrated", s)
+}
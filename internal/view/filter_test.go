@@ -0,0 +1,102 @@
+package view
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterGroups(t *testing.T) {
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	groups := []ResponseGroup{
+		{
+			QueryID: "q1.md",
+			Responses: []ModelResponse{
+				{Model: "gpt-4o", Content: "A", Rating: RatingGood, ExecutedAt: day},
+				{Model: "o1", Content: "B", Rating: RatingBad, ExecutedAt: day.Add(2 * time.Hour)},
+				{Model: "sonnet", Content: "C", ExecutedAt: day.Add(4 * time.Hour)},
+			},
+		},
+		{
+			QueryID: "q2.md",
+			Responses: []ModelResponse{
+				{Model: "gpt-4o", Content: "D", Rating: RatingBad, ExecutedAt: day.Add(4 * time.Hour)},
+				{Model: "o1", Flagged: true, ExecutedAt: day.Add(4 * time.Hour)},
+				{Model: "sonnet"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		filter   Filter
+		expected map[string][]string // Query ID to the models kept
+	}{
+		"zero filter": {
+			expected: map[string][]string{"q1.md": {"gpt-4o", "o1", "sonnet"}, "q2.md": {"gpt-4o", "o1", "sonnet"}},
+		},
+		"failed": {
+			filter:   Filter{Failed: true},
+			expected: map[string][]string{"q2.md": {"o1", "sonnet"}},
+		},
+		"rating and model": {
+			filter:   Filter{Ratings: []Rating{RatingBad}, Models: []string{"gpt-4o"}},
+			expected: map[string][]string{"q2.md": {"gpt-4o"}},
+		},
+		"unrated and failed": {
+			filter:   Filter{Failed: true, Ratings: []Rating{RatingNone}},
+			expected: map[string][]string{"q2.md": {"o1", "sonnet"}},
+		},
+		"time window and ratings": {
+			filter:   Filter{Ratings: []Rating{RatingGood, RatingBad}, Since: day.Add(time.Hour), Until: day.Add(4 * time.Hour)},
+			expected: map[string][]string{"q1.md": {"o1"}},
+		},
+		"since and model": {
+			filter:   Filter{Models: []string{"o1", "sonnet"}, Since: day.Add(3 * time.Hour)},
+			expected: map[string][]string{"q1.md": {"sonnet"}, "q2.md": {"o1"}},
+		},
+		"no match": {
+			filter:   Filter{Ratings: []Rating{RatingGood}, Models: []string{"o1"}},
+			expected: map[string][]string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := make(map[string][]string)
+			for _, group := range FilterGroups(groups, tc.filter) {
+				for _, resp := range group.Responses {
+					got[group.QueryID] = append(got[group.QueryID], resp.Model)
+				}
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+
+	require.Len(t, groups[0].Responses, 3, "the input groups are not modified")
+}
+
+func TestParseRating(t *testing.T) {
+	tests := map[string]struct {
+		expected Rating
+		err      string
+	}{
+		"good":    {expected: RatingGood},
+		"bad":     {expected: RatingBad},
+		"unrated": {expected: RatingNone},
+		"great":   {err: `unknown rating "great": expected good, bad or unrated`},
+	}
+
+	for input, tc := range tests {
+		t.Run(input, func(t *testing.T) {
+			rating, err := ParseRating(input)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rating)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package view
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.octolab.org/toolset/tuna/internal/assistant"
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/response"
+)
+
+// ImportResult contains the result of importing external responses.
+type ImportResult struct {
+	Model    string
+	Imported []string // Paths of written response files
+}
+
+// Import copies markdown responses generated elsewhere into the plan's
+// output structure under the given model, so they can be viewed and rated.
+// Files are matched to plan queries by name: "query_001.md" and
+// "query_001_response.md" both map to query "query_001.md".
+// The model is recorded in the plan's imported models, which are viewed
+// but never executed; it must not be one of the plan models.
+func Import(planPath, dir, model string) (*ImportResult, error) {
+	if model == "" {
+		return nil, fmt.Errorf("model name is required for import")
+	}
+
+	p, err := plan.LoadFromPath(planPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := assistant.ListFiles(dir, assistant.DefaultFilter())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .txt or .md files found in %s", dir)
+	}
+
+	// Map query base names to query IDs
+	queries := make(map[string]string, len(p.Queries))
+	for _, q := range p.Queries {
+		queries[plan.ResponseBaseName(q.ID)] = q.ID
+	}
+
+	// Validate all files before writing anything
+	matched := make(map[string]string, len(files)) // filename -> query ID
+	var unknown []string
+	for _, name := range files {
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		base = strings.TrimSuffix(base, "_response")
+		queryID, ok := queries[base]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		matched[name] = queryID
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("files do not match any query in plan %s: %s",
+			p.PlanID, strings.Join(unknown, ", "))
+	}
+
+	if slices.Contains(p.Assistant.LLM.Models, model) {
+		return nil, fmt.Errorf("model %s is executed by plan %s; import under another name", model, p.PlanID)
+	}
+
+	outputDir := filepath.Dir(planPath)
+	manifest, err := exec.LoadModelManifest(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.Add(model); err != nil {
+		return nil, err
+	}
+
+	modelDir := filepath.Join(outputDir, exec.ModelHash(model))
+	if err := os.MkdirAll(modelDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	result := &ImportResult{Model: model}
+	for _, name := range files {
+		meta, content, err := response.Parse(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if meta.Model == "" {
+			meta.Model = model
+		}
+
+		formatted, err := response.Format(meta, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", name, err)
+		}
+
+		respPath := filepath.Join(modelDir, responseFileName(matched[name]))
+		if err := exec.WriteResponseFile(respPath, []byte(formatted)); err != nil {
+			return nil, err
+		}
+		result.Imported = append(result.Imported, respPath)
+	}
+
+	if err := manifest.Save(outputDir); err != nil {
+		return nil, err
+	}
+
+	// Register the model as imported, not in the plan models:
+	// exec never sends requests for it
+	if slices.Contains(p.ImportedModels, model) {
+		return result, nil
+	}
+	p.ImportedModels = append(p.ImportedModels, model)
+	if err := plan.Save(planPath, p); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package view
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/plan"
+)
+
+// importPlan creates a plan without responses and a directory of
+// external response files with the given names and contents.
+func importPlan(t *testing.T, files map[string]string) (planPath, dir string) {
+	t.Helper()
+	assistantDir := filepath.Join(t.TempDir(), "assistant")
+	outputDir := filepath.Join(assistantDir, "Output", "plan")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(assistantDir, "Input"), 0755))
+	for _, query := range []string{"q1.md", "q2.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(assistantDir, "Input", query), []byte("Question"), 0644))
+	}
+	planPath = filepath.Join(outputDir, "plan.toml")
+	require.NoError(t, plan.Save(planPath, &plan.Plan{
+		PlanID:    "plan",
+		Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o"}}},
+		Queries:   []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+	}))
+
+	dir = t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return planPath, dir
+}
+
+func TestImport(t *testing.T) {
+	planPath, dir := importPlan(t, map[string]string{
+		"q1.md":          "First answer.\n",
+		"q2_response.md": "---\nmodel: llama-3-70b\n---\n\nSecond answer.\n",
+	})
+
+	result, err := Import(planPath, dir, "external")
+	require.NoError(t, err)
+	assert.Len(t, result.Imported, 2)
+
+	p, err := plan.LoadFromPath(planPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o"}, p.Assistant.LLM.Models, "imported models are never executed")
+	assert.Equal(t, []string{"external"}, p.ImportedModels)
+	manifest, err := exec.LoadModelManifest(filepath.Dir(planPath))
+	require.NoError(t, err)
+	model, _ := manifest.Model(exec.ModelHash("external"))
+	assert.Equal(t, "external", model)
+	assert.NoFileExists(t, result.Imported[0]+".tmp", "files are written atomically")
+
+	groups, err := LoadResponses(planPath)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	contents := make(map[string]string)
+	for _, group := range groups {
+		require.Len(t, group.Responses, 2)
+		contents[group.QueryID] = group.Responses[1].Content
+	}
+	assert.Contains(t, contents["q1.md"], "First answer.")
+	assert.Contains(t, contents["q2.md"], "Second answer.")
+
+	others, err := OtherModels(planPath)
+	require.NoError(t, err)
+	assert.Empty(t, others, "imported models are not reported as removed")
+
+	// Importing again keeps the plan's model lists as they are
+	_, err = Import(planPath, dir, "external")
+	require.NoError(t, err)
+	p, err = plan.LoadFromPath(planPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o"}, p.Assistant.LLM.Models)
+	assert.Equal(t, []string{"external"}, p.ImportedModels)
+
+	_, err = Import(planPath, dir, "gpt-4o")
+	assert.EqualError(t, err, "model gpt-4o is executed by plan plan; import under another name")
+}
+
+func TestImport_UnknownQuery(t *testing.T) {
+	planPath, dir := importPlan(t, map[string]string{
+		"q1.md":    "First answer.\n",
+		"other.md": "Stray answer.\n",
+	})
+
+	_, err := Import(planPath, dir, "external")
+	assert.EqualError(t, err, "files do not match any query in plan plan: other.md")
+	assert.NoDirExists(t, filepath.Join(filepath.Dir(planPath), exec.ModelHash("external")), "nothing is written")
+
+	_, err = Import(planPath, dir, "")
+	assert.EqualError(t, err, "model name is required for import")
+}
@@ -2,13 +2,17 @@
 package view
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"go.octolab.org/toolset/tuna/internal/exec"
 	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/response"
 )
 
 // ResponseGroup represents all model responses for a single input query.
@@ -30,10 +34,17 @@ type ModelResponse struct {
 	Duration   time.Duration
 	Input      int
 	Output     int
+	Chars      int
+	Words      int
+	Cost       float64
 	ExecutedAt time.Time
+	Flagged    bool // Query was flagged by moderation and not sent
+	// Request parameters (nil for responses written before they were recorded)
+	Temperature *float64
 	// Rating metadata
 	Rating  Rating
 	RatedAt time.Time
+	Pinned  bool
 }
 
 // Rating represents the user's rating of a response.
@@ -47,6 +58,12 @@ const (
 
 // LoadResponses loads all responses for a plan from disk.
 func LoadResponses(planPath string) ([]ResponseGroup, error) {
+	return LoadResponsesFrom(planPath, nil)
+}
+
+// LoadResponsesFrom loads all responses for a plan from the given store,
+// the one exec wrote them to. A nil store reads the plan output directory.
+func LoadResponsesFrom(planPath string, store exec.ResponseStore) ([]ResponseGroup, error) {
 	p, err := plan.LoadFromPath(planPath)
 	if err != nil {
 		return nil, err
@@ -57,23 +74,31 @@ func LoadResponses(planPath string) ([]ResponseGroup, error) {
 
 	var groups []ResponseGroup
 	for _, query := range p.Queries {
+		inputFile, _ := plan.ParseQueryID(query.ID)
 		group := ResponseGroup{
 			QueryID:   query.ID,
-			InputPath: filepath.Join(assistantDir, "Input", query.ID),
+			InputPath: filepath.Join(p.InputDir(assistantDir), inputFile),
 		}
 
 		// Read input content
-		content, err := os.ReadFile(group.InputPath)
+		content, err := plan.ReadQuery(p.InputDir(assistantDir), query.ID)
 		if err != nil {
 			return nil, err
 		}
-		group.InputText = string(content)
+		group.InputText = content
 
 		// Load responses for each model
-		for _, model := range p.Assistant.LLM.Models {
+		for _, model := range p.ViewModels() {
 			hash := exec.ModelHash(model)
 			respPath := filepath.Join(outputDir, hash, responseFileName(query.ID))
 
+			// Imported responses are always files in the output directory
+			read := func() (*response.Metadata, string, error) { return ParseResponse(respPath) }
+			if store != nil && !slices.Contains(p.ImportedModels, model) {
+				respPath = store.Path(model, query.ID)
+				read = func() (*response.Metadata, string, error) { return store.Read(model, query.ID) }
+			}
+
 			resp := ModelResponse{
 				Model:     model,
 				ModelHash: hash,
@@ -82,19 +107,25 @@ func LoadResponses(planPath string) ([]ResponseGroup, error) {
 
 			// Parse response: extracts metadata from front matter,
 			// returns content without front matter for rendering
-			if meta, respContent, err := ParseResponse(respPath); err == nil {
+			if meta, respContent, err := read(); err == nil {
 				resp.Content = respContent // Already stripped of front matter
 				// Execution metadata
 				resp.Provider = meta.Provider
 				resp.Duration = meta.Duration
 				resp.Input = meta.Input
 				resp.Output = meta.Output
+				resp.Chars = meta.Chars
+				resp.Words = meta.Words
+				resp.Cost = meta.Cost
+				resp.Temperature = meta.Temperature
 				resp.ExecutedAt = meta.ExecutedAt
+				resp.Flagged = meta.Moderation == response.ModerationFlagged
 				// Rating metadata
 				if meta.Rating != "" {
 					resp.Rating = Rating(meta.Rating)
 				}
 				resp.RatedAt = meta.RatedAt
+				resp.Pinned = meta.Pinned
 			}
 
 			group.Responses = append(group.Responses, resp)
@@ -106,9 +137,123 @@ func LoadResponses(planPath string) ([]ResponseGroup, error) {
 	return groups, nil
 }
 
+// OtherModels returns the models that have responses in the plan output
+// directory but are no longer in the plan, e.g. models removed from
+// plan.toml by hand. Their directories are decoded with the model
+// manifest written by exec; directories missing from it are returned by
+// name.
+func OtherModels(planPath string) ([]string, error) {
+	p, err := plan.LoadFromPath(planPath)
+	if err != nil {
+		return nil, err
+	}
+
+	outputDir := filepath.Dir(planPath)
+	manifest, err := exec.LoadModelManifest(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan output: %w", err)
+	}
+
+	var others []string
+	for _, entry := range entries {
+		hash := entry.Name()
+		if !entry.IsDir() || slices.ContainsFunc(p.ViewModels(), func(model string) bool {
+			return exec.ModelHash(model) == hash
+		}) {
+			continue
+		}
+		if responses, _ := filepath.Glob(filepath.Join(outputDir, hash, "*_response.md")); len(responses) == 0 {
+			continue
+		}
+		model, ok := manifest.Model(hash)
+		if !ok {
+			model = hash
+		}
+		others = append(others, model)
+	}
+	return others, nil
+}
+
+// Label returns the column label of the response. With withTemperature,
+// the sampling temperature is appended, e.g. "gpt-4o @ T=0.2", so that
+// columns of a temperature sweep can be told apart.
+func (r ModelResponse) Label(withTemperature bool) string {
+	if !withTemperature || r.Temperature == nil {
+		return r.Model
+	}
+	return fmt.Sprintf("%s @ T=%g", r.Model, *r.Temperature)
+}
+
+// Failed reports whether the response is missing, empty,
+// or was not generated because moderation flagged the query.
+func (r ModelResponse) Failed() bool {
+	return r.Flagged || strings.TrimSpace(r.Content) == ""
+}
+
 // responseFileName converts a query ID to a response filename.
 // e.g., "query_001.md" -> "query_001_response.md"
 func responseFileName(queryID string) string {
-	base := strings.TrimSuffix(queryID, filepath.Ext(queryID))
-	return base + "_response.md"
+	return plan.ResponseBaseName(queryID) + "_response.md"
+}
+
+// ReadResponse returns the content of a single response of a plan,
+// without front matter. Query and model must be part of the plan.
+func ReadResponse(planPath, queryID, model string) (string, error) {
+	p, err := plan.LoadFromPath(planPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.ContainsFunc(p.Queries, func(q plan.Query) bool { return q.ID == queryID }) {
+		return "", fmt.Errorf("query %s is not part of plan %s", queryID, p.PlanID)
+	}
+	if !slices.Contains(p.ViewModels(), model) {
+		return "", fmt.Errorf("model %s is not part of plan %s", model, p.PlanID)
+	}
+
+	respPath := filepath.Join(filepath.Dir(planPath), exec.ModelHash(model), responseFileName(queryID))
+	_, content, err := ParseResponse(respPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no response to query %s from model %s", queryID, model)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	return content, nil
+}
+
+// InvalidResponse describes a response file whose front matter cannot be parsed.
+type InvalidResponse struct {
+	FilePath string
+	Err      error
+}
+
+// FindInvalidResponses strictly parses every existing response file of a plan
+// and returns those with malformed front matter.
+func FindInvalidResponses(planPath string) ([]InvalidResponse, error) {
+	p, err := plan.LoadFromPath(planPath)
+	if err != nil {
+		return nil, err
+	}
+
+	outputDir := filepath.Dir(planPath)
+
+	var invalid []InvalidResponse
+	for _, query := range p.Queries {
+		for _, model := range p.ViewModels() {
+			respPath := filepath.Join(outputDir, exec.ModelHash(model), responseFileName(query.ID))
+			if _, err := os.Stat(respPath); err != nil {
+				continue // Missing responses are not corrupt
+			}
+			if _, _, err := response.ParseStrict(respPath); err != nil {
+				invalid = append(invalid, InvalidResponse{FilePath: respPath, Err: err})
+			}
+		}
+	}
+
+	return invalid, nil
 }
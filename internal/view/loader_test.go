@@ -0,0 +1,269 @@
+package view
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.octolab.org/toolset/tuna/internal/exec"
+	"go.octolab.org/toolset/tuna/internal/llm"
+	"go.octolab.org/toolset/tuna/internal/plan"
+	"go.octolab.org/toolset/tuna/internal/response"
+)
+
+func TestOtherModels(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "assistant", "Output", "plan")
+	planPath := filepath.Join(outputDir, "plan.toml")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(planPath, &plan.Plan{
+		PlanID:    "plan",
+		Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"kept"}}},
+	}))
+
+	writeResponse := func(dir string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(outputDir, dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, dir, "q_response.md"), []byte("answer"), 0644))
+	}
+	writeResponse(exec.ModelHash("kept"))
+	writeResponse(exec.ModelHash("removed"))
+	writeResponse("deadbeef")
+	require.NoError(t, os.MkdirAll(filepath.Join(outputDir, exec.ModelHash("empty")), 0755))
+	require.NoError(t, exec.ModelManifest{
+		exec.ModelHash("kept"):    "kept",
+		exec.ModelHash("removed"): "removed",
+		exec.ModelHash("empty"):   "empty",
+	}.Save(outputDir))
+
+	others, err := OtherModels(planPath)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"removed", "deadbeef"}, others)
+}
+
+func TestReadResponse(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "assistant", "Output", "plan")
+	planPath := filepath.Join(outputDir, "plan.toml")
+	require.NoError(t, os.MkdirAll(filepath.Join(outputDir, exec.ModelHash("gpt-4o")), 0755))
+	require.NoError(t, plan.Save(planPath, &plan.Plan{
+		PlanID:    "plan",
+		Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}}},
+		Queries:   []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+	}))
+	respPath := filepath.Join(outputDir, exec.ModelHash("gpt-4o"), "q1_response.md")
+	require.NoError(t, os.WriteFile(respPath, []byte("---\nmodel: gpt-4o\n---\n\nThe answer.\n"), 0644))
+
+	content, err := ReadResponse(planPath, "q1.md", "gpt-4o")
+	require.NoError(t, err)
+	assert.Equal(t, "The answer.\n", content)
+
+	_, err = ReadResponse(planPath, "q2.md", "gpt-4o")
+	assert.EqualError(t, err, "no response to query q2.md from model gpt-4o")
+	_, err = ReadResponse(planPath, "q1.md", "o1")
+	assert.EqualError(t, err, "no response to query q1.md from model o1")
+	_, err = ReadResponse(planPath, "q3.md", "gpt-4o")
+	assert.EqualError(t, err, "query q3.md is not part of plan plan")
+	_, err = ReadResponse(planPath, "q1.md", "claude")
+	assert.EqualError(t, err, "model claude is not part of plan plan")
+}
+
+func TestFindInvalidResponses(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "assistant", "Output", "plan")
+	planPath := filepath.Join(outputDir, "plan.toml")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(planPath, &plan.Plan{
+		PlanID:    "plan",
+		Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}}},
+		Queries:   []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+	}))
+
+	files := map[string]string{
+		"gpt-4o/q1_response.md": "---\nmodel: gpt-4o\n---\n\nFine.\n",
+		"gpt-4o/q2_response.md": "---\nmodel: [gpt-4o\n---\n\nCorrupt.\n",
+		"o1/q1_response.md":     "No front matter at all.\n",
+		// o1/q2 is missing, which is not corruption
+	}
+	for name, content := range files {
+		model, file := filepath.Split(name)
+		dir := filepath.Join(outputDir, exec.ModelHash(filepath.Clean(model)))
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+
+	invalid, err := FindInvalidResponses(planPath)
+	require.NoError(t, err)
+	require.Len(t, invalid, 1)
+	assert.Equal(t, filepath.Join(outputDir, exec.ModelHash("gpt-4o"), "q2_response.md"), invalid[0].FilePath)
+	assert.ErrorContains(t, invalid[0].Err, "invalid front matter")
+}
+
+func TestLoadResponses_OnlyFailed(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "assistant")
+	outputDir := filepath.Join(dir, "Output", "plan")
+	planPath := filepath.Join(outputDir, "plan.toml")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Input"), 0755))
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(planPath, &plan.Plan{
+		PlanID:    "plan",
+		Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}}},
+		Queries:   []plan.Query{{ID: "ok.md"}, {ID: "mixed.md"}, {ID: "bad.md"}},
+	}))
+	for _, query := range []string{"ok.md", "mixed.md", "bad.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Input", query), []byte("Question"), 0644))
+	}
+
+	responses := map[string]string{
+		"gpt-4o/ok":    "---\nmodel: gpt-4o\n---\n\nAnswer\n",
+		"o1/ok":        "---\nmodel: o1\n---\n\nAnswer\n",
+		"gpt-4o/mixed": "---\nmodel: gpt-4o\n---\n\nAnswer\n",
+		"o1/mixed":     "---\nmodel: o1\n---\n\n  \n", // Empty
+		"gpt-4o/bad":   "---\nmodel: gpt-4o\nmoderation: flagged\n---\n\n",
+		// o1/bad is missing
+	}
+	for name, content := range responses {
+		model, query := filepath.Split(name)
+		modelDir := filepath.Join(outputDir, exec.ModelHash(filepath.Clean(model)))
+		require.NoError(t, os.MkdirAll(modelDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(modelDir, query+"_response.md"), []byte(content), 0644))
+	}
+
+	groups, err := LoadResponses(planPath)
+	require.NoError(t, err)
+	failed := FilterGroups(groups, Filter{Failed: true})
+
+	got := make(map[string][]string)
+	for _, group := range failed {
+		for _, resp := range group.Responses {
+			got[group.QueryID] = append(got[group.QueryID], resp.Model)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"mixed.md": {"o1"},
+		"bad.md":   {"gpt-4o", "o1"},
+	}, got, "only missing, empty and flagged responses are shown")
+	assert.True(t, failed[1].Responses[0].Flagged)
+}
+
+// memoryStore keeps responses in memory, formatted like response files.
+type memoryStore struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func (s *memoryStore) Path(model, queryID string) string {
+	return "mem://" + model + "/" + queryID
+}
+
+func (s *memoryStore) Write(model, queryID, content string, opts exec.WriteOptions) (string, error) {
+	data, err := response.Format(&response.Metadata{
+		Model:  opts.Model,
+		Input:  opts.InputTokens,
+		Output: opts.OutputTokens,
+
+		ExecutedAt: response.Now(opts.Precision),
+	}, content)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files == nil {
+		s.files = make(map[string]string)
+	}
+	path := s.Path(model, queryID)
+	s.files[path] = data
+	return path, nil
+}
+
+func (s *memoryStore) Read(model, queryID string) (*response.Metadata, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[s.Path(model, queryID)]
+	if !ok {
+		return nil, "", os.ErrNotExist
+	}
+	return response.ParseContent(data)
+}
+
+// echoClient answers every request with the query it was sent.
+type echoClient struct{}
+
+func (echoClient) Chat(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{Content: "Echo: " + req.UserMessage, Model: req.Model, PromptTokens: 4, OutputTokens: 2}, nil
+}
+
+func TestLoadResponsesFrom_Store(t *testing.T) {
+	assistantDir := filepath.Join(t.TempDir(), "assistant")
+	outputDir := filepath.Join(assistantDir, "Output", "plan")
+	planPath := filepath.Join(outputDir, "plan.toml")
+	require.NoError(t, os.MkdirAll(filepath.Join(assistantDir, "Input"), 0755))
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	p := &plan.Plan{
+		PlanID:    "plan",
+		Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}, MaxTokens: 10}},
+		Queries:   []plan.Query{{ID: "q1.md"}, {ID: "q2.md"}},
+	}
+	require.NoError(t, plan.Save(planPath, p))
+	for _, query := range p.Queries {
+		require.NoError(t, os.WriteFile(filepath.Join(assistantDir, "Input", query.ID), []byte("Question "+query.ID), 0644))
+	}
+
+	store := &memoryStore{}
+	summary, err := exec.New(p, assistantDir, echoClient{}, exec.Options{Store: store}).Execute(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, summary.Errors)
+	assert.Len(t, store.files, 4)
+
+	groups, err := LoadResponsesFrom(planPath, store)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	for _, group := range groups {
+		require.Len(t, group.Responses, 2)
+		for _, resp := range group.Responses {
+			assert.Equal(t, "mem://"+resp.Model+"/"+group.QueryID, resp.FilePath)
+			assert.Equal(t, "Echo: Question "+group.QueryID, strings.TrimSpace(resp.Content))
+			assert.Equal(t, 4, resp.Input)
+			assert.Equal(t, 2, resp.Output)
+		}
+	}
+
+	// Nothing went to disk, so the default store finds no responses
+	groups, err = LoadResponses(planPath)
+	require.NoError(t, err)
+	for _, group := range groups {
+		for _, resp := range group.Responses {
+			assert.Empty(t, resp.Content)
+		}
+	}
+}
+
+func TestLoadResponses_Temperature(t *testing.T) {
+	assistantDir := filepath.Join(t.TempDir(), "assistant")
+	outputDir := filepath.Join(assistantDir, "Output", "plan")
+	planPath := filepath.Join(outputDir, "plan.toml")
+	require.NoError(t, os.MkdirAll(filepath.Join(assistantDir, "Input"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(assistantDir, "Input", "q.md"), []byte("Question"), 0644))
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, plan.Save(planPath, &plan.Plan{
+		PlanID:    "plan",
+		Assistant: plan.Assistant{LLM: plan.LLM{Models: []string{"gpt-4o", "o1"}}},
+		Queries:   []plan.Query{{ID: "q.md"}},
+	}))
+	modelDir := filepath.Join(outputDir, exec.ModelHash("gpt-4o"))
+	require.NoError(t, os.MkdirAll(modelDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(modelDir, "q_response.md"), []byte("---\nmodel: gpt-4o\ntemperature: 0.2\n---\n\nAnswer\n"), 0644))
+
+	groups, err := LoadResponses(planPath)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	labels := make([]string, 0, 2)
+	for _, resp := range groups[0].Responses {
+		labels = append(labels, resp.Label(true))
+	}
+	assert.Equal(t, []string{"gpt-4o @ T=0.2", "o1"}, labels, "responses without a recorded temperature keep the model name")
+}
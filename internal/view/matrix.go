@@ -0,0 +1,88 @@
+package view
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Cell statuses of a comparison matrix.
+const (
+	CellGood    = "good"
+	CellBad     = "bad"
+	CellUnrated = "unrated"
+	CellMissing = "missing" // No response, an empty one, or a flagged query
+)
+
+// Matrix is a models × queries grid of response statuses.
+type Matrix struct {
+	Models []string    // Column headers, in order of first appearance
+	Rows   []MatrixRow // One per query
+}
+
+// MatrixRow holds the cell statuses of a query, one per model.
+type MatrixRow struct {
+	QueryID string
+	Cells   []string
+}
+
+// BuildMatrix builds the comparison matrix of response groups.
+// Models without a response to a query are reported as missing.
+func BuildMatrix(groups []ResponseGroup) *Matrix {
+	m := &Matrix{}
+	columns := make(map[string]int)
+	for _, group := range groups {
+		for _, resp := range group.Responses {
+			if _, ok := columns[resp.Model]; !ok {
+				columns[resp.Model] = len(m.Models)
+				m.Models = append(m.Models, resp.Model)
+			}
+		}
+	}
+
+	for _, group := range groups {
+		row := MatrixRow{QueryID: group.QueryID, Cells: make([]string, len(m.Models))}
+		for i := range row.Cells {
+			row.Cells[i] = CellMissing
+		}
+		for _, resp := range group.Responses {
+			row.Cells[columns[resp.Model]] = cellStatus(resp)
+		}
+		m.Rows = append(m.Rows, row)
+	}
+	return m
+}
+
+// cellStatus returns the matrix cell status of a response.
+// A rating takes precedence over the response being missing.
+func cellStatus(resp ModelResponse) string {
+	switch {
+	case resp.Rating == RatingGood:
+		return CellGood
+	case resp.Rating == RatingBad:
+		return CellBad
+	case resp.Failed():
+		return CellMissing
+	default:
+		return CellUnrated
+	}
+}
+
+// ExportMatrixCSV writes the matrix with a query_id column followed by
+// a column per model.
+func ExportMatrixCSV(w io.Writer, m *Matrix) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"query_id"}, m.Models...)); err != nil {
+		return fmt.Errorf("failed to write matrix export: %w", err)
+	}
+	for _, row := range m.Rows {
+		if err := writer.Write(append([]string{row.QueryID}, row.Cells...)); err != nil {
+			return fmt.Errorf("failed to write matrix export: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write matrix export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMatrix(t *testing.T) {
+	groups := []ResponseGroup{
+		{
+			QueryID: "q1.md",
+			Responses: []ModelResponse{
+				{Model: "gpt-4o", Content: "A", Rating: RatingGood},
+				{Model: "o1", Content: "B", Rating: RatingBad},
+			},
+		},
+		{
+			QueryID: "q2.md",
+			Responses: []ModelResponse{
+				{Model: "gpt-4o", Content: "C"},
+				{Model: "o1"},
+				{Model: "sonnet", Flagged: true},
+			},
+		},
+		{
+			QueryID: "q3.md",
+			Responses: []ModelResponse{
+				{Model: "sonnet", Rating: RatingGood}, // Rated, then emptied
+			},
+		},
+	}
+
+	m := BuildMatrix(groups)
+	assert.Equal(t, []string{"gpt-4o", "o1", "sonnet"}, m.Models, "a column per model, in order of first appearance")
+	assert.Equal(t, []MatrixRow{
+		{QueryID: "q1.md", Cells: []string{CellGood, CellBad, CellMissing}},
+		{QueryID: "q2.md", Cells: []string{CellUnrated, CellMissing, CellMissing}},
+		{QueryID: "q3.md", Cells: []string{CellMissing, CellMissing, CellGood}},
+	}, m.Rows, "a row per query")
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportMatrixCSV(&buf, m))
+	assert.Equal(t, "query_id,gpt-4o,o1,sonnet\n"+
+		"q1.md,good,bad,missing\n"+
+		"q2.md,unrated,missing,missing\n"+
+		"q3.md,missing,missing,good\n", buf.String())
+}
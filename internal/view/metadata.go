@@ -1,11 +1,13 @@
 package view
 
 import (
+	"errors"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 
+	"go.octolab.org/toolset/tuna/internal/exec"
 	"go.octolab.org/toolset/tuna/internal/response"
 )
 
@@ -15,13 +17,27 @@ var frontMatterRegex = regexp.MustCompile(`(?s)^---\n(.+?)\n---\n`)
 
 // ParseResponse splits a response file into metadata and content.
 // Content is returned without front matter for rendering.
+//
+// Exec, SaveRating and SavePinned replace response files atomically, so
+// they are never read half written. A file with malformed or unterminated front matter, e.g. one
+// edited by hand, is tolerated: metadata is left empty and the content
+// is returned as is.
 func ParseResponse(filePath string) (*response.Metadata, string, error) {
+	meta, content, err := response.ParseStrict(filePath)
+	if err == nil && !strings.HasPrefix(content, "---\n") {
+		return meta, content, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", err
+	}
+
 	return response.Parse(filePath)
 }
 
 // SaveRating updates or adds front matter with the rating.
-// Preserves execution metadata if present.
-func SaveRating(filePath string, rating Rating) error {
+// Preserves execution metadata if present. RatedAt is truncated to
+// precision (0 = response.DefaultTimestampPrecision).
+func SaveRating(filePath string, rating Rating, precision time.Duration) error {
 	meta, content, err := response.Parse(filePath)
 	if err != nil {
 		return err
@@ -33,7 +49,7 @@ func SaveRating(filePath string, rating Rating) error {
 		meta.RatedAt = time.Time{}
 	} else {
 		meta.Rating = string(rating)
-		meta.RatedAt = time.Now()
+		meta.RatedAt = response.Now(precision)
 	}
 
 	// Format with updated metadata
@@ -42,7 +58,25 @@ func SaveRating(filePath string, rating Rating) error {
 		return err
 	}
 
-	return os.WriteFile(filePath, []byte(formatted), 0644)
+	return exec.WriteResponseFile(filePath, []byte(formatted))
+}
+
+// SavePinned updates or adds front matter with the pinned flag.
+// Preserves execution and rating metadata if present.
+func SavePinned(filePath string, pinned bool) error {
+	meta, content, err := response.Parse(filePath)
+	if err != nil {
+		return err
+	}
+
+	meta.Pinned = pinned
+
+	formatted, err := response.Format(meta, content)
+	if err != nil {
+		return err
+	}
+
+	return exec.WriteResponseFile(filePath, []byte(formatted))
 }
 
 // StripFrontMatter removes front matter from content for display.
@@ -0,0 +1,138 @@
+package view
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResponse(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]struct {
+		data    string
+		model   string
+		content string
+	}{
+		"front matter": {
+			data:    "---\nmodel: gpt-4o\n---\n\nAnswer\n",
+			model:   "gpt-4o",
+			content: "Answer\n",
+		},
+		"unterminated front matter": {
+			data:    "---\nmodel: gpt-4o\n",
+			content: "---\nmodel: gpt-4o\n",
+		},
+		"malformed front matter": {
+			data:    "---\nmodel: [gpt-4o\n---\n\nAnswer\n",
+			content: "---\nmodel: [gpt-4o\n---\n\nAnswer\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".md")
+			require.NoError(t, os.WriteFile(path, []byte(test.data), 0644))
+
+			meta, content, err := ParseResponse(path)
+			require.NoError(t, err)
+			assert.Equal(t, test.model, meta.Model)
+			assert.Equal(t, test.content, content)
+		})
+	}
+
+	_, _, err := ParseResponse(filepath.Join(dir, "missing.md"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSavePinned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q1_response.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\nmodel: gpt-4o\nrating: good\n---\n\nAnswer\n"), 0644))
+
+	for _, pinned := range []bool{true, false} {
+		require.NoError(t, SavePinned(path, pinned))
+
+		meta, content, err := ParseResponse(path)
+		require.NoError(t, err)
+		assert.Equal(t, pinned, meta.Pinned)
+		assert.Equal(t, "gpt-4o", meta.Model, "execution metadata is kept")
+		assert.Equal(t, "good", meta.Rating, "the rating is kept")
+		assert.Equal(t, "Answer\n", content)
+	}
+}
+
+func TestSaveRating_History(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q1_response.md")
+	data := "---\nmodel: gpt-4o\nhistory:\n    - executed_at: 2026-01-02T03:04:05Z\n      model: gpt-4o\n      output: 7\n---\n\nAnswer\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	require.NoError(t, SaveRating(path, RatingGood, 0))
+
+	meta, content, err := ParseResponse(path)
+	require.NoError(t, err)
+	assert.Equal(t, "good", meta.Rating)
+	require.Len(t, meta.History, 1, "rating keeps the execution history")
+	assert.Equal(t, 7, meta.History[0].Output)
+	assert.Equal(t, "Answer\n", content)
+}
+
+func TestSaveRating_Precision(t *testing.T) {
+	tests := map[string]struct {
+		precision time.Duration
+		want      time.Duration
+	}{
+		"default": {want: time.Second},
+		"minute":  {precision: time.Minute, want: time.Minute},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "q1_response.md")
+			require.NoError(t, os.WriteFile(path, []byte("---\nmodel: gpt-4o\n---\n\nAnswer\n"), 0644))
+
+			before := time.Now().Truncate(tc.want)
+			require.NoError(t, SaveRating(path, RatingGood, tc.precision))
+
+			meta, _, err := ParseResponse(path)
+			require.NoError(t, err)
+			assert.True(t, meta.RatedAt.Equal(meta.RatedAt.Truncate(tc.want)), "rounded to the precision")
+			assert.False(t, meta.RatedAt.Before(before))
+		})
+	}
+}
+
+func TestSave_Atomic(t *testing.T) {
+	tests := map[string]func(path string) error{
+		"rating": func(path string) error { return SaveRating(path, RatingBad, 0) },
+		"pinned": func(path string) error { return SavePinned(path, true) },
+	}
+
+	for name, save := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "q1_response.md")
+			original := "---\nmodel: gpt-4o\n---\n\nAnswer\n"
+			require.NoError(t, os.WriteFile(path, []byte(original), 0644))
+
+			// A reader that opened the file before the save keeps seeing
+			// the whole old file, instead of a truncated or mixed one.
+			reader, err := os.Open(path)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			require.NoError(t, save(path))
+
+			old, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, original, string(old))
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Len(t, entries, 1, "no temporary file is left behind")
+			assert.Equal(t, "q1_response.md", entries[0].Name())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package view
+
+import "sort"
+
+// ModelTokens holds token totals of a single model across a plan's responses.
+type ModelTokens struct {
+	Model     string `json:"model"`
+	Responses int    `json:"responses"`
+	Input     int    `json:"input_tokens"`
+	Output    int    `json:"output_tokens"`
+}
+
+// ResponseTokens holds token usage of a single response.
+type ResponseTokens struct {
+	QueryID string `json:"query_id"`
+	Model   string `json:"model"`
+	Input   int    `json:"input_tokens"`
+	Output  int    `json:"output_tokens"`
+}
+
+// Total returns combined input and output tokens.
+func (r ResponseTokens) Total() int {
+	return r.Input + r.Output
+}
+
+// TokenReport summarizes token usage across a plan's responses.
+type TokenReport struct {
+	Models []ModelTokens    `json:"models"`
+	Total  ModelTokens      `json:"total"`
+	Top    []ResponseTokens `json:"most_expensive"`
+}
+
+// TokenUsage aggregates token metadata per model (in plan order)
+// and lists the top most expensive responses by total tokens.
+// Responses without execution metadata are skipped.
+func TokenUsage(groups []ResponseGroup, top int) *TokenReport {
+	report := &TokenReport{Total: ModelTokens{Model: "total"}}
+	index := make(map[string]int)
+
+	var all []ResponseTokens
+	for _, group := range groups {
+		for _, resp := range group.Responses {
+			if resp.Input == 0 && resp.Output == 0 {
+				continue
+			}
+
+			i, ok := index[resp.Model]
+			if !ok {
+				i = len(report.Models)
+				index[resp.Model] = i
+				report.Models = append(report.Models, ModelTokens{Model: resp.Model})
+			}
+			report.Models[i].Responses++
+			report.Models[i].Input += resp.Input
+			report.Models[i].Output += resp.Output
+
+			report.Total.Responses++
+			report.Total.Input += resp.Input
+			report.Total.Output += resp.Output
+
+			all = append(all, ResponseTokens{
+				QueryID: group.QueryID,
+				Model:   resp.Model,
+				Input:   resp.Input,
+				Output:  resp.Output,
+			})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Total() > all[j].Total() })
+	if top > 0 && len(all) > top {
+		all = all[:top]
+	}
+	report.Top = all
+
+	return report
+}
+
+// ModelRatings holds rating tallies of a single model across a plan's queries.
+type ModelRatings struct {
+	Model   string
+	Good    int
+	Bad     int
+	Unrated int
+	// Wins counts queries where this model is the only one rated good.
+	Wins int
+}
+
+// RatingsByModel tallies ratings per model, in plan order.
+func RatingsByModel(groups []ResponseGroup) []ModelRatings {
+	var tallies []ModelRatings
+	index := make(map[string]int)
+
+	for _, group := range groups {
+		var good []int
+		for _, resp := range group.Responses {
+			i, ok := index[resp.Model]
+			if !ok {
+				i = len(tallies)
+				index[resp.Model] = i
+				tallies = append(tallies, ModelRatings{Model: resp.Model})
+			}
+
+			switch resp.Rating {
+			case RatingGood:
+				tallies[i].Good++
+				good = append(good, i)
+			case RatingBad:
+				tallies[i].Bad++
+			default:
+				tallies[i].Unrated++
+			}
+		}
+		if len(good) == 1 {
+			tallies[good[0]].Wins++
+		}
+	}
+
+	return tallies
+}
@@ -0,0 +1,106 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenUsage(t *testing.T) {
+	groups := []ResponseGroup{
+		{QueryID: "q1.md", Responses: []ModelResponse{
+			{Model: "gpt-4o", Input: 100, Output: 50},
+			{Model: "o1", Input: 100, Output: 400},
+		}},
+		{QueryID: "q2.md", Responses: []ModelResponse{
+			{Model: "gpt-4o", Input: 200, Output: 20},
+			{Model: "o1"}, // Missing response, no metadata
+		}},
+		{QueryID: "q3.md", Responses: []ModelResponse{
+			{Model: "gpt-4o", Input: 10, Output: 5},
+			{Model: "o1", Input: 10, Output: 90},
+		}},
+	}
+
+	tests := map[string]struct {
+		top     int
+		wantTop []string
+	}{
+		"top two": {top: 2, wantTop: []string{"q1.md o1", "q2.md gpt-4o"}},
+		"all": {top: 0, wantTop: []string{
+			"q1.md o1", "q2.md gpt-4o", "q1.md gpt-4o", "q3.md o1", "q3.md gpt-4o",
+		}},
+		"more than responses": {top: 10, wantTop: []string{
+			"q1.md o1", "q2.md gpt-4o", "q1.md gpt-4o", "q3.md o1", "q3.md gpt-4o",
+		}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			report := TokenUsage(groups, tc.top)
+
+			assert.Equal(t, []ModelTokens{
+				{Model: "gpt-4o", Responses: 3, Input: 310, Output: 75},
+				{Model: "o1", Responses: 2, Input: 110, Output: 490},
+			}, report.Models, "per-model totals in plan order")
+			assert.Equal(t, ModelTokens{Model: "total", Responses: 5, Input: 420, Output: 565}, report.Total)
+
+			top := make([]string, len(report.Top))
+			for i, r := range report.Top {
+				top[i] = r.QueryID + " " + r.Model
+			}
+			assert.Equal(t, tc.wantTop, top)
+		})
+	}
+}
+
+func TestRatingsByModel(t *testing.T) {
+	tests := map[string]struct {
+		groups []ResponseGroup
+		want   []ModelRatings
+	}{
+		"no groups": {},
+		"single winner": {
+			groups: []ResponseGroup{
+				{QueryID: "q1.md", Responses: []ModelResponse{
+					{Model: "gpt-4o", Rating: RatingGood},
+					{Model: "o1", Rating: RatingBad},
+					{Model: "llama"},
+				}},
+				{QueryID: "q2.md", Responses: []ModelResponse{
+					{Model: "gpt-4o", Rating: RatingGood},
+					{Model: "o1", Rating: RatingGood},
+					{Model: "llama", Rating: RatingBad},
+				}},
+				{QueryID: "q3.md", Responses: []ModelResponse{
+					{Model: "gpt-4o"},
+					{Model: "o1", Rating: RatingGood},
+					{Model: "llama", Rating: RatingBad},
+				}},
+			},
+			want: []ModelRatings{
+				{Model: "gpt-4o", Good: 2, Unrated: 1, Wins: 1},
+				{Model: "o1", Good: 2, Bad: 1, Wins: 1},
+				{Model: "llama", Bad: 2, Unrated: 1},
+			},
+		},
+		"no good ratings": {
+			groups: []ResponseGroup{
+				{QueryID: "q1.md", Responses: []ModelResponse{
+					{Model: "gpt-4o", Rating: RatingBad},
+					{Model: "o1"},
+				}},
+			},
+			want: []ModelRatings{
+				{Model: "gpt-4o", Bad: 1},
+				{Model: "o1", Unrated: 1},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, RatingsByModel(tc.groups))
+		})
+	}
+}